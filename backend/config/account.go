@@ -0,0 +1,16 @@
+package config
+
+import "time"
+
+// AccountConfig holds account lifecycle settings.
+type AccountConfig struct {
+	DeletionGracePeriod time.Duration
+}
+
+// LoadAccountConfig reads ACCOUNT_DELETION_GRACE_PERIOD (a Go duration,
+// default 30 days).
+func LoadAccountConfig() *AccountConfig {
+	return &AccountConfig{
+		DeletionGracePeriod: envDuration("ACCOUNT_DELETION_GRACE_PERIOD", 30*24*time.Hour),
+	}
+}
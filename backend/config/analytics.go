@@ -0,0 +1,16 @@
+package config
+
+import "time"
+
+// AnalyticsConfig holds analytics settings.
+type AnalyticsConfig struct {
+	CacheTTL time.Duration
+}
+
+// LoadAnalyticsConfig reads ANALYTICS_CACHE_TTL (a Go duration, default 10
+// minutes). Zero disables the cache.
+func LoadAnalyticsConfig() *AnalyticsConfig {
+	return &AnalyticsConfig{
+		CacheTTL: envDuration("ANALYTICS_CACHE_TTL", 10*time.Minute),
+	}
+}
@@ -0,0 +1,66 @@
+package config
+
+import (
+	"bufio"
+	"os"
+	"strings"
+	"time"
+)
+
+// ContentFilterConfig controls how user-supplied text is screened before it
+// is stored. Actions are one of "reject", "mask", "flag" or "allow".
+type ContentFilterConfig struct {
+	Words          []string
+	WordAction     string
+	MaxLinks       int
+	MaxLinkDensity float64
+	LinkAction     string
+	RepeatWindow   time.Duration
+	MaxRepeats     int
+	RepeatAction   string
+}
+
+// LoadContentFilterConfig reads the content filter settings from the
+// environment, falling back to conservative defaults.
+//
+//	CONTENT_FILTER_WORDS             comma separated blocked words
+//	CONTENT_FILTER_WORDS_FILE        file with one blocked word per line
+//	CONTENT_FILTER_WORD_ACTION       default "mask"
+//	CONTENT_FILTER_MAX_LINKS         default 2
+//	CONTENT_FILTER_MAX_LINK_DENSITY  links per word, default 0.3
+//	CONTENT_FILTER_LINK_ACTION       default "flag"
+//	CONTENT_FILTER_REPEAT_WINDOW     default "1h"
+//	CONTENT_FILTER_MAX_REPEATS       identical posts allowed in the window, default 3
+//	CONTENT_FILTER_REPEAT_ACTION     default "reject"
+func LoadContentFilterConfig() *ContentFilterConfig {
+	cfg := &ContentFilterConfig{
+		WordAction:     envOr("CONTENT_FILTER_WORD_ACTION", "mask"),
+		MaxLinks:       envInt("CONTENT_FILTER_MAX_LINKS", 2),
+		MaxLinkDensity: envFloat("CONTENT_FILTER_MAX_LINK_DENSITY", 0.3),
+		LinkAction:     envOr("CONTENT_FILTER_LINK_ACTION", "flag"),
+		RepeatWindow:   envDuration("CONTENT_FILTER_REPEAT_WINDOW", time.Hour),
+		MaxRepeats:     envInt("CONTENT_FILTER_MAX_REPEATS", 3),
+		RepeatAction:   envOr("CONTENT_FILTER_REPEAT_ACTION", "reject"),
+	}
+
+	for _, word := range strings.Split(os.Getenv("CONTENT_FILTER_WORDS"), ",") {
+		if word = strings.TrimSpace(word); word != "" {
+			cfg.Words = append(cfg.Words, word)
+		}
+	}
+
+	if path := os.Getenv("CONTENT_FILTER_WORDS_FILE"); path != "" {
+		if f, err := os.Open(path); err == nil {
+			scanner := bufio.NewScanner(f)
+			for scanner.Scan() {
+				word := strings.TrimSpace(scanner.Text())
+				if word != "" && !strings.HasPrefix(word, "#") {
+					cfg.Words = append(cfg.Words, word)
+				}
+			}
+			f.Close()
+		}
+	}
+
+	return cfg
+}
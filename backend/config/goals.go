@@ -0,0 +1,14 @@
+package config
+
+// GoalConfig holds goal lifecycle settings.
+type GoalConfig struct {
+	AutoArchive bool
+}
+
+// LoadGoalConfig reads GOAL_AUTO_ARCHIVE, which archives goals once their
+// deadline has passed. It is off by default.
+func LoadGoalConfig() *GoalConfig {
+	return &GoalConfig{
+		AutoArchive: envBool("GOAL_AUTO_ARCHIVE", false),
+	}
+}
@@ -0,0 +1,23 @@
+package config
+
+// MailConfig holds the SMTP server used to send email. Mail is off unless
+// Host is set.
+type MailConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// LoadMailConfig reads MAIL_SMTP_HOST, MAIL_SMTP_PORT (default 587),
+// MAIL_SMTP_USERNAME, MAIL_SMTP_PASSWORD and MAIL_FROM.
+func LoadMailConfig() *MailConfig {
+	return &MailConfig{
+		Host:     envOr("MAIL_SMTP_HOST", ""),
+		Port:     envInt("MAIL_SMTP_PORT", 587),
+		Username: envOr("MAIL_SMTP_USERNAME", ""),
+		Password: envOr("MAIL_SMTP_PASSWORD", ""),
+		From:     envOr("MAIL_FROM", "DoToday <no-reply@dotoday.app>"),
+	}
+}
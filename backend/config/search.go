@@ -0,0 +1,24 @@
+package config
+
+// Search modes.
+const (
+	SearchModeAuto     = "auto"
+	SearchModeFullText = "fulltext"
+	SearchModeSimple   = "simple"
+)
+
+// SearchConfig selects the search implementation.
+type SearchConfig struct {
+	Mode string
+}
+
+// LoadSearchConfig reads SEARCH_MODE: "fulltext" uses Postgres full-text
+// search, "simple" uses case-insensitive substring matching and "auto" (the
+// default) uses full-text search unless probing for it fails. Both modes run
+// against the Postgres schema; simple mode is not a fallback for other
+// databases.
+func LoadSearchConfig() *SearchConfig {
+	return &SearchConfig{
+		Mode: envOr("SEARCH_MODE", SearchModeAuto),
+	}
+}
@@ -0,0 +1,30 @@
+package config
+
+// XPConfig holds how many points each activity is worth.
+type XPConfig struct {
+	Completion      int
+	StreakMilestone map[int]int
+	TargetReached   int
+	FeedPost        int
+	Comment         int
+	Like            int
+}
+
+// LoadXPConfig reads XP weights from the environment (XP_COMPLETION,
+// XP_STREAK_7, XP_STREAK_30, XP_STREAK_100, XP_STREAK_365, XP_TARGET,
+// XP_FEED_POST, XP_COMMENT, XP_LIKE), falling back to defaults.
+func LoadXPConfig() *XPConfig {
+	return &XPConfig{
+		Completion: envInt("XP_COMPLETION", 10),
+		StreakMilestone: map[int]int{
+			7:   envInt("XP_STREAK_7", 50),
+			30:  envInt("XP_STREAK_30", 200),
+			100: envInt("XP_STREAK_100", 500),
+			365: envInt("XP_STREAK_365", 2000),
+		},
+		TargetReached: envInt("XP_TARGET", 100),
+		FeedPost:      envInt("XP_FEED_POST", 5),
+		Comment:       envInt("XP_COMMENT", 2),
+		Like:          envInt("XP_LIKE", 1),
+	}
+}
@@ -0,0 +1,442 @@
+package main
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"DoToday/openapi"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	contractUserID  = "6f1c2a9e-3d4b-4c5e-8f70-112233445566"
+	contractGoalID  = "0b7e4d2c-9a18-4f36-b5c1-665544332211"
+	contractOtherID = "9d8c7b6a-5f4e-4d3c-a2b1-0a1b2c3d4e5f"
+)
+
+// specDoc is the part of the OpenAPI document the contract tests read.
+type specDoc struct {
+	Paths      map[string]map[string]*specOperation `json:"paths"`
+	Components struct {
+		Schemas map[string]*schema `json:"schemas"`
+	} `json:"components"`
+}
+
+type specOperation struct {
+	OperationID string                   `json:"operationId"`
+	Security    []map[string][]string    `json:"security"`
+	Parameters  []specParameter          `json:"parameters"`
+	RequestBody *specBody                `json:"requestBody"`
+	Responses   map[string]*specResponse `json:"responses"`
+}
+
+type specParameter struct {
+	Name   string  `json:"name"`
+	In     string  `json:"in"`
+	Schema *schema `json:"schema"`
+}
+
+type specBody struct {
+	Content map[string]struct {
+		Schema *schema `json:"schema"`
+	} `json:"content"`
+}
+
+type specResponse struct {
+	Content map[string]struct {
+		Schema *schema `json:"schema"`
+	} `json:"content"`
+}
+
+// schema covers the subset of OpenAPI 3.0 schemas the document uses.
+type schema struct {
+	Ref                  string             `json:"$ref"`
+	AllOf                []*schema          `json:"allOf"`
+	Type                 string             `json:"type"`
+	Format               string             `json:"format"`
+	Nullable             bool               `json:"nullable"`
+	Enum                 []interface{}      `json:"enum"`
+	Properties           map[string]*schema `json:"properties"`
+	Required             []string           `json:"required"`
+	Items                *schema            `json:"items"`
+	AdditionalProperties json.RawMessage    `json:"additionalProperties"`
+}
+
+func loadSpec(t *testing.T) *specDoc {
+	t.Helper()
+	var doc specDoc
+	if err := json.Unmarshal(openapi.Spec, &doc); err != nil {
+		t.Fatalf("openapi.json does not parse: %v", err)
+	}
+	return &doc
+}
+
+func (d *specDoc) resolve(s *schema) (*schema, error) {
+	for s.Ref != "" {
+		name := strings.TrimPrefix(s.Ref, "#/components/schemas/")
+		target, ok := d.Components.Schemas[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown schema %s", s.Ref)
+		}
+		s = target
+	}
+	return s, nil
+}
+
+// validate checks value, as decoded by encoding/json, against s.
+func (d *specDoc) validate(s *schema, value interface{}, at string) error {
+	s, err := d.resolve(s)
+	if err != nil {
+		return err
+	}
+	if value == nil {
+		if s.Nullable || (s.Type == "" && len(s.AllOf) == 0) {
+			return nil
+		}
+		return fmt.Errorf("%s: null is not allowed", at)
+	}
+	for _, sub := range s.AllOf {
+		if err := d.validate(sub, value, at); err != nil {
+			return err
+		}
+	}
+	if len(s.Enum) > 0 {
+		found := false
+		for _, v := range s.Enum {
+			found = found || v == value
+		}
+		if !found {
+			return fmt.Errorf("%s: %v is not one of %v", at, value, s.Enum)
+		}
+	}
+
+	switch s.Type {
+	case "object":
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s: expected an object, got %T", at, value)
+		}
+		for _, name := range s.Required {
+			if _, ok := obj[name]; !ok {
+				return fmt.Errorf("%s: missing required property %q", at, name)
+			}
+		}
+		for name, v := range obj {
+			prop, ok := s.Properties[name]
+			if !ok {
+				switch string(s.AdditionalProperties) {
+				case "false":
+					return fmt.Errorf("%s: unexpected property %q", at, name)
+				case "", "true":
+					continue
+				}
+				prop = &schema{}
+				if err := json.Unmarshal(s.AdditionalProperties, prop); err != nil {
+					return err
+				}
+			}
+			if err := d.validate(prop, v, at+"."+name); err != nil {
+				return err
+			}
+		}
+	case "array":
+		arr, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("%s: expected an array, got %T", at, value)
+		}
+		for i, v := range arr {
+			if err := d.validate(s.Items, v, fmt.Sprintf("%s[%d]", at, i)); err != nil {
+				return err
+			}
+		}
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("%s: expected a string, got %T", at, value)
+		}
+		if s.Format == "date-time" {
+			if _, err := time.Parse(time.RFC3339Nano, str); err != nil {
+				return fmt.Errorf("%s: %q is not a date-time", at, str)
+			}
+		}
+	case "integer":
+		n, ok := value.(float64)
+		if !ok || n != float64(int64(n)) {
+			return fmt.Errorf("%s: expected an integer, got %v", at, value)
+		}
+	case "number":
+		if _, ok := value.(float64); !ok {
+			return fmt.Errorf("%s: expected a number, got %T", at, value)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("%s: expected a boolean, got %T", at, value)
+		}
+	}
+	return nil
+}
+
+// example builds a value satisfying s from its required properties.
+func (d *specDoc) example(s *schema) interface{} {
+	s, err := d.resolve(s)
+	if err != nil {
+		return nil
+	}
+	if len(s.AllOf) > 0 {
+		return d.example(s.AllOf[0])
+	}
+	if len(s.Enum) > 0 {
+		return s.Enum[0]
+	}
+	switch s.Type {
+	case "object":
+		obj := map[string]interface{}{}
+		for _, name := range s.Required {
+			obj[name] = d.example(s.Properties[name])
+		}
+		return obj
+	case "array":
+		return []interface{}{}
+	case "integer", "number":
+		return 1
+	case "boolean":
+		return true
+	case "string":
+		switch s.Format {
+		case "date-time":
+			return time.Now().UTC().AddDate(0, 0, 7).Format(time.RFC3339)
+		case "date":
+			return time.Now().UTC().Format("2006-01-02")
+		case "uuid":
+			return contractOtherID
+		}
+		return "example"
+	}
+	return nil
+}
+
+// requestBodies override the generated example where the binding rules ask
+// for more than the schema says.
+var requestBodies = map[string]interface{}{
+	"createGoal":        map[string]interface{}{"title": "Read", "category": "Learning", "frequency": "daily"},
+	"recordSlip":        map[string]interface{}{},
+	"reorderChecklist":  map[string]interface{}{"item_ids": []string{contractOtherID}},
+	"createReport":      map[string]interface{}{"target_type": "feed", "target_id": contractOtherID, "reason": "spam"},
+	"shareGoalWithTeam": map[string]interface{}{"goal_id": contractGoalID},
+	"createFeed":        map[string]interface{}{"goal_id": contractGoalID, "user_id": contractUserID, "content": "Done"},
+	"createComment":     map[string]interface{}{"feed_id": contractOtherID, "user_id": contractUserID, "content": "Nice"},
+	"createLike":        map[string]interface{}{"feed_id": contractOtherID, "user_id": contractUserID},
+}
+
+var pathParam = regexp.MustCompile(`\{(\w+)\}`)
+
+// contractRequest builds a request to the operation with example
+// parameters and body.
+func contractRequest(doc *specDoc, method, path string, op *specOperation, token string) (*http.Request, error) {
+	url := pathParam.ReplaceAllStringFunc(path, func(m string) string {
+		switch m {
+		case "{id}", "{goal_id}":
+			return contractGoalID
+		case "{date}":
+			return time.Now().UTC().Format("2006-01-02")
+		case "{file}":
+			return "token.ics"
+		}
+		return contractOtherID
+	})
+
+	var body bytes.Buffer
+	contentType := ""
+	if op.RequestBody != nil {
+		if _, ok := op.RequestBody.Content["multipart/form-data"]; ok {
+			w := multipart.NewWriter(&body)
+			w.WriteField("format", "dotoday")
+			w.WriteField("dry_run", "true")
+			part, err := w.CreateFormFile("file", "goals.json")
+			if err != nil {
+				return nil, err
+			}
+			part.Write([]byte(`{}`))
+			w.Close()
+			contentType = w.FormDataContentType()
+		} else {
+			value, ok := requestBodies[op.OperationID]
+			if !ok {
+				value = doc.example(op.RequestBody.Content["application/json"].Schema)
+			}
+			if err := json.NewEncoder(&body).Encode(value); err != nil {
+				return nil, err
+			}
+			contentType = "application/json"
+		}
+	}
+
+	req := httptest.NewRequest(strings.ToUpper(method), url, &body)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return req, nil
+}
+
+// checkResponse validates a recorded response against the operation.
+func checkResponse(doc *specDoc, op *specOperation, rec *httptest.ResponseRecorder) error {
+	status := strconv.Itoa(rec.Code)
+	resp, ok := op.Responses[status]
+	if !ok {
+		return fmt.Errorf("undocumented status %s: %s", status, rec.Body.String())
+	}
+	if len(resp.Content) == 0 {
+		if rec.Body.Len() > 0 {
+			return fmt.Errorf("status %s documents no body but got %s", status, rec.Body.String())
+		}
+		return nil
+	}
+
+	mediaType, _, err := mime.ParseMediaType(rec.Header().Get("Content-Type"))
+	if err != nil {
+		return fmt.Errorf("status %s: bad content type %q", status, rec.Header().Get("Content-Type"))
+	}
+	media, ok := resp.Content[mediaType]
+	if !ok {
+		return fmt.Errorf("status %s: undocumented content type %s", status, mediaType)
+	}
+	if mediaType != "application/json" {
+		return nil
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &value); err != nil {
+		return fmt.Errorf("status %s: invalid JSON: %v", status, err)
+	}
+	if err := doc.validate(media.Schema, value, "body"); err != nil {
+		return fmt.Errorf("status %s: %v: %s", status, err, rec.Body.String())
+	}
+	return nil
+}
+
+func contractToken(t *testing.T) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": contractUserID,
+		"exp":     time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte("contract-test-secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+// TestOpenAPIRoutes checks that the document describes exactly the routes
+// the router serves.
+func TestOpenAPIRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	doc := loadSpec(t)
+	db, err := openMemStore(t.Name(), &memStore{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	router, _ := newApp(db)
+
+	served := map[string]bool{}
+	for _, route := range router.Routes() {
+		path := regexp.MustCompile(`:(\w+)`).ReplaceAllString(route.Path, "{$1}")
+		served[strings.ToLower(route.Method)+" "+path] = true
+	}
+	documented := map[string]bool{}
+	for path, ops := range doc.Paths {
+		for method := range ops {
+			documented[method+" "+path] = true
+		}
+	}
+
+	var missing, stale []string
+	for route := range served {
+		if !documented[route] {
+			missing = append(missing, route)
+		}
+	}
+	for route := range documented {
+		if !served[route] {
+			stale = append(stale, route)
+		}
+	}
+	sort.Strings(missing)
+	sort.Strings(stale)
+	for _, route := range missing {
+		t.Errorf("route %s is not documented", route)
+	}
+	for _, route := range stale {
+		t.Errorf("documented route %s is not served", route)
+	}
+}
+
+// contractStores are the states the router is exercised against: no data
+// at all, and a signed-in user who owns one goal.
+func contractStores() map[string]*memStore {
+	now := time.Now().UTC().Truncate(time.Second)
+	goal := []driver.Value{
+		contractGoalID, contractUserID, "Read", "Learning", nil, "", "daily", int64(1),
+		"habit", "", nil, "", "", nil, false, int64(0), false, now.AddDate(0, 0, -20), nil, []byte("{books}"),
+	}
+	seeded := (&memStore{}).
+		on(`SELECT id, username, email, role, share_achievements, review_emails, created_at FROM profiles WHERE id = $1`,
+			[]driver.Value{contractUserID, "reader", "reader@example.com", "admin", false, true, now}).
+		on(`FROM goals g WHERE g.id = $1`, goal).
+		on(`FROM goals g WHERE g.user_id = $1 AND g.archived = false`, goal)
+	return map[string]*memStore{"empty": {}, "seeded": seeded}
+}
+
+// TestOpenAPIContract calls every documented operation, signed in and
+// anonymously, and validates each response against the document.
+func TestOpenAPIContract(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Setenv("JWT_SECRET", "contract-test-secret")
+	t.Setenv("SUPABASE_URL", "")
+	doc := loadSpec(t)
+	token := contractToken(t)
+
+	for name, store := range contractStores() {
+		db, err := openMemStore(t.Name()+"/"+name, store)
+		if err != nil {
+			t.Fatal(err)
+		}
+		router, _ := newApp(db)
+
+		for path, ops := range doc.Paths {
+			for method, op := range ops {
+				for _, signedIn := range []bool{true, false} {
+					label := fmt.Sprintf("%s: %s %s", name, strings.ToUpper(method), path)
+					req, err := contractRequest(doc, method, path, op, map[bool]string{true: token}[signedIn])
+					if err != nil {
+						t.Fatalf("%s: %v", label, err)
+					}
+					if !signedIn {
+						label += " (anonymous)"
+					}
+					rec := httptest.NewRecorder()
+					router.ServeHTTP(rec, req)
+					if err := checkResponse(doc, op, rec); err != nil {
+						t.Errorf("%s: %v", label, err)
+					}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"net/http"
+
+	"DoToday/middleware"
+	"DoToday/models"
+	"DoToday/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AccountHandler struct {
+	accountService *services.AccountService
+}
+
+func NewAccountHandler(accountService *services.AccountService) *AccountHandler {
+	return &AccountHandler{accountService: accountService}
+}
+
+func (h *AccountHandler) RequestDeletion(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.Error(models.Unauthorized("Unauthorized"))
+		return
+	}
+
+	deletion, err := h.accountService.RequestDeletion(userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, deletion)
+}
+
+func (h *AccountHandler) GetDeletion(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.Error(models.Unauthorized("Unauthorized"))
+		return
+	}
+
+	deletion, err := h.accountService.GetPendingDeletion(userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, deletion)
+}
+
+func (h *AccountHandler) CancelDeletion(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.Error(models.Unauthorized("Unauthorized"))
+		return
+	}
+
+	if err := h.accountService.CancelDeletion(userID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Account deletion cancelled"})
+}
@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"net/http"
+
+	"DoToday/middleware"
+	"DoToday/models"
+	"DoToday/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AchievementHandler struct {
+	achievementService *services.AchievementService
+}
+
+func NewAchievementHandler(achievementService *services.AchievementService) *AchievementHandler {
+	return &AchievementHandler{achievementService: achievementService}
+}
+
+func (h *AchievementHandler) GetUserAchievements(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.Error(models.Unauthorized("Unauthorized"))
+		return
+	}
+
+	achievements, err := h.achievementService.GetUserAchievements(userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, achievements)
+}
+
+func (h *AchievementHandler) GetCatalog(c *gin.Context) {
+	c.JSON(http.StatusOK, h.achievementService.GetCatalog())
+}
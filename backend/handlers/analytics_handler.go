@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"DoToday/middleware"
+	"DoToday/models"
+	"DoToday/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AnalyticsHandler struct {
+	analyticsService *services.AnalyticsService
+}
+
+func NewAnalyticsHandler(analyticsService *services.AnalyticsService) *AnalyticsHandler {
+	return &AnalyticsHandler{analyticsService: analyticsService}
+}
+
+// GetAnalytics returns the user's analytics over the last ?days days (30 by
+// default, at most 365), bucketing completion times in the ?tz timezone.
+func (h *AnalyticsHandler) GetAnalytics(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.Error(models.Unauthorized("Unauthorized"))
+		return
+	}
+
+	days, err := strconv.Atoi(c.DefaultQuery("days", "30"))
+	if err != nil {
+		days = 30
+	}
+
+	analytics, err := h.analyticsService.GetAnalytics(userID, days, c.Query("tz"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, analytics)
+}
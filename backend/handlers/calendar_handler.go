@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"DoToday/middleware"
+	"DoToday/models"
+	"DoToday/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CalendarHandler struct {
+	calendarService *services.CalendarService
+}
+
+func NewCalendarHandler(calendarService *services.CalendarService) *CalendarHandler {
+	return &CalendarHandler{calendarService: calendarService}
+}
+
+// feedURL builds the absolute URL of the feed with the given token.
+func feedURL(c *gin.Context, token string) string {
+	scheme := "http"
+	if c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + c.Request.Host + "/api/calendar/" + token + ".ics"
+}
+
+func (h *CalendarHandler) GetFeedSettings(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.Error(models.Unauthorized("Unauthorized"))
+		return
+	}
+
+	feed, err := h.calendarService.GetFeed(userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, feed)
+}
+
+// CreateToken issues a new secret feed URL, replacing the previous one. The
+// URL is only shown in this response.
+func (h *CalendarHandler) CreateToken(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.Error(models.Unauthorized("Unauthorized"))
+		return
+	}
+
+	token, feed, err := h.calendarService.CreateToken(userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	feed.URL = feedURL(c, token)
+
+	c.JSON(http.StatusCreated, feed)
+}
+
+func (h *CalendarHandler) DeleteToken(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.Error(models.Unauthorized("Unauthorized"))
+		return
+	}
+
+	if err := h.calendarService.DeleteToken(userID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Calendar feed disabled"})
+}
+
+// GetFeed serves the iCalendar feed for the token in the path
+// (/calendar/<token>.ics). Calendar clients cannot send an Authorization
+// header, so the token is the credential. ?kind=event gives all-day events
+// instead of to-dos and ?reminder=HH:MM (default 09:00) or "none" sets
+// reminders.
+func (h *CalendarHandler) GetFeed(c *gin.Context) {
+	token := strings.TrimSuffix(c.Param("file"), ".ics")
+
+	opts := services.CalendarOptions{Kind: c.DefaultQuery("kind", services.CalendarKindTodo)}
+	if opts.Kind != services.CalendarKindTodo && opts.Kind != services.CalendarKindEvent {
+		c.Error(models.Invalid("kind must be todo or event"))
+		return
+	}
+	switch reminder := c.DefaultQuery("reminder", "09:00"); reminder {
+	case "none":
+		opts.Reminder = -1
+	default:
+		at, err := time.Parse("15:04", reminder)
+		if err != nil {
+			c.Error(models.Invalid("reminder must be HH:MM or none"))
+			return
+		}
+		opts.Reminder = at.Hour()*60 + at.Minute()
+	}
+
+	feed, err := h.calendarService.RenderFeed(token, opts)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Header("Cache-Control", "private, max-age=900")
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", []byte(feed))
+}
@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"net/http"
+
+	"DoToday/middleware"
+	"DoToday/models"
+	"DoToday/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type CategoryHandler struct {
+	categoryService *services.CategoryService
+}
+
+func NewCategoryHandler(categoryService *services.CategoryService) *CategoryHandler {
+	return &CategoryHandler{categoryService: categoryService}
+}
+
+func (h *CategoryHandler) GetCategories(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.Error(models.Unauthorized("Unauthorized"))
+		return
+	}
+
+	categories, err := h.categoryService.GetCategories(userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, categories)
+}
+
+func (h *CategoryHandler) CreateCategory(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.Error(models.Unauthorized("Unauthorized"))
+		return
+	}
+
+	var req models.CreateCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	category, err := h.categoryService.CreateCategory(userID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, category)
+}
+
+func (h *CategoryHandler) UpdateCategory(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.Error(models.Unauthorized("Unauthorized"))
+		return
+	}
+
+	categoryID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(models.Invalid("Invalid category ID"))
+		return
+	}
+
+	var req models.UpdateCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	category, err := h.categoryService.UpdateCategory(categoryID.String(), userID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, category)
+}
+
+func (h *CategoryHandler) DeleteCategory(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.Error(models.Unauthorized("Unauthorized"))
+		return
+	}
+
+	categoryID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(models.Invalid("Invalid category ID"))
+		return
+	}
+
+	if err := h.categoryService.DeleteCategory(categoryID.String(), userID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Category deleted successfully"})
+}
+
+func (h *CategoryHandler) GetStats(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.Error(models.Unauthorized("Unauthorized"))
+		return
+	}
+
+	stats, err := h.categoryService.GetStats(userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+func (h *CategoryHandler) GetCategoryStats(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.Error(models.Unauthorized("Unauthorized"))
+		return
+	}
+
+	categoryID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(models.Invalid("Invalid category ID"))
+		return
+	}
+
+	stats, err := h.categoryService.GetCategoryStats(categoryID.String(), userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
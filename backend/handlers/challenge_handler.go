@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"net/http"
+
+	"DoToday/middleware"
+	"DoToday/models"
+	"DoToday/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ChallengeHandler struct {
+	challengeService *services.ChallengeService
+}
+
+func NewChallengeHandler(challengeService *services.ChallengeService) *ChallengeHandler {
+	return &ChallengeHandler{challengeService: challengeService}
+}
+
+func (h *ChallengeHandler) CreateChallenge(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.Error(models.Unauthorized("Unauthorized"))
+		return
+	}
+
+	var req models.CreateChallengeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	challenge, err := h.challengeService.CreateChallenge(userID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, challenge)
+}
+
+func (h *ChallengeHandler) GetUserChallenges(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.Error(models.Unauthorized("Unauthorized"))
+		return
+	}
+
+	challenges, err := h.challengeService.GetUserChallenges(userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, challenges)
+}
+
+func (h *ChallengeHandler) GetChallenge(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.Error(models.Unauthorized("Unauthorized"))
+		return
+	}
+
+	challengeID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(models.Invalid("Invalid challenge ID"))
+		return
+	}
+
+	challenge, err := h.challengeService.GetChallenge(challengeID.String(), userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, challenge)
+}
+
+func (h *ChallengeHandler) JoinByInviteCode(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.Error(models.Unauthorized("Unauthorized"))
+		return
+	}
+
+	var req models.JoinChallengeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	participant, err := h.challengeService.JoinByInviteCode(req.InviteCode, userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, participant)
+}
+
+func (h *ChallengeHandler) JoinChallenge(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.Error(models.Unauthorized("Unauthorized"))
+		return
+	}
+
+	challengeID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(models.Invalid("Invalid challenge ID"))
+		return
+	}
+
+	participant, err := h.challengeService.JoinByID(challengeID.String(), userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, participant)
+}
+
+func (h *ChallengeHandler) LeaveChallenge(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.Error(models.Unauthorized("Unauthorized"))
+		return
+	}
+
+	challengeID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(models.Invalid("Invalid challenge ID"))
+		return
+	}
+
+	if err := h.challengeService.LeaveChallenge(challengeID.String(), userID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Left challenge successfully"})
+}
+
+func (h *ChallengeHandler) GetLeaderboard(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.Error(models.Unauthorized("Unauthorized"))
+		return
+	}
+
+	challengeID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(models.Invalid("Invalid challenge ID"))
+		return
+	}
+
+	leaderboard, err := h.challengeService.GetLeaderboard(challengeID.String(), userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, leaderboard)
+}
+
+func (h *ChallengeHandler) CreatePost(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.Error(models.Unauthorized("Unauthorized"))
+		return
+	}
+
+	challengeID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(models.Invalid("Invalid challenge ID"))
+		return
+	}
+
+	var req models.CreateChallengePostRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	post, err := h.challengeService.CreatePost(challengeID.String(), userID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, post)
+}
+
+func (h *ChallengeHandler) GetPosts(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.Error(models.Unauthorized("Unauthorized"))
+		return
+	}
+
+	challengeID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(models.Invalid("Invalid challenge ID"))
+		return
+	}
+
+	posts, err := h.challengeService.GetPosts(challengeID.String(), userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, posts)
+}
@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"DoToday/middleware"
+	"DoToday/models"
+	"DoToday/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ChecklistHandler struct {
+	checklistService *services.ChecklistService
+}
+
+func NewChecklistHandler(checklistService *services.ChecklistService) *ChecklistHandler {
+	return &ChecklistHandler{checklistService: checklistService}
+}
+
+// parseIDs reads the :id goal parameter and, when withItem is set, the
+// :item_id parameter.
+func (h *ChecklistHandler) parseIDs(c *gin.Context, withItem bool) (string, string, bool) {
+	goalID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(models.Invalid("Invalid goal ID"))
+		return "", "", false
+	}
+	if !withItem {
+		return goalID.String(), "", true
+	}
+	itemID, err := uuid.Parse(c.Param("item_id"))
+	if err != nil {
+		c.Error(models.Invalid("Invalid checklist item ID"))
+		return "", "", false
+	}
+	return goalID.String(), itemID.String(), true
+}
+
+// GetChecklist returns the checklist with its state on ?date (default today).
+func (h *ChecklistHandler) GetChecklist(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.Error(models.Unauthorized("Unauthorized"))
+		return
+	}
+
+	goalID, _, ok := h.parseIDs(c, false)
+	if !ok {
+		return
+	}
+
+	date := time.Now().UTC().Truncate(24 * time.Hour)
+	if v := c.Query("date"); v != "" {
+		var err error
+		if date, err = time.Parse("2006-01-02", v); err != nil {
+			c.Error(models.Invalid("Invalid date, expected YYYY-MM-DD"))
+			return
+		}
+	}
+
+	day, err := h.checklistService.GetDay(goalID, userID, date)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, day)
+}
+
+func (h *ChecklistHandler) AddItem(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.Error(models.Unauthorized("Unauthorized"))
+		return
+	}
+
+	goalID, _, ok := h.parseIDs(c, false)
+	if !ok {
+		return
+	}
+
+	var req models.CreateChecklistItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	item, err := h.checklistService.AddItem(goalID, userID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, item)
+}
+
+func (h *ChecklistHandler) UpdateItem(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.Error(models.Unauthorized("Unauthorized"))
+		return
+	}
+
+	goalID, itemID, ok := h.parseIDs(c, true)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateChecklistItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	item, err := h.checklistService.UpdateItem(goalID, itemID, userID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, item)
+}
+
+func (h *ChecklistHandler) DeleteItem(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.Error(models.Unauthorized("Unauthorized"))
+		return
+	}
+
+	goalID, itemID, ok := h.parseIDs(c, true)
+	if !ok {
+		return
+	}
+
+	if err := h.checklistService.DeleteItem(goalID, itemID, userID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Checklist item deleted successfully"})
+}
+
+func (h *ChecklistHandler) Reorder(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.Error(models.Unauthorized("Unauthorized"))
+		return
+	}
+
+	goalID, _, ok := h.parseIDs(c, false)
+	if !ok {
+		return
+	}
+
+	var req models.ReorderChecklistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	items, err := h.checklistService.Reorder(goalID, userID, req.ItemIDs)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, items)
+}
+
+func (h *ChecklistHandler) CheckItem(c *gin.Context) {
+	h.setChecked(c, true)
+}
+
+func (h *ChecklistHandler) UncheckItem(c *gin.Context) {
+	h.setChecked(c, false)
+}
+
+func (h *ChecklistHandler) setChecked(c *gin.Context, checked bool) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.Error(models.Unauthorized("Unauthorized"))
+		return
+	}
+
+	goalID, itemID, ok := h.parseIDs(c, true)
+	if !ok {
+		return
+	}
+
+	day, err := h.checklistService.SetChecked(goalID, itemID, userID, checked)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, day)
+}
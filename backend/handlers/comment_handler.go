@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"DoToday/middleware"
 	"DoToday/models"
 	"DoToday/services"
 	"net/http"
@@ -19,11 +20,14 @@ func NewCommentHandler(commentService *services.CommentService) *CommentHandler
 func (h *CommentHandler) CreateComment(c *gin.Context) {
 	var req models.Comment
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(err).SetType(gin.ErrorTypeBind)
 		return
 	}
+	if userID, ok := middleware.GetUserID(c); ok {
+		req.UserID = userID
+	}
 	if err := h.commentService.CreateComment(&req); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 	c.JSON(http.StatusCreated, req)
@@ -31,9 +35,10 @@ func (h *CommentHandler) CreateComment(c *gin.Context) {
 
 func (h *CommentHandler) GetCommentsByFeedID(c *gin.Context) {
 	feedID := c.Param("feed_id")
-	comments, err := h.commentService.GetCommentsByFeedID(feedID)
+	viewerID, _ := middleware.GetUserID(c)
+	comments, err := h.commentService.GetCommentsByFeedID(feedID, viewerID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 	c.JSON(http.StatusOK, comments)
@@ -41,9 +46,9 @@ func (h *CommentHandler) GetCommentsByFeedID(c *gin.Context) {
 
 func (h *CommentHandler) DeleteComment(c *gin.Context) {
 	id := c.Param("id")
-	userID := c.Query("user_id")
+	userID, _ := middleware.GetUserID(c)
 	if err := h.commentService.DeleteComment(id, userID); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "Comment deleted successfully"})
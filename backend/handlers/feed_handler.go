@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"DoToday/middleware"
 	"DoToday/models"
 	"DoToday/services"
 	"net/http"
@@ -19,11 +20,12 @@ func NewFeedHandler(feedService *services.FeedService) *FeedHandler {
 func (h *FeedHandler) CreateFeed(c *gin.Context) {
 	var req models.Feed
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(err).SetType(gin.ErrorTypeBind)
 		return
 	}
-	if err := h.feedService.CreateFeed(&req); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	userID, _ := middleware.GetUserID(c)
+	if err := h.feedService.CreateFeed(userID, &req); err != nil {
+		c.Error(err)
 		return
 	}
 	c.JSON(http.StatusCreated, req)
@@ -31,9 +33,10 @@ func (h *FeedHandler) CreateFeed(c *gin.Context) {
 
 func (h *FeedHandler) GetFeedByID(c *gin.Context) {
 	id := c.Param("id")
-	feed, err := h.feedService.GetFeedByID(id)
+	viewerID, _ := middleware.GetUserID(c)
+	feed, err := h.feedService.GetFeedByID(id, viewerID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Feed not found"})
+		c.Error(err)
 		return
 	}
 	c.JSON(http.StatusOK, feed)
@@ -41,9 +44,10 @@ func (h *FeedHandler) GetFeedByID(c *gin.Context) {
 
 func (h *FeedHandler) GetFeedsByGoalID(c *gin.Context) {
 	goalID := c.Param("goal_id")
-	feeds, err := h.feedService.GetFeedsByGoalID(goalID)
+	viewerID, _ := middleware.GetUserID(c)
+	feeds, err := h.feedService.GetFeedsByGoalID(goalID, viewerID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 	c.JSON(http.StatusOK, feeds)
@@ -1,8 +1,10 @@
 package handlers
 
 import (
+	"io"
 	"net/http"
 	"strconv"
+	"time"
 
 	"DoToday/middleware"
 	"DoToday/models"
@@ -23,19 +25,19 @@ func NewGoalHandler(goalService *services.GoalService) *GoalHandler {
 func (h *GoalHandler) CreateGoal(c *gin.Context) {
 	userID, ok := middleware.GetUserID(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		c.Error(models.Unauthorized("Unauthorized"))
 		return
 	}
 
 	var req models.CreateGoalRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(err).SetType(gin.ErrorTypeBind)
 		return
 	}
 
 	goal, err := h.goalService.CreateGoal(userID, &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -45,13 +47,13 @@ func (h *GoalHandler) CreateGoal(c *gin.Context) {
 func (h *GoalHandler) GetUserGoals(c *gin.Context) {
 	userID, ok := middleware.GetUserID(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		c.Error(models.Unauthorized("Unauthorized"))
 		return
 	}
 
 	goals, err := h.goalService.GetUserGoals(userID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -61,24 +63,20 @@ func (h *GoalHandler) GetUserGoals(c *gin.Context) {
 func (h *GoalHandler) GetGoalByID(c *gin.Context) {
 	userID, ok := middleware.GetUserID(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		c.Error(models.Unauthorized("Unauthorized"))
 		return
 	}
 
 	goalIDStr := c.Param("id")
 	goalID, err := uuid.Parse(goalIDStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid goal ID"})
+		c.Error(models.Invalid("Invalid goal ID"))
 		return
 	}
 
 	goal, err := h.goalService.GetGoalByID(goalID.String(), userID)
 	if err != nil {
-		if err.Error() == "unauthorized" {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Unauthorized"})
-			return
-		}
-		c.JSON(http.StatusNotFound, gin.H{"error": "Goal not found"})
+		c.Error(err)
 		return
 	}
 
@@ -88,84 +86,250 @@ func (h *GoalHandler) GetGoalByID(c *gin.Context) {
 func (h *GoalHandler) UpdateGoal(c *gin.Context) {
 	userID, ok := middleware.GetUserID(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		c.Error(models.Unauthorized("Unauthorized"))
 		return
 	}
 
 	goalIDStr := c.Param("id")
 	goalID, err := uuid.Parse(goalIDStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid goal ID"})
+		c.Error(models.Invalid("Invalid goal ID"))
 		return
 	}
 
 	var req models.UpdateGoalRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(err).SetType(gin.ErrorTypeBind)
 		return
 	}
 
 	goal, err := h.goalService.UpdateGoal(goalID.String(), userID, &req)
 	if err != nil {
-		if err.Error() == "unauthorized" {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Unauthorized"})
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, goal)
+}
+
+func (h *GoalHandler) GetRevisions(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.Error(models.Unauthorized("Unauthorized"))
+		return
+	}
+
+	goalID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(models.Invalid("Invalid goal ID"))
+		return
+	}
+
+	revisions, err := h.goalService.GetRevisions(goalID.String(), userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, revisions)
+}
+
+// GetPeriods reports each scheduled period between the from and to query
+// dates (YYYY-MM-DD), defaulting to the last 90 days.
+func (h *GoalHandler) GetPeriods(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.Error(models.Unauthorized("Unauthorized"))
+		return
+	}
+
+	goalID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(models.Invalid("Invalid goal ID"))
+		return
+	}
+
+	to := time.Now().UTC()
+	if v := c.Query("to"); v != "" {
+		if to, err = time.Parse("2006-01-02", v); err != nil {
+			c.Error(models.Invalid("Invalid to date, expected YYYY-MM-DD"))
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+	from := to.AddDate(0, 0, -89)
+	if v := c.Query("from"); v != "" {
+		if from, err = time.Parse("2006-01-02", v); err != nil {
+			c.Error(models.Invalid("Invalid from date, expected YYYY-MM-DD"))
+			return
+		}
+	}
+	if from.After(to) {
+		c.Error(models.Invalid("from must not be after to"))
 		return
 	}
 
-	c.JSON(http.StatusOK, goal)
+	periods, err := h.goalService.GetPeriods(goalID.String(), userID, from, to)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, periods)
 }
 
 func (h *GoalHandler) DeleteGoal(c *gin.Context) {
 	userID, ok := middleware.GetUserID(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		c.Error(models.Unauthorized("Unauthorized"))
 		return
 	}
 
 	goalIDStr := c.Param("id")
 	goalID, err := uuid.Parse(goalIDStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid goal ID"})
+		c.Error(models.Invalid("Invalid goal ID"))
 		return
 	}
 
 	err = h.goalService.DeleteGoal(goalID.String(), userID)
 	if err != nil {
-		if err.Error() == "unauthorized" {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Unauthorized"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Goal moved to trash"})
+}
+
+func (h *GoalHandler) GetArchivedGoals(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.Error(models.Unauthorized("Unauthorized"))
+		return
+	}
+
+	goals, err := h.goalService.GetArchivedGoals(userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, goals)
+}
+
+func (h *GoalHandler) GetTags(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.Error(models.Unauthorized("Unauthorized"))
+		return
+	}
+
+	tags, err := h.goalService.GetTags(userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tags)
+}
+
+func (h *GoalHandler) UnarchiveGoal(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.Error(models.Unauthorized("Unauthorized"))
+		return
+	}
+
+	goalID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(models.Invalid("Invalid goal ID"))
+		return
+	}
+
+	goal, err := h.goalService.UnarchiveGoal(goalID.String(), userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, goal)
+}
+
+func (h *GoalHandler) GetTrash(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.Error(models.Unauthorized("Unauthorized"))
+		return
+	}
+
+	goals, err := h.goalService.GetTrash(userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, goals)
+}
+
+func (h *GoalHandler) RestoreGoal(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.Error(models.Unauthorized("Unauthorized"))
+		return
+	}
+
+	goalID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(models.Invalid("Invalid goal ID"))
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Goal deleted successfully"})
+	goal, err := h.goalService.RestoreGoal(goalID.String(), userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, goal)
+}
+
+func (h *GoalHandler) PurgeGoal(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.Error(models.Unauthorized("Unauthorized"))
+		return
+	}
+
+	goalID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(models.Invalid("Invalid goal ID"))
+		return
+	}
+
+	if err := h.goalService.PurgeGoal(goalID.String(), userID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Goal deleted permanently"})
 }
 
 func (h *GoalHandler) ArchiveGoal(c *gin.Context) {
 	userID, ok := middleware.GetUserID(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		c.Error(models.Unauthorized("Unauthorized"))
 		return
 	}
 
 	goalIDStr := c.Param("id")
 	goalID, err := uuid.Parse(goalIDStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid goal ID"})
+		c.Error(models.Invalid("Invalid goal ID"))
 		return
 	}
 
 	err = h.goalService.ArchiveGoal(goalID.String(), userID)
 	if err != nil {
-		if err.Error() == "unauthorized" {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Unauthorized"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -175,55 +339,155 @@ func (h *GoalHandler) ArchiveGoal(c *gin.Context) {
 func (h *GoalHandler) MarkComplete(c *gin.Context) {
 	userID, ok := middleware.GetUserID(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		c.Error(models.Unauthorized("Unauthorized"))
 		return
 	}
 
 	goalIDStr := c.Param("id")
 	goalID, err := uuid.Parse(goalIDStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid goal ID"})
+		c.Error(models.Invalid("Invalid goal ID"))
 		return
 	}
 
 	err = h.goalService.MarkComplete(goalID.String(), userID)
 	if err != nil {
-		if err.Error() == "unauthorized" {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Unauthorized"})
-			return
-		}
-		if err.Error() == "already completed today" {
-			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Goal marked as complete"})
 }
 
+func (h *GoalHandler) RecordMeasurement(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.Error(models.Unauthorized("Unauthorized"))
+		return
+	}
+
+	goalID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(models.Invalid("Invalid goal ID"))
+		return
+	}
+
+	var req models.RecordMeasurementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	completion, err := h.goalService.RecordMeasurement(goalID.String(), userID, *req.Value)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, completion)
+}
+
+func (h *GoalHandler) RecordSlip(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.Error(models.Unauthorized("Unauthorized"))
+		return
+	}
+
+	goalID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(models.Invalid("Invalid goal ID"))
+		return
+	}
+
+	var req models.RecordSlipRequest
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	slip, err := h.goalService.RecordSlip(goalID.String(), userID, req.Date)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, slip)
+}
+
+func (h *GoalHandler) GetGraph(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.Error(models.Unauthorized("Unauthorized"))
+		return
+	}
+
+	goalID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(models.Invalid("Invalid goal ID"))
+		return
+	}
+
+	days, err := strconv.Atoi(c.DefaultQuery("days", "30"))
+	if err != nil {
+		days = 30
+	}
+
+	graph, err := h.goalService.GetGraph(goalID.String(), userID, days)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, graph)
+}
+
+func (h *GoalHandler) RemoveCompletion(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.Error(models.Unauthorized("Unauthorized"))
+		return
+	}
+
+	goalIDStr := c.Param("id")
+	goalID, err := uuid.Parse(goalIDStr)
+	if err != nil {
+		c.Error(models.Invalid("Invalid goal ID"))
+		return
+	}
+
+	date, err := time.Parse("2006-01-02", c.Param("date"))
+	if err != nil {
+		c.Error(models.Invalid("Invalid date, expected YYYY-MM-DD"))
+		return
+	}
+
+	err = h.goalService.RemoveCompletion(goalID.String(), userID, date)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Completion removed"})
+}
+
 func (h *GoalHandler) GetCompletions(c *gin.Context) {
 	userID, ok := middleware.GetUserID(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		c.Error(models.Unauthorized("Unauthorized"))
 		return
 	}
 
 	goalIDStr := c.Param("id")
 	goalID, err := uuid.Parse(goalIDStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid goal ID"})
+		c.Error(models.Invalid("Invalid goal ID"))
 		return
 	}
 
 	completions, err := h.goalService.GetCompletions(goalID.String(), userID)
 	if err != nil {
-		if err.Error() == "unauthorized" {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Unauthorized"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -233,24 +497,20 @@ func (h *GoalHandler) GetCompletions(c *gin.Context) {
 func (h *GoalHandler) GetStreak(c *gin.Context) {
 	userID, ok := middleware.GetUserID(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		c.Error(models.Unauthorized("Unauthorized"))
 		return
 	}
 
 	goalIDStr := c.Param("id")
 	goalID, err := uuid.Parse(goalIDStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid goal ID"})
+		c.Error(models.Invalid("Invalid goal ID"))
 		return
 	}
 
 	streak, err := h.goalService.GetStreak(goalID.String(), userID)
 	if err != nil {
-		if err.Error() == "unauthorized" {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Unauthorized"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -264,9 +524,10 @@ func (h *GoalHandler) GetPublicGoals(c *gin.Context) {
 		limit = 50
 	}
 
-	goals, err := h.goalService.GetPublicGoals(limit)
+	viewerID, _ := middleware.GetUserID(c)
+	goals, err := h.goalService.GetPublicGoals(limit, viewerID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"net/http"
+
+	"DoToday/middleware"
+	"DoToday/models"
+	"DoToday/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type GoalShareHandler struct {
+	shareService *services.GoalShareService
+}
+
+func NewGoalShareHandler(shareService *services.GoalShareService) *GoalShareHandler {
+	return &GoalShareHandler{shareService: shareService}
+}
+
+func (h *GoalShareHandler) ShareGoal(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.Error(models.Unauthorized("Unauthorized"))
+		return
+	}
+
+	goalID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(models.Invalid("Invalid goal ID"))
+		return
+	}
+
+	var req models.ShareGoalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	share, err := h.shareService.ShareGoal(goalID.String(), userID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, share)
+}
+
+func (h *GoalShareHandler) GetShares(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.Error(models.Unauthorized("Unauthorized"))
+		return
+	}
+
+	goalID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(models.Invalid("Invalid goal ID"))
+		return
+	}
+
+	shares, err := h.shareService.GetShares(goalID.String(), userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, shares)
+}
+
+func (h *GoalShareHandler) RemoveShare(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.Error(models.Unauthorized("Unauthorized"))
+		return
+	}
+
+	goalID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(models.Invalid("Invalid goal ID"))
+		return
+	}
+	targetID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		c.Error(models.Invalid("Invalid user ID"))
+		return
+	}
+
+	if err := h.shareService.RemoveShare(goalID.String(), userID, targetID.String()); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Share removed successfully"})
+}
+
+func (h *GoalShareHandler) GetSharedGoals(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.Error(models.Unauthorized("Unauthorized"))
+		return
+	}
+
+	goals, err := h.shareService.GetSharedGoals(userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, goals)
+}
+
+func (h *GoalShareHandler) Encourage(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.Error(models.Unauthorized("Unauthorized"))
+		return
+	}
+
+	goalID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(models.Invalid("Invalid goal ID"))
+		return
+	}
+
+	var req models.EncouragementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	encouragement, err := h.shareService.Encourage(goalID.String(), userID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, encouragement)
+}
+
+func (h *GoalShareHandler) GetEncouragements(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.Error(models.Unauthorized("Unauthorized"))
+		return
+	}
+
+	goalID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(models.Invalid("Invalid goal ID"))
+		return
+	}
+
+	encouragements, err := h.shareService.GetEncouragements(goalID.String(), userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, encouragements)
+}
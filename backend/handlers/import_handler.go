@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+
+	"DoToday/middleware"
+	"DoToday/models"
+	"DoToday/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxImportSize caps uploaded import files.
+const maxImportSize = 10 << 20
+
+type ImportHandler struct {
+	importService *services.ImportService
+}
+
+func NewImportHandler(importService *services.ImportService) *ImportHandler {
+	return &ImportHandler{importService: importService}
+}
+
+func (h *ImportHandler) Import(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.Error(models.Unauthorized("Unauthorized"))
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+
+	var req models.ImportRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.Error(models.Invalid("Missing import file"))
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	report, err := h.importService.Import(userID, data, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	status := http.StatusCreated
+	if req.DryRun {
+		status = http.StatusOK
+	}
+	c.JSON(status, report)
+}
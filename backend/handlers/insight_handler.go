@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"DoToday/middleware"
+	"DoToday/models"
+	"DoToday/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type InsightHandler struct {
+	insightService *services.InsightService
+}
+
+func NewInsightHandler(insightService *services.InsightService) *InsightHandler {
+	return &InsightHandler{insightService: insightService}
+}
+
+// GetInsights returns the strongest relationships between the user's goals
+// over the last ?days days (90 by default), at most ?limit of them.
+func (h *InsightHandler) GetInsights(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.Error(models.Unauthorized("Unauthorized"))
+		return
+	}
+
+	days, err := strconv.Atoi(c.DefaultQuery("days", "90"))
+	if err != nil {
+		days = 90
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil {
+		limit = 10
+	}
+
+	report, err := h.insightService.GetInsights(userID, days, limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"DoToday/middleware"
 	"DoToday/models"
 	"DoToday/services"
 	"net/http"
@@ -19,11 +20,14 @@ func NewLikeHandler(likeService *services.LikeService) *LikeHandler {
 func (h *LikeHandler) CreateLike(c *gin.Context) {
 	var req models.Like
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(err).SetType(gin.ErrorTypeBind)
 		return
 	}
+	if userID, ok := middleware.GetUserID(c); ok {
+		req.UserID = userID
+	}
 	if err := h.likeService.CreateLike(&req); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 	c.JSON(http.StatusCreated, req)
@@ -33,7 +37,7 @@ func (h *LikeHandler) DeleteLike(c *gin.Context) {
 	feedID := c.Param("feed_id")
 	userID := c.Query("user_id")
 	if err := h.likeService.DeleteLike(feedID, userID); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "Like deleted successfully"})
@@ -41,9 +45,10 @@ func (h *LikeHandler) DeleteLike(c *gin.Context) {
 
 func (h *LikeHandler) CountLikes(c *gin.Context) {
 	feedID := c.Param("feed_id")
-	count, err := h.likeService.CountLikes(feedID)
+	viewerID, _ := middleware.GetUserID(c)
+	count, err := h.likeService.CountLikes(feedID, viewerID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"count": count})
@@ -54,7 +59,7 @@ func (h *LikeHandler) Exists(c *gin.Context) {
 	userID := c.Query("user_id")
 	exists, err := h.likeService.Exists(feedID, userID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"exists": exists})
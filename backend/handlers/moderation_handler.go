@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"DoToday/middleware"
+	"DoToday/models"
+	"DoToday/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ModerationHandler struct {
+	moderationService *services.ModerationService
+}
+
+func NewModerationHandler(moderationService *services.ModerationService) *ModerationHandler {
+	return &ModerationHandler{moderationService: moderationService}
+}
+
+func (h *ModerationHandler) BlockUser(c *gin.Context) {
+	h.changeRelation(c, h.moderationService.BlockUser, "User blocked successfully")
+}
+
+func (h *ModerationHandler) UnblockUser(c *gin.Context) {
+	h.changeRelation(c, h.moderationService.UnblockUser, "User unblocked successfully")
+}
+
+func (h *ModerationHandler) MuteUser(c *gin.Context) {
+	h.changeRelation(c, h.moderationService.MuteUser, "User muted successfully")
+}
+
+func (h *ModerationHandler) UnmuteUser(c *gin.Context) {
+	h.changeRelation(c, h.moderationService.UnmuteUser, "User unmuted successfully")
+}
+
+// changeRelation handles the block/mute endpoints, which all take the target
+// user from the :user_id path parameter.
+func (h *ModerationHandler) changeRelation(c *gin.Context, apply func(userID, targetID string) error, message string) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.Error(models.Unauthorized("Unauthorized"))
+		return
+	}
+
+	targetID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		c.Error(models.Invalid("Invalid user ID"))
+		return
+	}
+
+	if err := apply(userID, targetID.String()); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": message})
+}
+
+func (h *ModerationHandler) GetBlocks(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.Error(models.Unauthorized("Unauthorized"))
+		return
+	}
+
+	blocks, err := h.moderationService.GetBlocks(userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, blocks)
+}
+
+func (h *ModerationHandler) GetMutes(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.Error(models.Unauthorized("Unauthorized"))
+		return
+	}
+
+	mutes, err := h.moderationService.GetMutes(userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, mutes)
+}
+
+func (h *ModerationHandler) CreateReport(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.Error(models.Unauthorized("Unauthorized"))
+		return
+	}
+
+	var req models.CreateReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	report, err := h.moderationService.CreateReport(userID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, report)
+}
+
+func (h *ModerationHandler) GetReports(c *gin.Context) {
+	status := c.DefaultQuery("status", models.ReportStatusOpen)
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil {
+		limit = 50
+	}
+
+	reports, err := h.moderationService.GetReports(status, limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, reports)
+}
+
+func (h *ModerationHandler) GetContentFlags(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil {
+		limit = 50
+	}
+
+	flags, err := h.moderationService.GetContentFlags(limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, flags)
+}
+
+func (h *ModerationHandler) HideReported(c *gin.Context) {
+	h.resolveReport(c, h.moderationService.HideReported)
+}
+
+func (h *ModerationHandler) RestoreReported(c *gin.Context) {
+	h.resolveReport(c, h.moderationService.RestoreReported)
+}
+
+func (h *ModerationHandler) resolveReport(c *gin.Context, resolve func(reportID, adminID string) (*models.Report, error)) {
+	adminID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.Error(models.Unauthorized("Unauthorized"))
+		return
+	}
+
+	reportID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(models.Invalid("Invalid report ID"))
+		return
+	}
+
+	report, err := resolve(reportID.String(), adminID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
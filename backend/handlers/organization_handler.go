@@ -0,0 +1,247 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"DoToday/middleware"
+	"DoToday/models"
+	"DoToday/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type OrganizationHandler struct {
+	orgService *services.OrganizationService
+}
+
+func NewOrganizationHandler(orgService *services.OrganizationService) *OrganizationHandler {
+	return &OrganizationHandler{orgService: orgService}
+}
+
+// orgParams reads the caller and the :id organization parameter, writing an
+// error response and returning ok=false when either is missing or invalid.
+func (h *OrganizationHandler) orgParams(c *gin.Context) (userID, orgID string, ok bool) {
+	userID, ok = middleware.GetUserID(c)
+	if !ok {
+		c.Error(models.Unauthorized("Unauthorized"))
+		return "", "", false
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(models.Invalid("Invalid organization ID"))
+		return "", "", false
+	}
+	return userID, id.String(), true
+}
+
+func (h *OrganizationHandler) CreateOrganization(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.Error(models.Unauthorized("Unauthorized"))
+		return
+	}
+
+	var req models.CreateOrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	org, err := h.orgService.CreateOrganization(userID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, org)
+}
+
+func (h *OrganizationHandler) GetUserOrganizations(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.Error(models.Unauthorized("Unauthorized"))
+		return
+	}
+
+	orgs, err := h.orgService.GetUserOrganizations(userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, orgs)
+}
+
+func (h *OrganizationHandler) GetOrganization(c *gin.Context) {
+	userID, orgID, ok := h.orgParams(c)
+	if !ok {
+		return
+	}
+
+	org, err := h.orgService.GetOrganization(orgID, userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, org)
+}
+
+func (h *OrganizationHandler) GetMembers(c *gin.Context) {
+	userID, orgID, ok := h.orgParams(c)
+	if !ok {
+		return
+	}
+
+	members, err := h.orgService.GetMembers(orgID, userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, members)
+}
+
+func (h *OrganizationHandler) AddMember(c *gin.Context) {
+	userID, orgID, ok := h.orgParams(c)
+	if !ok {
+		return
+	}
+
+	var req models.AddMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	member, err := h.orgService.AddMember(orgID, userID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, member)
+}
+
+func (h *OrganizationHandler) UpdateMemberRole(c *gin.Context) {
+	userID, orgID, ok := h.orgParams(c)
+	if !ok {
+		return
+	}
+
+	targetID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		c.Error(models.Invalid("Invalid user ID"))
+		return
+	}
+
+	var req models.UpdateMemberRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	if err := h.orgService.UpdateMemberRole(orgID, userID, targetID.String(), &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Member role updated successfully"})
+}
+
+func (h *OrganizationHandler) RemoveMember(c *gin.Context) {
+	userID, orgID, ok := h.orgParams(c)
+	if !ok {
+		return
+	}
+
+	targetID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		c.Error(models.Invalid("Invalid user ID"))
+		return
+	}
+
+	if err := h.orgService.RemoveMember(orgID, userID, targetID.String()); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Member removed successfully"})
+}
+
+func (h *OrganizationHandler) GetGoals(c *gin.Context) {
+	userID, orgID, ok := h.orgParams(c)
+	if !ok {
+		return
+	}
+
+	goals, err := h.orgService.GetGoals(orgID, userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, goals)
+}
+
+func (h *OrganizationHandler) ShareGoal(c *gin.Context) {
+	userID, orgID, ok := h.orgParams(c)
+	if !ok {
+		return
+	}
+
+	var req models.ShareGoalWithTeamRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	if err := h.orgService.ShareGoal(orgID, userID, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Goal shared with team"})
+}
+
+func (h *OrganizationHandler) UnshareGoal(c *gin.Context) {
+	userID, orgID, ok := h.orgParams(c)
+	if !ok {
+		return
+	}
+
+	goalID, err := uuid.Parse(c.Param("goal_id"))
+	if err != nil {
+		c.Error(models.Invalid("Invalid goal ID"))
+		return
+	}
+
+	if err := h.orgService.UnshareGoal(orgID, userID, goalID.String()); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Goal removed from team"})
+}
+
+func (h *OrganizationHandler) GetDashboard(c *gin.Context) {
+	userID, orgID, ok := h.orgParams(c)
+	if !ok {
+		return
+	}
+
+	days, err := strconv.Atoi(c.DefaultQuery("days", "30"))
+	if err != nil {
+		days = 30
+	}
+
+	dashboard, err := h.orgService.GetDashboard(orgID, userID, days)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dashboard)
+}
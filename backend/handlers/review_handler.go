@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"DoToday/middleware"
+	"DoToday/models"
+	"DoToday/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ReviewHandler struct {
+	reviewService *services.ReviewService
+}
+
+func NewReviewHandler(reviewService *services.ReviewService) *ReviewHandler {
+	return &ReviewHandler{reviewService: reviewService}
+}
+
+// GetReviews lists the user's latest reviews, filtered by ?period (weekly or
+// monthly) and capped by ?limit.
+func (h *ReviewHandler) GetReviews(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.Error(models.Unauthorized("Unauthorized"))
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "12"))
+	if err != nil {
+		limit = 12
+	}
+
+	reviews, err := h.reviewService.GetReviews(userID, c.Query("period"), limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, reviews)
+}
+
+// GenerateReview returns the review of the last full week or month,
+// generating it if needed.
+func (h *ReviewHandler) GenerateReview(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.Error(models.Unauthorized("Unauthorized"))
+		return
+	}
+
+	var req models.GenerateReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	review, err := h.reviewService.Generate(userID, req.Period)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, review)
+}
+
+// GetReview returns a review as JSON, or rendered with ?format=markdown or
+// ?format=html.
+func (h *ReviewHandler) GetReview(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.Error(models.Unauthorized("Unauthorized"))
+		return
+	}
+
+	reviewID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(models.Invalid("Invalid review ID"))
+		return
+	}
+
+	review, err := h.reviewService.GetReview(reviewID.String(), userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	switch c.DefaultQuery("format", "json") {
+	case "json":
+		c.JSON(http.StatusOK, review)
+	case "markdown":
+		c.Data(http.StatusOK, "text/markdown; charset=utf-8", []byte(services.RenderReviewMarkdown(review)))
+	case "html":
+		html, err := services.RenderReviewHTML(review)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(html))
+	default:
+		c.Error(models.Invalid("format must be json, markdown or html"))
+	}
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"DoToday/middleware"
+	"DoToday/models"
+	"DoToday/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type SearchHandler struct {
+	searchService *services.SearchService
+}
+
+func NewSearchHandler(searchService *services.SearchService) *SearchHandler {
+	return &SearchHandler{searchService: searchService}
+}
+
+// Search handles GET /search?q=...&type=goal,feed,comment&tag=&category_id=&from=&to=&limit=
+func (h *SearchHandler) Search(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.Error(models.Unauthorized("Unauthorized"))
+		return
+	}
+
+	q := &models.SearchQuery{
+		Text: c.Query("q"),
+		Tag:  c.Query("tag"),
+	}
+	if v := c.Query("type"); v != "" {
+		q.Types = strings.Split(v, ",")
+	}
+	if v := c.Query("category_id"); v != "" {
+		categoryID, err := uuid.Parse(v)
+		if err != nil {
+			c.Error(models.Invalid("Invalid category ID"))
+			return
+		}
+		q.CategoryID = categoryID.String()
+	}
+	if v := c.Query("from"); v != "" {
+		from, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			c.Error(models.Invalid("Invalid from date, expected YYYY-MM-DD"))
+			return
+		}
+		q.From = &from
+	}
+	if v := c.Query("to"); v != "" {
+		to, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			c.Error(models.Invalid("Invalid to date, expected YYYY-MM-DD"))
+			return
+		}
+		q.To = &to
+	}
+	if limit, err := strconv.Atoi(c.Query("limit")); err == nil {
+		q.Limit = limit
+	}
+
+	results, err := h.searchService.Search(userID, q)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, results)
+}
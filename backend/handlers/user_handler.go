@@ -23,13 +23,13 @@ func NewUserHandler(userService *services.UserService) *UserHandler {
 func (h *UserHandler) GetProfile(c *gin.Context) {
 	userID, ok := middleware.GetUserID(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		c.Error(models.Unauthorized("Unauthorized"))
 		return
 	}
 
 	user, err := h.userService.GetProfile(userID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -39,23 +39,19 @@ func (h *UserHandler) GetProfile(c *gin.Context) {
 func (h *UserHandler) UpdateProfile(c *gin.Context) {
 	userID, ok := middleware.GetUserID(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		c.Error(models.Unauthorized("Unauthorized"))
 		return
 	}
 
 	var req models.UpdateProfileRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(err).SetType(gin.ErrorTypeBind)
 		return
 	}
 
 	user, err := h.userService.UpdateProfile(userID, &req)
 	if err != nil {
-		if err.Error() == "username already exists" {
-			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -65,20 +61,78 @@ func (h *UserHandler) UpdateProfile(c *gin.Context) {
 func (h *UserHandler) GetUserStats(c *gin.Context) {
 	userID, ok := middleware.GetUserID(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		c.Error(models.Unauthorized("Unauthorized"))
 		return
 	}
 	// Convert userID string to uuid.UUID
 	uid, err := uuid.Parse(userID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		c.Error(models.Invalid("Invalid user ID"))
 		return
 	}
 	stats, err := h.userService.GetUserStats(uid)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, stats)
 }
+
+func (h *UserHandler) Follow(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.Error(models.Unauthorized("Unauthorized"))
+		return
+	}
+
+	targetID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		c.Error(models.Invalid("Invalid user ID"))
+		return
+	}
+
+	if err := h.userService.Follow(userID, targetID.String()); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User followed successfully"})
+}
+
+func (h *UserHandler) Unfollow(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.Error(models.Unauthorized("Unauthorized"))
+		return
+	}
+
+	targetID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		c.Error(models.Invalid("Invalid user ID"))
+		return
+	}
+
+	if err := h.userService.Unfollow(userID, targetID.String()); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User unfollowed successfully"})
+}
+
+func (h *UserHandler) GetFollowing(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.Error(models.Unauthorized("Unauthorized"))
+		return
+	}
+
+	following, err := h.userService.GetFollowing(userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, following)
+}
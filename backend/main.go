@@ -1,8 +1,10 @@
 package main
 
 import (
+	"database/sql"
 	"log"
 	"os"
+	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -11,6 +13,8 @@ import (
 	"DoToday/config"
 	"DoToday/handlers"
 	"DoToday/middleware"
+	"DoToday/migrations"
+	"DoToday/openapi"
 	"DoToday/repositories"
 	"DoToday/services"
 )
@@ -27,6 +31,38 @@ func main() {
 		log.Fatal("Failed to connect to database:", err)
 	}
 
+	// Apply pending schema migrations
+	if err := migrations.Apply(db); err != nil {
+		log.Fatal("Failed to apply migrations:", err)
+	}
+
+	router, jobs := newApp(db)
+	for _, job := range jobs {
+		runPeriodically(job.name, job.interval, job.run)
+	}
+
+	// Get port from environment or default to 8080
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "8080"
+	}
+
+	log.Printf("Server starting on port %s", port)
+	if err := router.Run(":" + port); err != nil {
+		log.Fatal("Failed to start server:", err)
+	}
+}
+
+// backgroundJob is work main repeats every interval while the server runs.
+type backgroundJob struct {
+	name     string
+	interval time.Duration
+	run      func() error
+}
+
+// newApp wires the repositories, services and handlers over db and returns
+// the router along with the background jobs to run.
+func newApp(db *sql.DB) (*gin.Engine, []backgroundJob) {
 	// Initialize repositories
 	userRepo := repositories.NewUserRepository(db)
 	goalRepo := repositories.NewGoalRepository(db)
@@ -34,14 +70,58 @@ func main() {
 	feedRepo := repositories.NewFeedRepository(db)
 	commentRepo := repositories.NewCommentRepository(db)
 	likeRepo := repositories.NewLikeRepository(db)
+	moderationRepo := repositories.NewModerationRepository(db)
+	contentFilterRepo := repositories.NewContentFilterRepository(db)
+	challengeRepo := repositories.NewChallengeRepository(db)
+	goalShareRepo := repositories.NewGoalShareRepository(db)
+	notificationRepo := repositories.NewNotificationRepository(db)
+	orgRepo := repositories.NewOrganizationRepository(db)
+	achievementRepo := repositories.NewAchievementRepository(db)
+	xpRepo := repositories.NewXPRepository(db)
+	followRepo := repositories.NewFollowRepository(db)
+	accountRepo := repositories.NewAccountRepository(db)
+	categoryRepo := repositories.NewCategoryRepository(db)
+	searchRepo := repositories.NewSearchRepository(db)
+	checklistRepo := repositories.NewChecklistRepository(db)
+	analyticsRepo := repositories.NewAnalyticsRepository(db)
+	reviewRepo := repositories.NewReviewRepository(db)
+	calendarRepo := repositories.NewCalendarRepository(db)
+
+	// Initialize content filter
+	contentFilterConfig := config.LoadContentFilterConfig()
+	contentFilter := services.NewContentFilter(
+		contentFilterRepo,
+		services.DefaultContentRules(contentFilterConfig, contentFilterRepo)...,
+	)
 
 	// Initialize services
-	authService := services.NewAuthService(userRepo)
-	goalService := services.NewGoalService(goalRepo, completionRepo)
-	userService := services.NewUserService(userRepo)
-	feedService := services.NewFeedService(feedRepo)
-	commentService := services.NewCommentService(commentRepo)
-	likeService := services.NewLikeService(likeRepo)
+	authService := services.NewAuthService(userRepo, contentFilter)
+	accountService := services.NewAccountService(accountRepo, authService, config.LoadAccountConfig())
+	exportService := services.NewExportService(userRepo, goalRepo, completionRepo, feedRepo, commentRepo, likeRepo)
+	xpService := services.NewXPService(xpRepo, completionRepo, config.LoadXPConfig())
+	achievementService := services.NewAchievementService(achievementRepo, userRepo, feedRepo, services.DefaultAchievementRules)
+	categoryService := services.NewCategoryService(categoryRepo)
+	searchService := services.NewSearchService(searchRepo, config.LoadSearchConfig())
+	goalService := services.NewGoalService(goalRepo, completionRepo, goalShareRepo, orgRepo, categoryService, contentFilter)
+	goalService.AddListener(achievementService)
+	goalService.AddListener(xpService)
+	analyticsService := services.NewAnalyticsService(analyticsRepo, goalRepo, completionRepo, goalService, config.LoadAnalyticsConfig())
+	goalService.AddListener(analyticsService)
+	importService := services.NewImportService(goalRepo, completionRepo, goalService, categoryService)
+	checklistService := services.NewChecklistService(checklistRepo, goalRepo, completionRepo, goalService)
+	userService := services.NewUserService(userRepo, followRepo, achievementService, contentFilter)
+	feedService := services.NewFeedService(feedRepo, goalRepo, xpService, contentFilter)
+	commentService := services.NewCommentService(commentRepo, feedRepo, xpService, contentFilter)
+	likeService := services.NewLikeService(likeRepo, feedRepo, xpService)
+	moderationService := services.NewModerationService(moderationRepo, feedRepo, commentRepo, contentFilterRepo, userRepo)
+	challengeService := services.NewChallengeService(challengeRepo, completionRepo, goalService, contentFilter)
+	goalShareService := services.NewGoalShareService(goalShareRepo, goalRepo, userRepo, notificationRepo, contentFilter)
+	notificationService := services.NewNotificationService(notificationRepo)
+	orgService := services.NewOrganizationService(orgRepo, goalRepo, userRepo, completionRepo, goalService)
+	calendarService := services.NewCalendarService(calendarRepo, goalRepo, completionRepo, goalService)
+	mailer := services.NewMailer(config.LoadMailConfig())
+	insightService := services.NewInsightService(goalRepo, completionRepo)
+	reviewService := services.NewReviewService(reviewRepo, goalRepo, completionRepo, userRepo, goalService, insightService, mailer)
 
 	// Initialize handlers
 	authHandler := handlers.NewAuthHandler(authService)
@@ -50,28 +130,74 @@ func main() {
 	feedHandler := handlers.NewFeedHandler(feedService)
 	commentHandler := handlers.NewCommentHandler(commentService)
 	likeHandler := handlers.NewLikeHandler(likeService)
+	moderationHandler := handlers.NewModerationHandler(moderationService)
+	challengeHandler := handlers.NewChallengeHandler(challengeService)
+	goalShareHandler := handlers.NewGoalShareHandler(goalShareService)
+	notificationHandler := handlers.NewNotificationHandler(notificationService)
+	orgHandler := handlers.NewOrganizationHandler(orgService)
+	achievementHandler := handlers.NewAchievementHandler(achievementService)
+	xpHandler := handlers.NewXPHandler(xpService)
+	exportHandler := handlers.NewExportHandler(exportService)
+	importHandler := handlers.NewImportHandler(importService)
+	accountHandler := handlers.NewAccountHandler(accountService)
+	categoryHandler := handlers.NewCategoryHandler(categoryService)
+	searchHandler := handlers.NewSearchHandler(searchService)
+	checklistHandler := handlers.NewChecklistHandler(checklistService)
+	analyticsHandler := handlers.NewAnalyticsHandler(analyticsService)
+	reviewHandler := handlers.NewReviewHandler(reviewService)
+	insightHandler := handlers.NewInsightHandler(insightService)
+	calendarHandler := handlers.NewCalendarHandler(calendarService)
 
-	// Setup router
-	router := setupRouter(authHandler, goalHandler, userHandler, feedHandler, commentHandler, likeHandler)
-	// Get port from environment or default to 8080
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	// Background jobs
+	jobs := []backgroundJob{
+		{"missed goal notifications", time.Hour, func() error {
+			return goalShareService.NotifyMissedGoals(time.Now().UTC().AddDate(0, 0, -1))
+		}},
+		{"account erasure", time.Hour, accountService.ProcessDueDeletions},
+		{"goal trash purge", time.Hour, goalService.PurgeExpiredTrash},
+		{"review generation", time.Hour, reviewService.GenerateDue},
 	}
-
-	log.Printf("Server starting on port %s", port)
-	if err := router.Run(":" + port); err != nil {
-		log.Fatal("Failed to start server:", err)
+	if config.LoadGoalConfig().AutoArchive {
+		jobs = append(jobs, backgroundJob{"goal auto-archive", time.Hour, goalService.AutoArchiveExpired})
 	}
+
+	// Setup router
+	router := setupRouter(
+		userRepo,
+		authHandler, goalHandler, userHandler, feedHandler, commentHandler, likeHandler,
+		moderationHandler, challengeHandler, goalShareHandler, notificationHandler,
+		orgHandler, achievementHandler, xpHandler, exportHandler, importHandler,
+		accountHandler, categoryHandler, searchHandler, checklistHandler, analyticsHandler,
+		reviewHandler, insightHandler, calendarHandler,
+	)
+	return router, jobs
 }
 
 func setupRouter(
+	userRepo *repositories.UserRepository,
 	authHandler *handlers.AuthHandler,
 	goalHandler *handlers.GoalHandler,
 	userHandler *handlers.UserHandler,
 	feedHandler *handlers.FeedHandler,
 	commentHandler *handlers.CommentHandler,
 	likeHandler *handlers.LikeHandler,
+	moderationHandler *handlers.ModerationHandler,
+	challengeHandler *handlers.ChallengeHandler,
+	goalShareHandler *handlers.GoalShareHandler,
+	notificationHandler *handlers.NotificationHandler,
+	orgHandler *handlers.OrganizationHandler,
+	achievementHandler *handlers.AchievementHandler,
+	xpHandler *handlers.XPHandler,
+	exportHandler *handlers.ExportHandler,
+	importHandler *handlers.ImportHandler,
+	accountHandler *handlers.AccountHandler,
+	categoryHandler *handlers.CategoryHandler,
+	searchHandler *handlers.SearchHandler,
+	checklistHandler *handlers.ChecklistHandler,
+	analyticsHandler *handlers.AnalyticsHandler,
+	reviewHandler *handlers.ReviewHandler,
+	insightHandler *handlers.InsightHandler,
+	calendarHandler *handlers.CalendarHandler,
 ) *gin.Engine {
 	router := gin.Default()
 	corsConfig := cors.DefaultConfig()
@@ -80,6 +206,7 @@ func setupRouter(
 	corsConfig.AllowMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}
 	corsConfig.AllowHeaders = []string{"Origin", "Content-Length", "Content-Type", "Authorization"}
 	router.Use(cors.New(corsConfig))
+	router.Use(middleware.ErrorHandler())
 
 	// Health check
 	router.GET("/healthz", func(c *gin.Context) {
@@ -98,11 +225,22 @@ func setupRouter(
 		}
 
 		// Public goals (feed)
-		api.GET("/feed", goalHandler.GetPublicGoals)
+		api.GET("/feed", middleware.OptionalAuthMiddleware(), goalHandler.GetPublicGoals)
 
 		// Public feed endpoints
-		api.GET("/feeds/:goal_id", feedHandler.GetFeedsByGoalID)
-		api.GET("/feed/:id", feedHandler.GetFeedByID)
+		api.GET("/feeds/:goal_id", middleware.OptionalAuthMiddleware(), feedHandler.GetFeedsByGoalID)
+		api.GET("/feed/:id", middleware.OptionalAuthMiddleware(), feedHandler.GetFeedByID)
+
+		// Achievement catalog
+		api.GET("/achievements", achievementHandler.GetCatalog)
+
+		// iCalendar feed, authenticated by the secret token in the path
+		api.GET("/calendar/:file", calendarHandler.GetFeed)
+
+		// API description
+		api.GET("/openapi.json", func(c *gin.Context) {
+			c.Data(200, "application/json; charset=utf-8", openapi.Spec)
+		})
 	}
 
 	// Protected routes
@@ -115,6 +253,51 @@ func setupRouter(
 			user.GET("/profile", userHandler.GetProfile)
 			user.PUT("/profile", userHandler.UpdateProfile)
 			user.GET("/stats", userHandler.GetUserStats)
+			user.GET("/analytics", analyticsHandler.GetAnalytics)
+			user.GET("/insights", insightHandler.GetInsights)
+
+			// Calendar feed
+			user.GET("/calendar", calendarHandler.GetFeedSettings)
+			user.POST("/calendar/token", calendarHandler.CreateToken)
+			user.DELETE("/calendar/token", calendarHandler.DeleteToken)
+
+			// Weekly and monthly reviews
+			user.GET("/reviews", reviewHandler.GetReviews)
+			user.POST("/reviews", reviewHandler.GenerateReview)
+			user.GET("/reviews/:id", reviewHandler.GetReview)
+			user.GET("/achievements", achievementHandler.GetUserAchievements)
+
+			// Follows
+			user.GET("/follows", userHandler.GetFollowing)
+			user.POST("/follows/:user_id", userHandler.Follow)
+			user.DELETE("/follows/:user_id", userHandler.Unfollow)
+
+			// XP and levels
+			user.GET("/xp", xpHandler.GetLevel)
+			user.GET("/xp/history", xpHandler.GetHistory)
+			user.GET("/xp/leaderboard", xpHandler.GetWeeklyLeaderboard)
+
+			// Data export
+			user.GET("/export", exportHandler.ExportAccount)
+			user.POST("/import", importHandler.Import)
+
+			// Account deletion
+			user.GET("/account/deletion", accountHandler.GetDeletion)
+			user.POST("/account/deletion", accountHandler.RequestDeletion)
+			user.DELETE("/account/deletion", accountHandler.CancelDeletion)
+
+			// Blocks and mutes
+			user.GET("/blocks", moderationHandler.GetBlocks)
+			user.POST("/blocks/:user_id", moderationHandler.BlockUser)
+			user.DELETE("/blocks/:user_id", moderationHandler.UnblockUser)
+			user.GET("/mutes", moderationHandler.GetMutes)
+			user.POST("/mutes/:user_id", moderationHandler.MuteUser)
+			user.DELETE("/mutes/:user_id", moderationHandler.UnmuteUser)
+
+			// Notifications
+			user.GET("/notifications", notificationHandler.GetNotifications)
+			user.POST("/notifications/read", notificationHandler.MarkAllRead)
+			user.POST("/notifications/:id/read", notificationHandler.MarkRead)
 		}
 
 		// Goal routes
@@ -122,15 +305,58 @@ func setupRouter(
 		{
 			goals.POST("/", goalHandler.CreateGoal)
 			goals.GET("/", goalHandler.GetUserGoals)
+			goals.GET("/shared", goalShareHandler.GetSharedGoals)
+			goals.GET("/trash", goalHandler.GetTrash)
+			goals.GET("/archived", goalHandler.GetArchivedGoals)
+			goals.GET("/tags", goalHandler.GetTags)
 			goals.GET("/:id", goalHandler.GetGoalByID)
 			goals.PUT("/:id", goalHandler.UpdateGoal)
 			goals.DELETE("/:id", goalHandler.DeleteGoal)
 			goals.POST("/:id/archive", goalHandler.ArchiveGoal)
+			goals.POST("/:id/unarchive", goalHandler.UnarchiveGoal)
+			goals.POST("/:id/restore", goalHandler.RestoreGoal)
+			goals.DELETE("/:id/purge", goalHandler.PurgeGoal)
 
 			// Completion routes
 			goals.POST("/:id/complete", goalHandler.MarkComplete)
+			goals.POST("/:id/measurements", goalHandler.RecordMeasurement)
+			goals.POST("/:id/slips", goalHandler.RecordSlip)
+			goals.GET("/:id/graph", goalHandler.GetGraph)
 			goals.GET("/:id/completions", goalHandler.GetCompletions)
+			goals.DELETE("/:id/completions/:date", goalHandler.RemoveCompletion)
 			goals.GET("/:id/streak", goalHandler.GetStreak)
+			goals.GET("/:id/revisions", goalHandler.GetRevisions)
+			goals.GET("/:id/periods", goalHandler.GetPeriods)
+
+			// Checklist routes
+			goals.GET("/:id/checklist", checklistHandler.GetChecklist)
+			goals.POST("/:id/checklist", checklistHandler.AddItem)
+			goals.PUT("/:id/checklist/order", checklistHandler.Reorder)
+			goals.PUT("/:id/checklist/:item_id", checklistHandler.UpdateItem)
+			goals.DELETE("/:id/checklist/:item_id", checklistHandler.DeleteItem)
+			goals.POST("/:id/checklist/:item_id/check", checklistHandler.CheckItem)
+			goals.DELETE("/:id/checklist/:item_id/check", checklistHandler.UncheckItem)
+
+			// Sharing and accountability partner routes
+			goals.GET("/:id/shares", goalShareHandler.GetShares)
+			goals.POST("/:id/shares", goalShareHandler.ShareGoal)
+			goals.DELETE("/:id/shares/:user_id", goalShareHandler.RemoveShare)
+			goals.GET("/:id/encouragements", goalShareHandler.GetEncouragements)
+			goals.POST("/:id/encouragements", goalShareHandler.Encourage)
+		}
+
+		// Search
+		protected.GET("/search", searchHandler.Search)
+
+		// Category routes
+		categories := protected.Group("/categories")
+		{
+			categories.GET("/", categoryHandler.GetCategories)
+			categories.POST("/", categoryHandler.CreateCategory)
+			categories.GET("/stats", categoryHandler.GetStats)
+			categories.PUT("/:id", categoryHandler.UpdateCategory)
+			categories.DELETE("/:id", categoryHandler.DeleteCategory)
+			categories.GET("/:id/stats", categoryHandler.GetCategoryStats)
 		}
 
 		// Feed routes
@@ -155,7 +381,63 @@ func setupRouter(
 			likes.GET("/feed/:feed_id/count", likeHandler.CountLikes)
 			likes.GET("/feed/:feed_id/exists", likeHandler.Exists)
 		}
+
+		// Challenge routes
+		challenges := protected.Group("/challenges")
+		{
+			challenges.POST("/", challengeHandler.CreateChallenge)
+			challenges.GET("/", challengeHandler.GetUserChallenges)
+			challenges.POST("/join", challengeHandler.JoinByInviteCode)
+			challenges.GET("/:id", challengeHandler.GetChallenge)
+			challenges.POST("/:id/join", challengeHandler.JoinChallenge)
+			challenges.POST("/:id/leave", challengeHandler.LeaveChallenge)
+			challenges.GET("/:id/leaderboard", challengeHandler.GetLeaderboard)
+			challenges.GET("/:id/posts", challengeHandler.GetPosts)
+			challenges.POST("/:id/posts", challengeHandler.CreatePost)
+		}
+
+		// Organization (team) routes
+		orgs := protected.Group("/orgs")
+		{
+			orgs.POST("/", orgHandler.CreateOrganization)
+			orgs.GET("/", orgHandler.GetUserOrganizations)
+			orgs.GET("/:id", orgHandler.GetOrganization)
+			orgs.GET("/:id/dashboard", orgHandler.GetDashboard)
+			orgs.GET("/:id/members", orgHandler.GetMembers)
+			orgs.POST("/:id/members", orgHandler.AddMember)
+			orgs.PUT("/:id/members/:user_id", orgHandler.UpdateMemberRole)
+			orgs.DELETE("/:id/members/:user_id", orgHandler.RemoveMember)
+			orgs.GET("/:id/goals", orgHandler.GetGoals)
+			orgs.POST("/:id/goals", orgHandler.ShareGoal)
+			orgs.DELETE("/:id/goals/:goal_id", orgHandler.UnshareGoal)
+		}
+
+		// Report routes
+		protected.POST("/reports", moderationHandler.CreateReport)
+
+		// Admin moderation routes
+		admin := protected.Group("/admin")
+		admin.Use(middleware.AdminMiddleware(userRepo))
+		{
+			admin.GET("/reports", moderationHandler.GetReports)
+			admin.POST("/reports/:id/hide", moderationHandler.HideReported)
+			admin.POST("/reports/:id/restore", moderationHandler.RestoreReported)
+			admin.GET("/flags", moderationHandler.GetContentFlags)
+		}
 	}
 
 	return router
 }
+
+// runPeriodically runs job in the background every interval and logs failures.
+func runPeriodically(name string, interval time.Duration, job func() error) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if err := job(); err != nil {
+				log.Printf("%s failed: %v", name, err)
+			}
+		}
+	}()
+}
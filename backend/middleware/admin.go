@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"database/sql"
+
+	"DoToday/models"
+	"DoToday/repositories"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminMiddleware must run after AuthMiddleware. It looks up the caller's
+// profile and rejects anyone without the admin role.
+func AdminMiddleware(userRepo *repositories.UserRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := GetUserID(c)
+		if !ok {
+			c.Error(models.Unauthorized("Unauthorized"))
+			c.Abort()
+			return
+		}
+
+		profile, err := userRepo.GetByID(userID)
+		if err != nil && err != sql.ErrNoRows {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+		if err != nil || profile.Role != models.RoleAdmin {
+			c.Error(models.Forbidden("Admin access required"))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
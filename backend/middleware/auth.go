@@ -1,10 +1,11 @@
 package middleware
 
 import (
-	"net/http"
 	"os"
 	"strings"
 
+	"DoToday/models"
+
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 )
@@ -18,25 +19,21 @@ func AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
+			c.Error(models.Unauthorized("Authorization header required"))
 			c.Abort()
 			return
 		}
 
 		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
 		if tokenString == authHeader {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Bearer token required"})
+			c.Error(models.Unauthorized("Bearer token required"))
 			c.Abort()
 			return
 		}
 
-		claims := &Claims{}
-		token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-			return []byte(os.Getenv("JWT_SECRET")), nil
-		})
-
-		if err != nil || !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		claims, err := parseToken(tokenString)
+		if err != nil {
+			c.Error(models.Unauthorized("Invalid token"))
 			c.Abort()
 			return
 		}
@@ -47,7 +44,7 @@ func AuthMiddleware() gin.HandlerFunc {
 			userID = claims.Subject
 		}
 		if userID == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in token"})
+			c.Error(models.Unauthorized("User ID not found in token"))
 			c.Abort()
 			return
 		}
@@ -56,6 +53,41 @@ func AuthMiddleware() gin.HandlerFunc {
 	}
 }
 
+// OptionalAuthMiddleware sets the user ID when a valid bearer token is sent
+// but lets anonymous requests through, so public routes can still tailor
+// their results to a signed-in viewer.
+func OptionalAuthMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenString := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if tokenString != "" {
+			if claims, err := parseToken(tokenString); err == nil {
+				userID := claims.UserID
+				if userID == "" {
+					userID = claims.Subject
+				}
+				if userID != "" {
+					c.Set("user_id", userID)
+				}
+			}
+		}
+		c.Next()
+	}
+}
+
+func parseToken(tokenString string) (*Claims, error) {
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		return []byte(os.Getenv("JWT_SECRET")), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid {
+		return nil, jwt.ErrTokenInvalidClaims
+	}
+	return claims, nil
+}
+
 func GetUserID(c *gin.Context) (string, bool) {
 	userID, exists := c.Get("user_id")
 	if !exists {
@@ -0,0 +1,156 @@
+package middleware
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"DoToday/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+var errorStatus = map[string]int{
+	models.ErrorValidation:   http.StatusBadRequest,
+	models.ErrorUnauthorized: http.StatusUnauthorized,
+	models.ErrorForbidden:    http.StatusForbidden,
+	models.ErrorNotFound:     http.StatusNotFound,
+	models.ErrorConflict:     http.StatusConflict,
+	models.ErrorRejected:     http.StatusUnprocessableEntity,
+	models.ErrorInternal:     http.StatusInternalServerError,
+}
+
+// ErrorHandler writes the last error a handler recorded with c.Error as
+// {"error": {"code", "message", "fields"}}. Domain errors keep their message
+// and map to the status of their code, missing rows are not found, errors
+// recorded as gin.ErrorTypeBind are validation errors with the offending
+// fields, and anything else is logged and reported as an internal error.
+func ErrorHandler() gin.HandlerFunc {
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(fieldName)
+	}
+
+	return func(c *gin.Context) {
+		c.Next()
+
+		last := c.Errors.Last()
+		if last == nil || c.Writer.Written() {
+			return
+		}
+		appErr := toAppError(last)
+		if appErr.Code == models.ErrorInternal {
+			log.Printf("%s %s failed: %v", c.Request.Method, c.Request.URL.Path, last.Err)
+		}
+		c.JSON(errorStatus[appErr.Code], gin.H{"error": appErr})
+	}
+}
+
+func toAppError(ginErr *gin.Error) *models.Error {
+	err := ginErr.Err
+	var appErr *models.Error
+	switch {
+	case errors.As(err, &appErr):
+		if _, ok := errorStatus[appErr.Code]; ok {
+			return appErr
+		}
+	case errors.Is(err, sql.ErrNoRows):
+		return models.NotFound("Not found")
+	case ginErr.IsType(gin.ErrorTypeBind):
+		return bindError(err)
+	}
+	return &models.Error{Code: models.ErrorInternal, Message: "Internal server error"}
+}
+
+// bindError describes why a request body or form could not be bound.
+func bindError(err error) *models.Error {
+	var validationErrs validator.ValidationErrors
+	var typeErr *json.UnmarshalTypeError
+	var syntaxErr *json.SyntaxError
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.As(err, &validationErrs):
+		fields := make([]models.FieldError, len(validationErrs))
+		for i, fe := range validationErrs {
+			fields[i] = models.FieldError{Field: fe.Field(), Message: ruleMessage(fe)}
+		}
+		return models.InvalidFields("Invalid request", fields...)
+	case errors.As(err, &typeErr):
+		return models.InvalidFields("Invalid request", models.FieldError{
+			Field:   typeErr.Field,
+			Message: "must be a " + jsonTypeName(typeErr.Type),
+		})
+	case errors.As(err, &syntaxErr), errors.Is(err, io.ErrUnexpectedEOF):
+		return models.Invalid("Malformed JSON body")
+	case errors.Is(err, io.EOF):
+		return models.Invalid("Request body is required")
+	case errors.As(err, &maxBytesErr):
+		return models.Invalid("Request body is too large")
+	}
+	return models.Invalid(err.Error())
+}
+
+// ruleMessage words a failed binding rule.
+func ruleMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required", "required_without":
+		return "is required"
+	case "oneof":
+		return "must be one of: " + strings.ReplaceAll(fe.Param(), " ", ", ")
+	case "uuid":
+		return "must be a UUID"
+	case "hexcolor":
+		return "must be a hex color"
+	case "datetime":
+		return "must be a date formatted as " + fe.Param()
+	case "min", "max":
+		bound := "at least"
+		if fe.Tag() == "max" {
+			bound = "at most"
+		}
+		switch fe.Kind() {
+		case reflect.String:
+			return fmt.Sprintf("must be %s %s characters", bound, fe.Param())
+		case reflect.Slice:
+			return fmt.Sprintf("must have %s %s items", bound, fe.Param())
+		}
+		return fmt.Sprintf("must be %s %s", bound, fe.Param())
+	}
+	return "failed the " + fe.Tag() + " rule"
+}
+
+// fieldName names struct fields in validation errors as clients send them.
+func fieldName(f reflect.StructField) string {
+	for _, tag := range []string{"json", "form"} {
+		if name, _, _ := strings.Cut(f.Tag.Get(tag), ","); name != "" && name != "-" {
+			return name
+		}
+	}
+	return f.Name
+}
+
+func jsonTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "list"
+	case reflect.Ptr:
+		return jsonTypeName(t.Elem())
+	}
+	return "object"
+}
@@ -0,0 +1,34 @@
+-- Content moderation: roles, blocks, mutes, reports and hidden content.
+
+ALTER TABLE profiles ADD COLUMN IF NOT EXISTS role text NOT NULL DEFAULT 'user';
+
+ALTER TABLE feeds ADD COLUMN IF NOT EXISTS hidden boolean NOT NULL DEFAULT false;
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS hidden boolean NOT NULL DEFAULT false;
+
+CREATE TABLE IF NOT EXISTS blocks (
+	blocker_id uuid NOT NULL REFERENCES profiles(id) ON DELETE CASCADE,
+	blocked_id uuid NOT NULL REFERENCES profiles(id) ON DELETE CASCADE,
+	created_at timestamptz NOT NULL DEFAULT now(),
+	PRIMARY KEY (blocker_id, blocked_id)
+);
+
+CREATE TABLE IF NOT EXISTS mutes (
+	muter_id   uuid NOT NULL REFERENCES profiles(id) ON DELETE CASCADE,
+	muted_id   uuid NOT NULL REFERENCES profiles(id) ON DELETE CASCADE,
+	created_at timestamptz NOT NULL DEFAULT now(),
+	PRIMARY KEY (muter_id, muted_id)
+);
+
+CREATE TABLE IF NOT EXISTS reports (
+	id          uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+	reporter_id uuid NOT NULL REFERENCES profiles(id) ON DELETE CASCADE,
+	target_type text NOT NULL CHECK (target_type IN ('feed', 'comment')),
+	target_id   uuid NOT NULL,
+	reason      text NOT NULL,
+	status      text NOT NULL DEFAULT 'open',
+	resolved_by uuid REFERENCES profiles(id) ON DELETE SET NULL,
+	resolved_at timestamptz,
+	created_at  timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS reports_status_idx ON reports (status, created_at);
@@ -0,0 +1,13 @@
+-- Content filter: user content let through but flagged for review.
+
+CREATE TABLE IF NOT EXISTS content_flags (
+	id         uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+	user_id    uuid NOT NULL REFERENCES profiles(id) ON DELETE CASCADE,
+	kind       text NOT NULL,
+	target_id  uuid,
+	content    text NOT NULL,
+	reasons    text NOT NULL,
+	created_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS content_flags_created_idx ON content_flags (created_at);
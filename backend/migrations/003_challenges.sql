@@ -0,0 +1,37 @@
+-- Group challenges: a shared goal template that each participant gets a copy of.
+
+CREATE TABLE IF NOT EXISTS challenges (
+	id               uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+	owner_id         uuid NOT NULL REFERENCES profiles(id) ON DELETE CASCADE,
+	title            text NOT NULL,
+	description      text NOT NULL DEFAULT '',
+	start_date       date NOT NULL,
+	end_date         date NOT NULL,
+	goal_title       text NOT NULL,
+	goal_category    text NOT NULL,
+	goal_description text NOT NULL DEFAULT '',
+	frequency        text NOT NULL DEFAULT 'daily',
+	target_count     integer NOT NULL DEFAULT 1,
+	invite_code      text NOT NULL UNIQUE,
+	is_public        boolean NOT NULL DEFAULT false,
+	created_at       timestamptz NOT NULL DEFAULT now(),
+	CHECK (end_date >= start_date)
+);
+
+CREATE TABLE IF NOT EXISTS challenge_participants (
+	challenge_id uuid NOT NULL REFERENCES challenges(id) ON DELETE CASCADE,
+	user_id      uuid NOT NULL REFERENCES profiles(id) ON DELETE CASCADE,
+	goal_id      uuid NOT NULL REFERENCES goals(id) ON DELETE CASCADE,
+	joined_at    timestamptz NOT NULL DEFAULT now(),
+	PRIMARY KEY (challenge_id, user_id)
+);
+
+CREATE TABLE IF NOT EXISTS challenge_posts (
+	id           uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+	challenge_id uuid NOT NULL REFERENCES challenges(id) ON DELETE CASCADE,
+	user_id      uuid NOT NULL REFERENCES profiles(id) ON DELETE CASCADE,
+	content      text NOT NULL,
+	created_at   timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS challenge_posts_challenge_idx ON challenge_posts (challenge_id, created_at);
@@ -0,0 +1,36 @@
+-- Accountability partners: per-goal sharing grants, encouragement and notifications.
+
+CREATE TABLE IF NOT EXISTS goal_shares (
+	goal_id    uuid NOT NULL REFERENCES goals(id) ON DELETE CASCADE,
+	user_id    uuid NOT NULL REFERENCES profiles(id) ON DELETE CASCADE,
+	role       text NOT NULL CHECK (role IN ('viewer', 'partner')),
+	created_at timestamptz NOT NULL DEFAULT now(),
+	PRIMARY KEY (goal_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS goal_shares_user_idx ON goal_shares (user_id);
+
+CREATE TABLE IF NOT EXISTS encouragements (
+	id         uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+	goal_id    uuid NOT NULL REFERENCES goals(id) ON DELETE CASCADE,
+	user_id    uuid NOT NULL REFERENCES profiles(id) ON DELETE CASCADE,
+	message    text NOT NULL,
+	created_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS encouragements_goal_idx ON encouragements (goal_id, created_at);
+
+CREATE TABLE IF NOT EXISTS notifications (
+	id         uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+	user_id    uuid NOT NULL REFERENCES profiles(id) ON DELETE CASCADE,
+	kind       text NOT NULL,
+	goal_id    uuid REFERENCES goals(id) ON DELETE CASCADE,
+	message    text NOT NULL,
+	ref_date   date,
+	read_at    timestamptz,
+	created_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS notifications_user_idx ON notifications (user_id, created_at);
+-- One notification per recipient, kind, goal and day
+CREATE UNIQUE INDEX IF NOT EXISTS notifications_dedupe_idx ON notifications (user_id, kind, goal_id, ref_date);
@@ -0,0 +1,27 @@
+-- Teams: organizations, their members and the goals shared with them.
+
+CREATE TABLE IF NOT EXISTS organizations (
+	id         uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+	name       text NOT NULL,
+	owner_id   uuid NOT NULL REFERENCES profiles(id) ON DELETE CASCADE,
+	created_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS organization_members (
+	organization_id uuid NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+	user_id         uuid NOT NULL REFERENCES profiles(id) ON DELETE CASCADE,
+	role            text NOT NULL CHECK (role IN ('owner', 'admin', 'member')),
+	joined_at       timestamptz NOT NULL DEFAULT now(),
+	PRIMARY KEY (organization_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS organization_members_user_idx ON organization_members (user_id);
+
+CREATE TABLE IF NOT EXISTS organization_goals (
+	organization_id uuid NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+	goal_id         uuid NOT NULL REFERENCES goals(id) ON DELETE CASCADE,
+	created_at      timestamptz NOT NULL DEFAULT now(),
+	PRIMARY KEY (organization_id, goal_id)
+);
+
+CREATE INDEX IF NOT EXISTS organization_goals_goal_idx ON organization_goals (goal_id);
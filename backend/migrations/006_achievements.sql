@@ -0,0 +1,11 @@
+-- Achievements: badges awarded per user, and an opt-in to post them to the feed.
+
+ALTER TABLE profiles ADD COLUMN IF NOT EXISTS share_achievements boolean NOT NULL DEFAULT false;
+
+CREATE TABLE IF NOT EXISTS user_achievements (
+	user_id    uuid NOT NULL REFERENCES profiles(id) ON DELETE CASCADE,
+	code       text NOT NULL,
+	goal_id    uuid REFERENCES goals(id) ON DELETE SET NULL,
+	awarded_at timestamptz NOT NULL DEFAULT now(),
+	PRIMARY KEY (user_id, code)
+);
@@ -0,0 +1,25 @@
+-- XP: an append-only points ledger and follows for the weekly leaderboard.
+
+CREATE TABLE IF NOT EXISTS xp_ledger (
+	id            uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+	user_id       uuid NOT NULL REFERENCES profiles(id) ON DELETE CASCADE,
+	amount        integer NOT NULL,
+	reason        text NOT NULL,
+	ref           text UNIQUE,
+	goal_id       uuid,
+	completion_id uuid,
+	reverses      uuid REFERENCES xp_ledger(id),
+	created_at    timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS xp_ledger_user_idx ON xp_ledger (user_id, created_at);
+CREATE INDEX IF NOT EXISTS xp_ledger_completion_idx ON xp_ledger (completion_id);
+CREATE INDEX IF NOT EXISTS xp_ledger_goal_idx ON xp_ledger (goal_id);
+CREATE UNIQUE INDEX IF NOT EXISTS xp_ledger_reverses_idx ON xp_ledger (reverses);
+
+CREATE TABLE IF NOT EXISTS follows (
+	follower_id uuid NOT NULL REFERENCES profiles(id) ON DELETE CASCADE,
+	followee_id uuid NOT NULL REFERENCES profiles(id) ON DELETE CASCADE,
+	created_at  timestamptz NOT NULL DEFAULT now(),
+	PRIMARY KEY (follower_id, followee_id)
+);
@@ -0,0 +1,29 @@
+-- Account deletion: requests with a grace period, kept afterwards as the
+-- erasure audit record. user_id has no foreign key so the row outlives the
+-- profile.
+
+CREATE TABLE IF NOT EXISTS account_deletions (
+	id                  uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+	user_id             uuid NOT NULL,
+	status              text NOT NULL DEFAULT 'pending',
+	requested_at        timestamptz NOT NULL DEFAULT now(),
+	scheduled_for       timestamptz NOT NULL,
+	cancelled_at        timestamptz,
+	completed_at        timestamptz,
+	goals_deleted       integer NOT NULL DEFAULT 0,
+	completions_deleted integer NOT NULL DEFAULT 0,
+	feeds_deleted       integer NOT NULL DEFAULT 0,
+	comments_deleted    integer NOT NULL DEFAULT 0,
+	comments_anonymized integer NOT NULL DEFAULT 0,
+	likes_deleted       integer NOT NULL DEFAULT 0,
+	auth_user_deleted   boolean NOT NULL DEFAULT false
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS account_deletions_pending_idx
+	ON account_deletions (user_id) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS account_deletions_due_idx
+	ON account_deletions (scheduled_for) WHERE status = 'pending';
+
+-- Comments left on other people's posts survive the author's erasure with no
+-- author.
+ALTER TABLE comments ALTER COLUMN user_id DROP NOT NULL;
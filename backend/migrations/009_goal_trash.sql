@@ -0,0 +1,6 @@
+-- Goal trash: deleted goals keep their rows with deleted_at set until they are
+-- restored or purged. This is separate from archived.
+
+ALTER TABLE goals ADD COLUMN IF NOT EXISTS deleted_at timestamptz;
+
+CREATE INDEX IF NOT EXISTS goals_trash_idx ON goals (user_id, deleted_at) WHERE deleted_at IS NOT NULL;
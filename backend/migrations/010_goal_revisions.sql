@@ -0,0 +1,18 @@
+-- Goal revisions: each schedule change (frequency and target count) with the
+-- date it takes effect, so past periods are judged by the schedule in force
+-- at the time. Existing goals get a revision from their creation date.
+
+CREATE TABLE IF NOT EXISTS goal_revisions (
+	id             uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+	goal_id        uuid NOT NULL REFERENCES goals(id) ON DELETE CASCADE,
+	frequency      text NOT NULL,
+	target_count   integer NOT NULL,
+	effective_from date NOT NULL,
+	created_at     timestamptz NOT NULL DEFAULT now(),
+	UNIQUE (goal_id, effective_from)
+);
+
+INSERT INTO goal_revisions (goal_id, frequency, target_count, effective_from)
+SELECT id, COALESCE(NULLIF(frequency, ''), 'daily'), GREATEST(target_count, 1), created_at::date
+FROM goals
+ON CONFLICT (goal_id, effective_from) DO NOTHING;
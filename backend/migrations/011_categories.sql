@@ -0,0 +1,50 @@
+-- Categories: system defaults (user_id NULL) plus user-defined ones. Goals
+-- link to a category by category_id; goals.category keeps the name for
+-- existing readers.
+
+CREATE TABLE IF NOT EXISTS categories (
+	id         uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+	user_id    uuid REFERENCES profiles(id) ON DELETE CASCADE,
+	name       text NOT NULL,
+	color      text NOT NULL DEFAULT '#6b7280',
+	icon       text NOT NULL DEFAULT 'tag',
+	created_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS categories_name_idx
+	ON categories (COALESCE(user_id, '00000000-0000-0000-0000-000000000000'::uuid), lower(name));
+
+INSERT INTO categories (user_id, name, color, icon) VALUES
+	(NULL, 'health',   '#16a34a', 'heart'),
+	(NULL, 'work',     '#2563eb', 'briefcase'),
+	(NULL, 'personal', '#9333ea', 'user'),
+	(NULL, 'learning', '#ea580c', 'book'),
+	(NULL, 'other',    '#6b7280', 'tag')
+ON CONFLICT (COALESCE(user_id, '00000000-0000-0000-0000-000000000000'::uuid), lower(name)) DO NOTHING;
+
+ALTER TABLE goals ADD COLUMN IF NOT EXISTS category_id uuid REFERENCES categories(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS goals_category_idx ON goals (category_id);
+
+-- Free-text values matching a system category (ignoring case and spacing)
+-- link to it.
+UPDATE goals g
+SET category_id = c.id, category = c.name
+FROM categories c
+WHERE g.category_id IS NULL AND c.user_id IS NULL AND lower(c.name) = lower(trim(g.category));
+
+-- Every other distinct value becomes a category of the goal's owner.
+INSERT INTO categories (user_id, name)
+SELECT DISTINCT ON (g.user_id, lower(trim(g.category))) g.user_id, trim(g.category)
+FROM goals g
+WHERE g.category_id IS NULL AND trim(g.category) <> ''
+ON CONFLICT (COALESCE(user_id, '00000000-0000-0000-0000-000000000000'::uuid), lower(name)) DO NOTHING;
+
+UPDATE goals g
+SET category_id = c.id, category = c.name
+FROM categories c
+WHERE g.category_id IS NULL AND c.user_id = g.user_id AND lower(c.name) = lower(trim(g.category));
+
+UPDATE goals g
+SET category_id = c.id, category = c.name
+FROM categories c
+WHERE g.category_id IS NULL AND c.user_id IS NULL AND c.name = 'other';
@@ -0,0 +1,24 @@
+-- Goal tags and full-text search over goals, feed posts and comments.
+
+CREATE TABLE IF NOT EXISTS goal_tags (
+	goal_id uuid NOT NULL REFERENCES goals(id) ON DELETE CASCADE,
+	tag     text NOT NULL,
+	PRIMARY KEY (goal_id, tag)
+);
+
+CREATE INDEX IF NOT EXISTS goal_tags_tag_idx ON goal_tags (tag);
+
+ALTER TABLE goals ADD COLUMN IF NOT EXISTS search_vector tsvector
+	GENERATED ALWAYS AS (
+		setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+		setweight(to_tsvector('english', coalesce(description, '')), 'B')
+	) STORED;
+CREATE INDEX IF NOT EXISTS goals_search_idx ON goals USING gin (search_vector);
+
+ALTER TABLE feeds ADD COLUMN IF NOT EXISTS search_vector tsvector
+	GENERATED ALWAYS AS (to_tsvector('english', coalesce(description, ''))) STORED;
+CREATE INDEX IF NOT EXISTS feeds_search_idx ON feeds USING gin (search_vector);
+
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS search_vector tsvector
+	GENERATED ALWAYS AS (to_tsvector('english', coalesce(content, ''))) STORED;
+CREATE INDEX IF NOT EXISTS comments_search_idx ON comments USING gin (search_vector);
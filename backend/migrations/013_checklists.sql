@@ -0,0 +1,20 @@
+-- Ordered checklist items under a goal and which items were checked on each
+-- day. Only required items count towards completing the day.
+
+CREATE TABLE IF NOT EXISTS checklist_items (
+	id         uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+	goal_id    uuid NOT NULL REFERENCES goals(id) ON DELETE CASCADE,
+	title      text NOT NULL,
+	position   int NOT NULL,
+	required   boolean NOT NULL DEFAULT true,
+	created_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS checklist_items_goal_idx ON checklist_items (goal_id, position);
+
+CREATE TABLE IF NOT EXISTS checklist_checks (
+	item_id    uuid NOT NULL REFERENCES checklist_items(id) ON DELETE CASCADE,
+	date       date NOT NULL,
+	checked_at timestamptz NOT NULL DEFAULT now(),
+	PRIMARY KEY (item_id, date)
+);
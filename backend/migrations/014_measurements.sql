@@ -0,0 +1,11 @@
+-- Measurable goals: a unit, a target value with a comparison and how the
+-- day's values combine over a period. Completions of measurable goals carry
+-- the day's value; count is the number of measurements that day.
+
+ALTER TABLE goals ADD COLUMN IF NOT EXISTS kind text NOT NULL DEFAULT 'habit';
+ALTER TABLE goals ADD COLUMN IF NOT EXISTS unit text NOT NULL DEFAULT '';
+ALTER TABLE goals ADD COLUMN IF NOT EXISTS target_value numeric;
+ALTER TABLE goals ADD COLUMN IF NOT EXISTS comparison text NOT NULL DEFAULT '';
+ALTER TABLE goals ADD COLUMN IF NOT EXISTS aggregation text NOT NULL DEFAULT '';
+
+ALTER TABLE completions ADD COLUMN IF NOT EXISTS value numeric;
@@ -0,0 +1,3 @@
+-- Goals created without a deadline were stored with Go's zero time instead
+-- of NULL.
+UPDATE goals SET deadline = NULL WHERE deadline < '1900-01-01';
@@ -0,0 +1,18 @@
+-- Weekly and monthly reviews. summary holds the generated report; one review
+-- per user, period and start date.
+
+CREATE TABLE IF NOT EXISTS reviews (
+	id           uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+	user_id      uuid NOT NULL REFERENCES profiles(id) ON DELETE CASCADE,
+	period       text NOT NULL,
+	period_start date NOT NULL,
+	period_end   date NOT NULL,
+	summary      jsonb NOT NULL,
+	created_at   timestamptz NOT NULL DEFAULT now(),
+	emailed_at   timestamptz,
+	UNIQUE (user_id, period, period_start)
+);
+
+CREATE INDEX IF NOT EXISTS reviews_user_idx ON reviews (user_id, period_start DESC);
+
+ALTER TABLE profiles ADD COLUMN IF NOT EXISTS review_emails boolean NOT NULL DEFAULT true;
@@ -0,0 +1,9 @@
+-- Calendar feeds: one secret token per user for the iCalendar feed. Only the
+-- token's SHA-256 hash is stored.
+
+CREATE TABLE IF NOT EXISTS calendar_feeds (
+	user_id      uuid PRIMARY KEY REFERENCES profiles(id) ON DELETE CASCADE,
+	token_hash   text NOT NULL UNIQUE,
+	created_at   timestamptz NOT NULL DEFAULT now(),
+	last_used_at timestamptz
+);
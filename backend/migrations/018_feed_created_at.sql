@@ -0,0 +1,10 @@
+-- Feed post times: feeds.date is the day a post is about, truncated to
+-- midnight, so it cannot tell how recently a post was written. created_at
+-- records that; existing posts take their date.
+
+ALTER TABLE feeds ADD COLUMN IF NOT EXISTS created_at timestamptz;
+
+UPDATE feeds SET created_at = COALESCE(date, now()) WHERE created_at IS NULL;
+
+ALTER TABLE feeds ALTER COLUMN created_at SET DEFAULT now();
+ALTER TABLE feeds ALTER COLUMN created_at SET NOT NULL;
@@ -0,0 +1,5 @@
+-- Unarchive times: auto-archiving re-archived goals the user had brought back
+-- after their deadline. unarchived_at records when a goal was last
+-- unarchived, and goals unarchived after their deadline are left alone.
+
+ALTER TABLE goals ADD COLUMN IF NOT EXISTS unarchived_at timestamptz;
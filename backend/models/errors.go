@@ -0,0 +1,82 @@
+package models
+
+import "fmt"
+
+// Error codes
+const (
+	ErrorValidation   = "validation"
+	ErrorUnauthorized = "unauthorized"
+	ErrorForbidden    = "forbidden"
+	ErrorNotFound     = "not_found"
+	ErrorConflict     = "conflict"
+	ErrorRejected     = "rejected"
+	ErrorInternal     = "internal"
+)
+
+// Error is a domain error returned by services. The error middleware turns
+// it into the JSON body {"error": {...}} with the status matching its code.
+type Error struct {
+	Code    string       `json:"code"`
+	Message string       `json:"message"`
+	Fields  []FieldError `json:"fields,omitempty"`
+}
+
+// FieldError explains why a request field was invalid.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Is matches errors with the same code, so errors.Is(err, &Error{Code:
+// ErrorNotFound}) tests for any not found error.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code && (t.Message == "" || t.Message == e.Message)
+}
+
+func newError(code, format string, args []interface{}) *Error {
+	if len(args) > 0 {
+		format = fmt.Sprintf(format, args...)
+	}
+	return &Error{Code: code, Message: format}
+}
+
+// Invalid reports a request that breaks a validation rule.
+func Invalid(format string, args ...interface{}) *Error {
+	return newError(ErrorValidation, format, args)
+}
+
+// InvalidFields reports a request with invalid fields.
+func InvalidFields(message string, fields ...FieldError) *Error {
+	return &Error{Code: ErrorValidation, Message: message, Fields: fields}
+}
+
+// Unauthorized reports a caller who is not signed in or failed to sign in.
+func Unauthorized(format string, args ...interface{}) *Error {
+	return newError(ErrorUnauthorized, format, args)
+}
+
+// Forbidden reports a signed-in caller who may not do what they asked.
+func Forbidden(format string, args ...interface{}) *Error {
+	return newError(ErrorForbidden, format, args)
+}
+
+// NotFound reports a missing resource.
+func NotFound(format string, args ...interface{}) *Error {
+	return newError(ErrorNotFound, format, args)
+}
+
+// Conflict reports a request that clashes with the current state.
+func Conflict(format string, args ...interface{}) *Error {
+	return newError(ErrorConflict, format, args)
+}
+
+// Rejected reports well-formed content that cannot be accepted, such as
+// posts stopped by the content filter or unreadable import files.
+func Rejected(format string, args ...interface{}) *Error {
+	return newError(ErrorRejected, format, args)
+}
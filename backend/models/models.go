@@ -9,24 +9,207 @@ type Profile struct {
 	ID        string    `json:"id" gorm:"primaryKey"`
 	Username  string    `json:"username" gorm:"unique;not null"`
 	Email     string    `json:"email" gorm:"unique;not null"`
+	Role      string    `json:"role" gorm:"default:'user'"`
 	CreatedAt time.Time `json:"created_at"`
+
+	ShareAchievements bool               `json:"share_achievements" gorm:"default:false"`
+	ReviewEmails      bool               `json:"review_emails" gorm:"default:true"`
+	Achievements      []*UserAchievement `json:"achievements,omitempty" gorm:"-"`
 }
 
+// Profile roles
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
 // goals
 type Goal struct {
-	ID            string        `json:"id" gorm:"primaryKey;default:gen_random_uuid()"`
-	UserID        string        `json:"user_id" gorm:"not null"`
-	Title         string        `json:"title" gorm:"not null"`
-	Category      string        `json:"category" gorm:"not null"`
-	Description   string        `json:"description"`
-	Frequency     string        `json:"frequency" gorm:"default:'daily'"`
-	TargetCount   int           `json:"target_count" gorm:"default:1"`
-	Deadline      *time.Time    `json:"deadline"`
-	IsPublic      bool          `json:"is_public" gorm:"default:false"`
-	CurrentStreak int           `json:"current_streak" gorm:"default:0"`
-	Archived      bool          `json:"archived" gorm:"default:false"`
-	CreatedAt     time.Time     `json:"created_at"`
-	Completions   []*Completion `json:"completions" gorm:"-"`
+	ID            string             `json:"id" gorm:"primaryKey;default:gen_random_uuid()"`
+	UserID        string             `json:"user_id" gorm:"not null"`
+	Title         string             `json:"title" gorm:"not null"`
+	Category      string             `json:"category" gorm:"not null"`
+	CategoryID    *string            `json:"category_id"`
+	Description   string             `json:"description"`
+	Frequency     string             `json:"frequency" gorm:"default:'daily'"`
+	TargetCount   int                `json:"target_count" gorm:"default:1"`
+	Kind          string             `json:"kind" gorm:"default:'habit'"`
+	Unit          string             `json:"unit,omitempty"`
+	TargetValue   *float64           `json:"target_value,omitempty"`
+	Comparison    string             `json:"comparison,omitempty"`
+	Aggregation   string             `json:"aggregation,omitempty"`
+	Deadline      *time.Time         `json:"deadline"`
+	IsPublic      bool               `json:"is_public" gorm:"default:false"`
+	CurrentStreak int                `json:"current_streak" gorm:"default:0"`
+	Archived      bool               `json:"archived" gorm:"default:false"`
+	CreatedAt     time.Time          `json:"created_at"`
+	DeletedAt     *time.Time         `json:"deleted_at,omitempty"`
+	Tags          []string           `json:"tags" gorm:"-"`
+	Completions   []*Completion      `json:"completions" gorm:"-"`
+	Stats         *StreakResponse    `json:"stats,omitempty" gorm:"-"`
+	Milestone     *MilestoneProgress `json:"milestone,omitempty" gorm:"-"`
+}
+
+// MilestoneProgress forecasts a milestone goal. Paces are per day; RecentPace
+// covers the last days up to the forecast window and ProjectedDate is when
+// the target is reached at that pace (nil without recent progress)
+type MilestoneProgress struct {
+	Target         float64    `json:"target"`
+	Progress       float64    `json:"progress"`
+	Percent        float64    `json:"percent"`
+	Remaining      float64    `json:"remaining"`
+	DaysLeft       int        `json:"days_left"`
+	RequiredPace   float64    `json:"required_pace"`
+	RecentPace     float64    `json:"recent_pace"`
+	ProjectedDate  *time.Time `json:"projected_date"`
+	Achieved       bool       `json:"achieved"`
+	BehindSchedule bool       `json:"behind_schedule"`
+}
+
+// categories; UserID is nil for system defaults
+type Category struct {
+	ID        string    `json:"id" gorm:"primaryKey;default:gen_random_uuid()"`
+	UserID    *string   `json:"user_id"`
+	Name      string    `json:"name" gorm:"not null"`
+	Color     string    `json:"color"`
+	Icon      string    `json:"icon"`
+	IsSystem  bool      `json:"is_system" gorm:"-"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// DefaultCategoryName is the system category goals fall back to
+const DefaultCategoryName = "other"
+
+// CategoryStats summarises the user's goals in one category
+type CategoryStats struct {
+	CategoryID        string  `json:"category_id"`
+	Name              string  `json:"name"`
+	Color             string  `json:"color"`
+	Icon              string  `json:"icon"`
+	ActiveGoals       int     `json:"active_goals"`
+	ArchivedGoals     int     `json:"archived_goals"`
+	Completions       int     `json:"completions"`
+	RecentCompletions int     `json:"recent_completions"`
+	BestStreak        int     `json:"best_streak"`
+	AverageStreak     float64 `json:"average_streak"`
+}
+
+// TagCount is one of the user's tags and how many goals carry it
+type TagCount struct {
+	Tag   string `json:"tag"`
+	Goals int    `json:"goals"`
+}
+
+// Search result types
+const (
+	SearchTypeGoal    = "goal"
+	SearchTypeFeed    = "feed"
+	SearchTypeComment = "comment"
+)
+
+// SearchQuery is a validated search request; Terms holds the lowercased
+// words of Text for the simple (non full-text) search
+type SearchQuery struct {
+	Text       string
+	Terms      []string
+	Types      []string
+	Tag        string
+	CategoryID string
+	From       *time.Time
+	To         *time.Time
+	Limit      int
+}
+
+// SearchResult is a matching goal, feed post or comment. Snippet is HTML
+// escaped with the matched words wrapped in <mark>
+type SearchResult struct {
+	Type      string    `json:"type"`
+	ID        string    `json:"id"`
+	GoalID    string    `json:"goal_id"`
+	GoalTitle string    `json:"goal_title"`
+	Snippet   string    `json:"snippet"`
+	Rank      float64   `json:"rank"`
+	Date      time.Time `json:"date"`
+}
+
+// Goal frequencies
+const (
+	FrequencyDaily   = "daily"
+	FrequencyWeekly  = "weekly"
+	FrequencyMonthly = "monthly"
+)
+
+// Goal kinds. Habit goals are checked off, measurable goals record a value,
+// quit goals log slips, their streak being the days since the last one, and
+// milestone goals work towards a total (TargetValue) by their deadline
+const (
+	GoalKindHabit      = "habit"
+	GoalKindMeasurable = "measurable"
+	GoalKindQuit       = "quit"
+	GoalKindMilestone  = "milestone"
+)
+
+// How a measurable goal's value is compared with its target
+const (
+	ComparisonAtLeast = "at_least"
+	ComparisonAtMost  = "at_most"
+	ComparisonExactly = "exactly"
+)
+
+// How a measurable goal's daily values combine over a period
+const (
+	AggregationSum = "sum"
+	AggregationMax = "max"
+	AggregationAvg = "avg"
+)
+
+// goal_revisions
+type GoalRevision struct {
+	ID            string    `json:"id" gorm:"primaryKey;default:gen_random_uuid()"`
+	GoalID        string    `json:"goal_id" gorm:"not null"`
+	Frequency     string    `json:"frequency" gorm:"not null"`
+	TargetCount   int       `json:"target_count" gorm:"not null"`
+	EffectiveFrom time.Time `json:"effective_from" gorm:"type:date;not null"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// GoalPeriod is one scheduled period of a goal, judged by the revision that
+// was in force when the period started
+type GoalPeriod struct {
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+	Frequency   string    `json:"frequency"`
+	TargetCount int       `json:"target_count"`
+	Completions int       `json:"completions"`
+	Value       *float64  `json:"value,omitempty"`
+	Met         bool      `json:"met"`
+}
+
+// checklist_items; Required items must all be checked to complete the day
+type ChecklistItem struct {
+	ID        string    `json:"id" gorm:"primaryKey;default:gen_random_uuid()"`
+	GoalID    string    `json:"goal_id" gorm:"not null"`
+	Title     string    `json:"title" gorm:"not null"`
+	Position  int       `json:"position" gorm:"not null"`
+	Required  bool      `json:"required" gorm:"default:true"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ChecklistItemState is a checklist item with its state on one day
+type ChecklistItemState struct {
+	*ChecklistItem
+	Checked   bool       `json:"checked"`
+	CheckedAt *time.Time `json:"checked_at"`
+}
+
+// ChecklistDay is a goal's checklist on one day. Done means every required
+// item is checked; Completed means the day's completion is recorded
+type ChecklistDay struct {
+	GoalID    string                `json:"goal_id"`
+	Date      time.Time             `json:"date"`
+	Items     []*ChecklistItemState `json:"items"`
+	Done      bool                  `json:"done"`
+	Completed bool                  `json:"completed"`
 }
 
 // completions
@@ -35,6 +218,7 @@ type Completion struct {
 	GoalID    string    `json:"goal_id" gorm:"not null"`
 	Date      time.Time `json:"date" gorm:"not null"`
 	Count     int       `json:"count" gorm:"default:1"`
+	Value     *float64  `json:"value,omitempty"`
 	CreatedAt time.Time `json:"created_at"`
 }
 
@@ -44,6 +228,7 @@ type Feed struct {
 	GoalID      string     `json:"goal_id" gorm:"not null"`
 	Date        *time.Time `json:"date"`
 	Description string     `json:"description" gorm:"not null"`
+	Hidden      bool       `json:"hidden" gorm:"default:false"`
 }
 
 // comments
@@ -52,9 +237,16 @@ type Comment struct {
 	FeedID    string    `json:"feed_id" gorm:"not null"`
 	UserID    string    `json:"user_id" gorm:"not null"`
 	Content   string    `json:"content" gorm:"not null"`
+	Hidden    bool      `json:"hidden" gorm:"default:false"`
 	CreatedAt time.Time `json:"created_at"`
+
+	// Author is set to DeletedUserName once the author's account is erased
+	Author string `json:"author,omitempty" gorm:"-"`
 }
 
+// DeletedUserName stands in for the author of comments whose account was erased
+const DeletedUserName = "deleted user"
+
 // likes
 type Like struct {
 	ID        string    `json:"id" gorm:"primaryKey;default:gen_random_uuid()"`
@@ -63,6 +255,293 @@ type Like struct {
 	CreatedAt time.Time `json:"created_at"`
 }
 
+// account_deletions
+type AccountDeletion struct {
+	ID                 string     `json:"id" gorm:"primaryKey;default:gen_random_uuid()"`
+	UserID             string     `json:"user_id" gorm:"not null"`
+	Status             string     `json:"status" gorm:"default:'pending'"`
+	RequestedAt        time.Time  `json:"requested_at"`
+	ScheduledFor       time.Time  `json:"scheduled_for"`
+	CancelledAt        *time.Time `json:"cancelled_at"`
+	CompletedAt        *time.Time `json:"completed_at"`
+	GoalsDeleted       int        `json:"goals_deleted"`
+	CompletionsDeleted int        `json:"completions_deleted"`
+	FeedsDeleted       int        `json:"feeds_deleted"`
+	CommentsDeleted    int        `json:"comments_deleted"`
+	CommentsAnonymized int        `json:"comments_anonymized"`
+	LikesDeleted       int        `json:"likes_deleted"`
+	AuthUserDeleted    bool       `json:"auth_user_deleted"`
+}
+
+// Account deletion statuses
+const (
+	DeletionStatusPending   = "pending"
+	DeletionStatusCancelled = "cancelled"
+	DeletionStatusCompleted = "completed"
+)
+
+// blocks
+type Block struct {
+	BlockerID string    `json:"blocker_id" gorm:"primaryKey"`
+	BlockedID string    `json:"blocked_id" gorm:"primaryKey"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// mutes
+type Mute struct {
+	MuterID   string    `json:"muter_id" gorm:"primaryKey"`
+	MutedID   string    `json:"muted_id" gorm:"primaryKey"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// reports
+type Report struct {
+	ID         string     `json:"id" gorm:"primaryKey;default:gen_random_uuid()"`
+	ReporterID string     `json:"reporter_id" gorm:"not null"`
+	TargetType string     `json:"target_type" gorm:"not null"`
+	TargetID   string     `json:"target_id" gorm:"not null"`
+	Reason     string     `json:"reason" gorm:"not null"`
+	Status     string     `json:"status" gorm:"default:'open'"`
+	ResolvedBy *string    `json:"resolved_by"`
+	ResolvedAt *time.Time `json:"resolved_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// Report target types and statuses
+const (
+	ReportTargetFeed    = "feed"
+	ReportTargetComment = "comment"
+
+	ReportStatusOpen     = "open"
+	ReportStatusHidden   = "hidden"
+	ReportStatusRestored = "restored"
+)
+
+// content_flags
+type ContentFlag struct {
+	ID        string    `json:"id" gorm:"primaryKey;default:gen_random_uuid()"`
+	UserID    string    `json:"user_id" gorm:"not null"`
+	Kind      string    `json:"kind" gorm:"not null"`
+	TargetID  *string   `json:"target_id"`
+	Content   string    `json:"content" gorm:"not null"`
+	Reasons   string    `json:"reasons" gorm:"not null"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Kinds of user content screened by the content filter
+const (
+	ContentKindUsername  = "username"
+	ContentKindGoalTitle = "goal_title"
+	ContentKindFeed      = "feed"
+	ContentKindComment   = "comment"
+	ContentKindChallenge = "challenge_post"
+	ContentKindEncourage = "encouragement"
+)
+
+// challenges
+type Challenge struct {
+	ID              string    `json:"id" gorm:"primaryKey;default:gen_random_uuid()"`
+	OwnerID         string    `json:"owner_id" gorm:"not null"`
+	Title           string    `json:"title" gorm:"not null"`
+	Description     string    `json:"description"`
+	StartDate       time.Time `json:"start_date" gorm:"not null"`
+	EndDate         time.Time `json:"end_date" gorm:"not null"`
+	GoalTitle       string    `json:"goal_title" gorm:"not null"`
+	GoalCategory    string    `json:"goal_category" gorm:"not null"`
+	GoalDescription string    `json:"goal_description"`
+	Frequency       string    `json:"frequency" gorm:"default:'daily'"`
+	TargetCount     int       `json:"target_count" gorm:"default:1"`
+	InviteCode      string    `json:"invite_code,omitempty" gorm:"unique;not null"`
+	IsPublic        bool      `json:"is_public" gorm:"default:false"`
+	Participants    int       `json:"participants" gorm:"-"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// challenge_participants
+type ChallengeParticipant struct {
+	ChallengeID string    `json:"challenge_id" gorm:"primaryKey"`
+	UserID      string    `json:"user_id" gorm:"primaryKey"`
+	GoalID      string    `json:"goal_id" gorm:"not null"`
+	Username    string    `json:"username,omitempty" gorm:"-"`
+	JoinedAt    time.Time `json:"joined_at"`
+}
+
+// challenge_posts
+type ChallengePost struct {
+	ID          string    `json:"id" gorm:"primaryKey;default:gen_random_uuid()"`
+	ChallengeID string    `json:"challenge_id" gorm:"not null"`
+	UserID      string    `json:"user_id" gorm:"not null"`
+	Content     string    `json:"content" gorm:"not null"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// ChallengeLeaderboardEntry is computed from the participants' linked goals
+type ChallengeLeaderboardEntry struct {
+	Rank          int    `json:"rank"`
+	UserID        string `json:"user_id"`
+	Username      string `json:"username"`
+	GoalID        string `json:"goal_id"`
+	Completions   int    `json:"completions"`
+	CurrentStreak int    `json:"current_streak"`
+}
+
+// goal_shares
+type GoalShare struct {
+	GoalID    string    `json:"goal_id" gorm:"primaryKey"`
+	UserID    string    `json:"user_id" gorm:"primaryKey"`
+	Username  string    `json:"username,omitempty" gorm:"-"`
+	Role      string    `json:"role" gorm:"not null"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Goal share roles. Viewers can see completions and streaks, partners can
+// also leave encouragement and are notified when the goal is missed.
+const (
+	ShareRoleViewer  = "viewer"
+	ShareRolePartner = "partner"
+)
+
+// encouragements
+type Encouragement struct {
+	ID        string    `json:"id" gorm:"primaryKey;default:gen_random_uuid()"`
+	GoalID    string    `json:"goal_id" gorm:"not null"`
+	UserID    string    `json:"user_id" gorm:"not null"`
+	Message   string    `json:"message" gorm:"not null"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// notifications
+type Notification struct {
+	ID        string     `json:"id" gorm:"primaryKey;default:gen_random_uuid()"`
+	UserID    string     `json:"user_id" gorm:"not null"`
+	Kind      string     `json:"kind" gorm:"not null"`
+	GoalID    *string    `json:"goal_id"`
+	Message   string     `json:"message" gorm:"not null"`
+	RefDate   *time.Time `json:"ref_date"`
+	ReadAt    *time.Time `json:"read_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// Notification kinds
+const (
+	NotificationGoalMissed    = "goal_missed"
+	NotificationEncouragement = "encouragement"
+)
+
+// organizations
+type Organization struct {
+	ID        string    `json:"id" gorm:"primaryKey;default:gen_random_uuid()"`
+	Name      string    `json:"name" gorm:"not null"`
+	OwnerID   string    `json:"owner_id" gorm:"not null"`
+	Role      string    `json:"role,omitempty" gorm:"-"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// organization_members
+type OrganizationMember struct {
+	OrganizationID string    `json:"organization_id" gorm:"primaryKey"`
+	UserID         string    `json:"user_id" gorm:"primaryKey"`
+	Username       string    `json:"username,omitempty" gorm:"-"`
+	Role           string    `json:"role" gorm:"not null"`
+	JoinedAt       time.Time `json:"joined_at"`
+}
+
+// Organization member roles
+const (
+	OrgRoleOwner  = "owner"
+	OrgRoleAdmin  = "admin"
+	OrgRoleMember = "member"
+)
+
+// TeamMemberStats combines the user_stats view with the member's team goals
+// over the dashboard window
+type TeamMemberStats struct {
+	UserID            string  `json:"user_id"`
+	Username          string  `json:"username"`
+	Role              string  `json:"role"`
+	TotalGoals        int     `json:"total_goals"`
+	TotalCompletions  int     `json:"total_completions"`
+	LongestStreak     int     `json:"longest_streak"`
+	TeamGoals         int     `json:"team_goals"`
+	ActiveStreaks     int     `json:"active_streaks"`
+	WindowCompletions int     `json:"window_completions"`
+	CompletionRate    float64 `json:"completion_rate"`
+}
+
+// TeamDashboard aggregates every member's team goals over the last Days days
+type TeamDashboard struct {
+	OrganizationID   string                `json:"organization_id"`
+	Days             int                   `json:"days"`
+	Members          []*TeamMemberStats    `json:"members"`
+	TeamGoals        int                   `json:"team_goals"`
+	ActiveStreaks    int                   `json:"active_streaks"`
+	TotalCompletions int                   `json:"total_completions"`
+	CompletionRate   float64               `json:"completion_rate"`
+	Graph            []CompletionGraphData `json:"graph"`
+}
+
+// user_achievements
+type UserAchievement struct {
+	UserID      string    `json:"user_id" gorm:"primaryKey"`
+	Code        string    `json:"code" gorm:"primaryKey"`
+	Title       string    `json:"title" gorm:"-"`
+	Description string    `json:"description" gorm:"-"`
+	GoalID      *string   `json:"goal_id"`
+	AwardedAt   time.Time `json:"awarded_at"`
+}
+
+// xp_ledger
+type XPEntry struct {
+	ID           string    `json:"id" gorm:"primaryKey;default:gen_random_uuid()"`
+	UserID       string    `json:"user_id" gorm:"not null"`
+	Amount       int       `json:"amount" gorm:"not null"`
+	Reason       string    `json:"reason" gorm:"not null"`
+	Ref          *string   `json:"-" gorm:"unique"`
+	GoalID       *string   `json:"goal_id"`
+	CompletionID *string   `json:"completion_id"`
+	Reverses     *string   `json:"reverses,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// XP ledger reasons
+const (
+	XPReasonCompletion = "completion"
+	XPReasonStreak     = "streak_milestone"
+	XPReasonTarget     = "target_reached"
+	XPReasonFeedPost   = "feed_post"
+	XPReasonComment    = "comment"
+	XPReasonLike       = "like"
+	XPReasonReversal   = "reversal"
+)
+
+// LevelInfo is derived from a user's total XP
+type LevelInfo struct {
+	UserID        string `json:"user_id"`
+	TotalXP       int    `json:"total_xp"`
+	Level         int    `json:"level"`
+	LevelStartXP  int    `json:"level_start_xp"`
+	NextLevelXP   int    `json:"next_level_xp"`
+	XPToNextLevel int    `json:"xp_to_next_level"`
+}
+
+// XPLeaderboardEntry ranks the caller and the users they follow by XP earned this week
+type XPLeaderboardEntry struct {
+	Rank     int    `json:"rank"`
+	UserID   string `json:"user_id"`
+	Username string `json:"username"`
+	WeeklyXP int    `json:"weekly_xp"`
+	TotalXP  int    `json:"total_xp"`
+	Level    int    `json:"level"`
+}
+
+// follows
+type Follow struct {
+	FollowerID string    `json:"follower_id" gorm:"primaryKey"`
+	FolloweeID string    `json:"followee_id" gorm:"primaryKey"`
+	Username   string    `json:"username,omitempty" gorm:"-"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
 // user_stats view
 type UserStats struct {
 	UserID           string `json:"user_id"`
@@ -71,11 +550,216 @@ type UserStats struct {
 	LongestStreak    int    `json:"longest_streak"`
 }
 
+// UserAnalytics summarises the user's active goals between From and To (the
+// last Days days). Rates are fractions of the periods a goal was due in which
+// it was met; consistency scores run from 0 to 100
+type UserAnalytics struct {
+	Days             int                  `json:"days"`
+	From             time.Time            `json:"from"`
+	To               time.Time            `json:"to"`
+	Timezone         string               `json:"timezone"`
+	CompletionRate   float64              `json:"completion_rate"`
+	ConsistencyScore float64              `json:"consistency_score"`
+	Goals            []*GoalAnalytics     `json:"goals"`
+	Categories       []*CategoryAnalytics `json:"categories"`
+	Weeks            []*WeeklyTrend       `json:"weeks"`
+	Weekdays         []*WeekdayStats      `json:"weekdays"`
+	BestWeekday      string               `json:"best_weekday,omitempty"`
+	WorstWeekday     string               `json:"worst_weekday,omitempty"`
+	Hours            []*HourStats         `json:"hours"`
+	GeneratedAt      time.Time            `json:"generated_at"`
+}
+
+// GoalAnalytics is one goal's completion rate over the analytics window
+type GoalAnalytics struct {
+	GoalID           string  `json:"goal_id"`
+	Title            string  `json:"title"`
+	Category         string  `json:"category"`
+	Kind             string  `json:"kind"`
+	Frequency        string  `json:"frequency"`
+	DuePeriods       int     `json:"due_periods"`
+	MetPeriods       int     `json:"met_periods"`
+	CompletionRate   float64 `json:"completion_rate"`
+	ConsistencyScore float64 `json:"consistency_score"`
+}
+
+// CategoryAnalytics adds up the goals of one category
+type CategoryAnalytics struct {
+	CategoryID       *string `json:"category_id"`
+	Name             string  `json:"name"`
+	Goals            int     `json:"goals"`
+	DuePeriods       int     `json:"due_periods"`
+	MetPeriods       int     `json:"met_periods"`
+	CompletionRate   float64 `json:"completion_rate"`
+	ConsistencyScore float64 `json:"consistency_score"`
+}
+
+// WeeklyTrend counts a week's completions; Change is the percentage change
+// from the week before (nil when that week had none)
+type WeeklyTrend struct {
+	WeekStart   time.Time `json:"week_start"`
+	Completions int       `json:"completions"`
+	Change      *float64  `json:"change"`
+}
+
+// WeekdayStats counts completions on one day of the week
+type WeekdayStats struct {
+	Weekday       string  `json:"weekday"`
+	Completions   int     `json:"completions"`
+	AveragePerDay float64 `json:"average_per_day"`
+}
+
+// HourStats counts completions logged in one hour of the day
+type HourStats struct {
+	Hour        int `json:"hour"`
+	Completions int `json:"completions"`
+}
+
+// InsightReport lists the strongest relationships between the user's goals
+// between From and To
+type InsightReport struct {
+	Days     int        `json:"days"`
+	From     time.Time  `json:"from"`
+	To       time.Time  `json:"to"`
+	Insights []*Insight `json:"insights"`
+}
+
+// Insight relates meeting goal A to meeting goal B Lag days later (0 for the
+// same day). Correlation is the phi coefficient over SampleSize days on which
+// both goals existed; RateWithA and RateWithoutA are how often B was met
+// after days A was and was not met
+type Insight struct {
+	GoalAID      string  `json:"goal_a_id"`
+	GoalATitle   string  `json:"goal_a_title"`
+	GoalBID      string  `json:"goal_b_id"`
+	GoalBTitle   string  `json:"goal_b_title"`
+	Lag          int     `json:"lag"`
+	Correlation  float64 `json:"correlation"`
+	SampleSize   int     `json:"sample_size"`
+	BothDays     int     `json:"both_days"`
+	RateWithA    float64 `json:"rate_with_a"`
+	RateWithoutA float64 `json:"rate_without_a"`
+	Summary      string  `json:"summary"`
+}
+
+// CalendarFeed describes the user's iCalendar feed. URL is only set when a
+// token is created, since only its hash is stored
+type CalendarFeed struct {
+	Enabled    bool       `json:"enabled"`
+	URL        string     `json:"url,omitempty"`
+	CreatedAt  *time.Time `json:"created_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+}
+
+// Review periods
+const (
+	ReviewPeriodWeekly  = "weekly"
+	ReviewPeriodMonthly = "monthly"
+)
+
+// reviews; a generated report on one week or month of the user's goals
+type Review struct {
+	ID          string         `json:"id"`
+	UserID      string         `json:"user_id"`
+	Period      string         `json:"period"`
+	PeriodStart time.Time      `json:"period_start"`
+	PeriodEnd   time.Time      `json:"period_end"`
+	Summary     *ReviewSummary `json:"summary"`
+	CreatedAt   time.Time      `json:"created_at"`
+	EmailedAt   *time.Time     `json:"emailed_at,omitempty"`
+}
+
+// ReviewSummary compares the periods the user's goals were scheduled for
+// with those they met. StreaksGained lists goals whose streak grew and
+// StreaksLost those that broke one; NeedsAttention holds goals that met less
+// than half their periods. Insights are the strongest relationships between
+// goals over the weeks up to the review
+type ReviewSummary struct {
+	Scheduled      int           `json:"scheduled"`
+	Completed      int           `json:"completed"`
+	CompletionRate float64       `json:"completion_rate"`
+	Goals          []*ReviewGoal `json:"goals"`
+	StreaksGained  []*ReviewGoal `json:"streaks_gained"`
+	StreaksLost    []*ReviewGoal `json:"streaks_lost"`
+	BestGoals      []*ReviewGoal `json:"best_goals"`
+	NeedsAttention []*ReviewGoal `json:"needs_attention"`
+	Insights       []*Insight    `json:"insights"`
+}
+
+// ReviewGoal is one goal's part of a review. Streaks count met periods in a
+// row at the start and end of the review
+type ReviewGoal struct {
+	GoalID         string  `json:"goal_id"`
+	Title          string  `json:"title"`
+	Kind           string  `json:"kind"`
+	Frequency      string  `json:"frequency"`
+	Scheduled      int     `json:"scheduled"`
+	Completed      int     `json:"completed"`
+	CompletionRate float64 `json:"completion_rate"`
+	StreakStart    int     `json:"streak_start"`
+	StreakEnd      int     `json:"streak_end"`
+}
+
+type GenerateReviewRequest struct {
+	Period string `json:"period" binding:"required,oneof=weekly monthly"`
+}
+
 // CompletionGraphData is not part of the DB schema but may be used for analytics
 type CompletionGraphData struct {
 	Date        time.Time `json:"date"`
 	Completions int       `json:"completions"`
 	Count       int       `json:"count"`
+	Value       *float64  `json:"value,omitempty"`
+}
+
+// ExportManifest describes the contents of an account export archive
+type ExportManifest struct {
+	SchemaVersion int           `json:"schema_version"`
+	UserID        string        `json:"user_id"`
+	GeneratedAt   time.Time     `json:"generated_at"`
+	Files         []*ExportFile `json:"files"`
+}
+
+type ExportFile struct {
+	Name    string `json:"name"`
+	Format  string `json:"format"`
+	Records int    `json:"records"`
+}
+
+// Import formats
+const (
+	ImportFormatGenericCSV = "generic_csv"
+	ImportFormatLoop       = "loop"
+	ImportFormatDoToday    = "dotoday"
+)
+
+// Import actions taken for each goal found in an import file
+const (
+	ImportActionCreate = "create"
+	ImportActionMerge  = "merge"
+	ImportActionSkip   = "skip"
+)
+
+// ImportReport summarises an import run. On a dry run nothing is written and
+// the counts describe what would happen.
+type ImportReport struct {
+	Format              string              `json:"format"`
+	DryRun              bool                `json:"dry_run"`
+	GoalsCreated        int                 `json:"goals_created"`
+	GoalsMerged         int                 `json:"goals_merged"`
+	CompletionsImported int                 `json:"completions_imported"`
+	CompletionsSkipped  int                 `json:"completions_skipped"`
+	Goals               []*ImportGoalReport `json:"goals"`
+	Warnings            []string            `json:"warnings"`
+}
+
+type ImportGoalReport struct {
+	Title       string `json:"title"`
+	GoalID      string `json:"goal_id,omitempty"`
+	Action      string `json:"action"`
+	Completions int    `json:"completions"`
+	Duplicates  int    `json:"duplicates"`
+	Reason      string `json:"reason,omitempty"`
 }
 
 // Request Models
@@ -91,26 +775,154 @@ type LoginRequest struct {
 }
 
 type CreateGoalRequest struct {
-	Title       string    `json:"title" binding:"required"`
-	Category    string    `json:"category" binding:"required"`
-	Description string    `json:"description"`
-	Frequency   string    `json:"frequency"`
-	TargetCount int       `json:"target_count"`
-	Deadline    time.Time `json:"deadline"`
-	IsPublic    bool      `json:"is_public"`
+	Title       string     `json:"title" binding:"required"`
+	Category    string     `json:"category" binding:"required_without=CategoryID"`
+	CategoryID  string     `json:"category_id" binding:"omitempty,uuid"`
+	Description string     `json:"description"`
+	Frequency   string     `json:"frequency" binding:"omitempty,oneof=daily weekly monthly"`
+	TargetCount int        `json:"target_count"`
+	Deadline    *time.Time `json:"deadline"`
+	IsPublic    bool       `json:"is_public"`
+	Tags        []string   `json:"tags" binding:"omitempty,max=20,dive,max=30"`
+
+	// Measurable goals need a unit and a target value; comparison defaults
+	// to at_least and aggregation to sum. Milestone goals need a target value
+	// and a deadline
+	Kind        string   `json:"kind" binding:"omitempty,oneof=habit measurable quit milestone"`
+	Unit        string   `json:"unit" binding:"omitempty,max=20"`
+	TargetValue *float64 `json:"target_value"`
+	Comparison  string   `json:"comparison" binding:"omitempty,oneof=at_least at_most exactly"`
+	Aggregation string   `json:"aggregation" binding:"omitempty,oneof=sum max avg"`
 }
 
 type UpdateGoalRequest struct {
 	Title       *string    `json:"title,omitempty"`
+	Category    *string    `json:"category,omitempty"`
+	CategoryID  *string    `json:"category_id,omitempty" binding:"omitempty,uuid"`
 	Description *string    `json:"description,omitempty"`
+	Frequency   *string    `json:"frequency,omitempty" binding:"omitempty,oneof=daily weekly monthly"`
+	TargetCount *int       `json:"target_count,omitempty" binding:"omitempty,min=1"`
 	Deadline    *time.Time `json:"deadline,omitempty"`
 	IsPublic    *bool      `json:"is_public,omitempty"`
 	Archived    *bool      `json:"archived,omitempty"`
+	Tags        *[]string  `json:"tags,omitempty" binding:"omitempty,max=20,dive,max=30"`
+	Unit        *string    `json:"unit,omitempty" binding:"omitempty,min=1,max=20"`
+	TargetValue *float64   `json:"target_value,omitempty"`
+	Comparison  *string    `json:"comparison,omitempty" binding:"omitempty,oneof=at_least at_most exactly"`
+	Aggregation *string    `json:"aggregation,omitempty" binding:"omitempty,oneof=sum max avg"`
+
+	// EffectiveFrom (YYYY-MM-DD) dates a frequency or target change; it
+	// defaults to today and may be backdated to the goal's creation
+	EffectiveFrom *string `json:"effective_from,omitempty" binding:"omitempty,datetime=2006-01-02"`
+}
+
+// RecordSlipRequest logs a slip on a quit goal; Date (YYYY-MM-DD) defaults
+// to today
+type RecordSlipRequest struct {
+	Date *string `json:"date" binding:"omitempty,datetime=2006-01-02"`
+}
+
+type RecordMeasurementRequest struct {
+	Value *float64 `json:"value" binding:"required"`
+}
+
+type CreateChecklistItemRequest struct {
+	Title    string `json:"title" binding:"required,max=200"`
+	Required *bool  `json:"required"`
+}
+
+type UpdateChecklistItemRequest struct {
+	Title    *string `json:"title,omitempty" binding:"omitempty,min=1,max=200"`
+	Required *bool   `json:"required,omitempty"`
+}
+
+// ReorderChecklistRequest lists every item of the checklist in its new order
+type ReorderChecklistRequest struct {
+	ItemIDs []string `json:"item_ids" binding:"required,dive,uuid"`
 }
 
 type UpdateProfileRequest struct {
-	Username    *string `json:"username,omitempty"`
-	NewPassword *string `json:"new_password,omitempty"`
+	Username          *string `json:"username,omitempty"`
+	NewPassword       *string `json:"new_password,omitempty"`
+	ShareAchievements *bool   `json:"share_achievements,omitempty"`
+	ReviewEmails      *bool   `json:"review_emails,omitempty"`
+}
+
+type CreateReportRequest struct {
+	TargetType string `json:"target_type" binding:"required,oneof=feed comment"`
+	TargetID   string `json:"target_id" binding:"required,uuid"`
+	Reason     string `json:"reason" binding:"required"`
+}
+
+type CreateChallengeRequest struct {
+	Title           string    `json:"title" binding:"required"`
+	Description     string    `json:"description"`
+	StartDate       time.Time `json:"start_date" binding:"required"`
+	EndDate         time.Time `json:"end_date" binding:"required"`
+	GoalTitle       string    `json:"goal_title" binding:"required"`
+	GoalCategory    string    `json:"goal_category" binding:"required"`
+	GoalDescription string    `json:"goal_description"`
+	Frequency       string    `json:"frequency"`
+	TargetCount     int       `json:"target_count"`
+	IsPublic        bool      `json:"is_public"`
+}
+
+type JoinChallengeRequest struct {
+	InviteCode string `json:"invite_code" binding:"required"`
+}
+
+type CreateChallengePostRequest struct {
+	Content string `json:"content" binding:"required"`
+}
+
+type ShareGoalRequest struct {
+	Username string `json:"username" binding:"required"`
+	Role     string `json:"role" binding:"required,oneof=viewer partner"`
+}
+
+type EncouragementRequest struct {
+	Message string `json:"message" binding:"required"`
+}
+
+type CreateOrganizationRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+type AddMemberRequest struct {
+	Username string `json:"username" binding:"required"`
+	Role     string `json:"role" binding:"omitempty,oneof=admin member"`
+}
+
+type UpdateMemberRoleRequest struct {
+	Role string `json:"role" binding:"required,oneof=admin member"`
+}
+
+type ShareGoalWithTeamRequest struct {
+	GoalID string `json:"goal_id" binding:"required,uuid"`
+}
+
+// ImportRequest is bound from the multipart form alongside the uploaded file.
+// The column and date format fields only apply to generic CSV files.
+type ImportRequest struct {
+	Format         string `form:"format" binding:"required,oneof=generic_csv loop dotoday"`
+	DryRun         bool   `form:"dry_run"`
+	TitleColumn    string `form:"title_column"`
+	DateColumn     string `form:"date_column"`
+	CountColumn    string `form:"count_column"`
+	CategoryColumn string `form:"category_column"`
+	DateFormat     string `form:"date_format"`
+}
+
+type CreateCategoryRequest struct {
+	Name  string `json:"name" binding:"required,max=50"`
+	Color string `json:"color" binding:"omitempty,hexcolor"`
+	Icon  string `json:"icon" binding:"omitempty,max=50"`
+}
+
+type UpdateCategoryRequest struct {
+	Name  *string `json:"name,omitempty" binding:"omitempty,min=1,max=50"`
+	Color *string `json:"color,omitempty" binding:"omitempty,hexcolor"`
+	Icon  *string `json:"icon,omitempty" binding:"omitempty,max=50"`
 }
 
 // Response Models
@@ -120,7 +932,19 @@ type AuthResponse struct {
 }
 
 type StreakResponse struct {
-	CurrentStreak int `json:"current_streak"`
-	LongestStreak int `json:"longest_streak"`
-	Completions   int `json:"completions"`
+	CurrentStreak int        `json:"current_streak"`
+	LongestStreak int        `json:"longest_streak"`
+	Completions   int        `json:"completions"`
+	Quit          *QuitStats `json:"quit,omitempty"`
+}
+
+// QuitStats describes a quit goal's slips. Clean runs count days without a
+// slip, starting from the day the goal was created
+type QuitStats struct {
+	DaysSinceSlip   int        `json:"days_since_slip"`
+	LongestCleanRun int        `json:"longest_clean_run"`
+	Slips           int        `json:"slips"`
+	SlipDays        int        `json:"slip_days"`
+	SlipsPerWeek    float64    `json:"slips_per_week"`
+	LastSlip        *time.Time `json:"last_slip"`
 }
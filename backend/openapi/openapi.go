@@ -0,0 +1,9 @@
+// Package openapi embeds the OpenAPI 3 description of the HTTP API. The
+// document is maintained by hand next to the routes in main.go; the contract
+// tests fail when either drifts from the other.
+package openapi
+
+import _ "embed"
+
+//go:embed openapi.json
+var Spec []byte
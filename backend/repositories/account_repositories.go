@@ -0,0 +1,209 @@
+package repositories
+
+import (
+	"DoToday/models"
+	"database/sql"
+	"time"
+)
+
+type AccountRepository struct {
+	db *sql.DB
+}
+
+func NewAccountRepository(db *sql.DB) *AccountRepository {
+	return &AccountRepository{db: db}
+}
+
+const accountDeletionColumns = `
+	id, user_id, status, requested_at, scheduled_for, cancelled_at, completed_at,
+	goals_deleted, completions_deleted, feeds_deleted, comments_deleted, comments_anonymized,
+	likes_deleted, auth_user_deleted
+`
+
+func scanAccountDeletion(row interface{ Scan(...interface{}) error }) (*models.AccountDeletion, error) {
+	d := &models.AccountDeletion{}
+	err := row.Scan(
+		&d.ID, &d.UserID, &d.Status, &d.RequestedAt, &d.ScheduledFor, &d.CancelledAt, &d.CompletedAt,
+		&d.GoalsDeleted, &d.CompletionsDeleted, &d.FeedsDeleted, &d.CommentsDeleted, &d.CommentsAnonymized,
+		&d.LikesDeleted, &d.AuthUserDeleted,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func (r *AccountRepository) CreateDeletion(d *models.AccountDeletion) error {
+	query := `
+		INSERT INTO account_deletions (id, user_id, status, requested_at, scheduled_for)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+	_, err := r.db.Exec(query, d.ID, d.UserID, d.Status, d.RequestedAt, d.ScheduledFor)
+	return err
+}
+
+// GetPendingDeletion returns the user's pending request, or sql.ErrNoRows.
+func (r *AccountRepository) GetPendingDeletion(userID string) (*models.AccountDeletion, error) {
+	query := `SELECT ` + accountDeletionColumns + ` FROM account_deletions WHERE user_id = $1 AND status = $2`
+	return scanAccountDeletion(r.db.QueryRow(query, userID, models.DeletionStatusPending))
+}
+
+func (r *AccountRepository) CancelDeletion(id string) error {
+	query := `UPDATE account_deletions SET status = $1, cancelled_at = $2 WHERE id = $3 AND status = $4`
+	_, err := r.db.Exec(query, models.DeletionStatusCancelled, time.Now(), id, models.DeletionStatusPending)
+	return err
+}
+
+// GetDueDeletions returns pending requests whose grace period ended before now.
+func (r *AccountRepository) GetDueDeletions(now time.Time) ([]*models.AccountDeletion, error) {
+	query := `
+		SELECT ` + accountDeletionColumns + `
+		FROM account_deletions
+		WHERE status = $1 AND scheduled_for <= $2
+		ORDER BY scheduled_for ASC
+	`
+	rows, err := r.db.Query(query, models.DeletionStatusPending, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deletions := []*models.AccountDeletion{}
+	for rows.Next() {
+		d, err := scanAccountDeletion(rows)
+		if err != nil {
+			return nil, err
+		}
+		deletions = append(deletions, d)
+	}
+	return deletions, nil
+}
+
+// EraseUser deletes everything the user owns in one transaction and records
+// the counts on the deletion request, marking it completed. Comments the user
+// left on other people's posts are kept without an author, and teams and
+// challenges other people belong to are handed over first (see
+// transferOwnership); everything else tied to the profile goes with it.
+func (r *AccountRepository) EraseUser(d *models.AccountDeletion) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	counts, err := deleteGoalData(tx, `SELECT id FROM goals WHERE user_id = $1`, d.UserID)
+	if err != nil {
+		return err
+	}
+	d.CompletionsDeleted = counts.Completions
+	d.FeedsDeleted = counts.Feeds
+	d.CommentsDeleted = counts.Comments
+	d.LikesDeleted = counts.Likes
+
+	res, err := tx.Exec(`UPDATE comments SET user_id = NULL WHERE user_id = $1`, d.UserID)
+	if err != nil {
+		return err
+	}
+	if d.CommentsAnonymized, err = rowsAffected(res); err != nil {
+		return err
+	}
+
+	res, err = tx.Exec(`DELETE FROM likes WHERE user_id = $1`, d.UserID)
+	if err != nil {
+		return err
+	}
+	n, err := rowsAffected(res)
+	if err != nil {
+		return err
+	}
+	d.LikesDeleted += n
+
+	res, err = tx.Exec(`DELETE FROM goals WHERE user_id = $1`, d.UserID)
+	if err != nil {
+		return err
+	}
+	if d.GoalsDeleted, err = rowsAffected(res); err != nil {
+		return err
+	}
+
+	if err := transferOwnership(tx, d.UserID); err != nil {
+		return err
+	}
+
+	// Tables added by our migrations cascade from profiles.
+	if _, err := tx.Exec(`DELETE FROM profiles WHERE id = $1`, d.UserID); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	d.Status = models.DeletionStatusCompleted
+	d.CompletedAt = &now
+	_, err = tx.Exec(`
+		UPDATE account_deletions
+		SET status = $1, completed_at = $2, goals_deleted = $3, completions_deleted = $4, feeds_deleted = $5,
+		    comments_deleted = $6, comments_anonymized = $7, likes_deleted = $8
+		WHERE id = $9
+	`, d.Status, d.CompletedAt, d.GoalsDeleted, d.CompletionsDeleted, d.FeedsDeleted,
+		d.CommentsDeleted, d.CommentsAnonymized, d.LikesDeleted, d.ID)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// transferOwnership hands the user's teams and challenges to whoever has been
+// in them longest, team admins before members, so erasing the owner does not
+// cascade through everyone else's membership, shared goals and posts. Teams
+// and challenges nobody else is in are left to go with the profile.
+func transferOwnership(tx *sql.Tx, userID string) error {
+	_, err := tx.Exec(`
+		UPDATE organizations o
+		SET owner_id = heir.user_id
+		FROM (
+			SELECT DISTINCT ON (m.organization_id) m.organization_id, m.user_id
+			FROM organization_members m
+			JOIN organizations owned ON owned.id = m.organization_id
+			WHERE owned.owner_id = $1 AND m.user_id <> $1
+			ORDER BY m.organization_id, (m.role = 'admin') DESC, m.joined_at ASC
+		) heir
+		WHERE o.id = heir.organization_id
+	`, userID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		UPDATE organization_members m
+		SET role = 'owner'
+		FROM organizations o
+		WHERE o.id = m.organization_id AND o.owner_id = m.user_id AND m.role <> 'owner'
+		  AND m.organization_id IN (SELECT organization_id FROM organization_members WHERE user_id = $1)
+	`, userID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		UPDATE challenges c
+		SET owner_id = heir.user_id
+		FROM (
+			SELECT DISTINCT ON (p.challenge_id) p.challenge_id, p.user_id
+			FROM challenge_participants p
+			JOIN challenges owned ON owned.id = p.challenge_id
+			WHERE owned.owner_id = $1 AND p.user_id <> $1
+			ORDER BY p.challenge_id, p.joined_at ASC
+		) heir
+		WHERE c.id = heir.challenge_id
+	`, userID)
+	return err
+}
+
+func (r *AccountRepository) SetAuthUserDeleted(id string) error {
+	_, err := r.db.Exec(`UPDATE account_deletions SET auth_user_deleted = true WHERE id = $1`, id)
+	return err
+}
+
+func rowsAffected(res sql.Result) (int, error) {
+	n, err := res.RowsAffected()
+	return int(n), err
+}
@@ -0,0 +1,83 @@
+package repositories
+
+import (
+	"DoToday/models"
+	"database/sql"
+	"time"
+)
+
+type AchievementRepository struct {
+	db *sql.DB
+}
+
+func NewAchievementRepository(db *sql.DB) *AchievementRepository {
+	return &AchievementRepository{db: db}
+}
+
+// Award records an achievement and reports whether it is new. Achievements
+// are awarded at most once per user.
+func (r *AchievementRepository) Award(achievement *models.UserAchievement) (bool, error) {
+	query := `
+		INSERT INTO user_achievements (user_id, code, goal_id, awarded_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id, code) DO NOTHING
+	`
+	result, err := r.db.Exec(query, achievement.UserID, achievement.Code, achievement.GoalID, achievement.AwardedAt)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+func (r *AchievementRepository) GetByUserID(userID string) ([]*models.UserAchievement, error) {
+	query := `
+		SELECT user_id, code, goal_id, awarded_at
+		FROM user_achievements
+		WHERE user_id = $1
+		ORDER BY awarded_at ASC
+	`
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	achievements := []*models.UserAchievement{}
+	for rows.Next() {
+		a := &models.UserAchievement{}
+		if err := rows.Scan(&a.UserID, &a.Code, &a.GoalID, &a.AwardedAt); err != nil {
+			return nil, err
+		}
+		achievements = append(achievements, a)
+	}
+	return achievements, nil
+}
+
+// UserActivity summarizes what the achievement rules need to know about a user.
+type UserActivity struct {
+	TotalCompletions   int
+	PublicGoals        int
+	PreviousCompletion *time.Time
+}
+
+// GetUserActivity counts the user's completions and public goals and finds
+// their most recent completion before the given day.
+func (r *AchievementRepository) GetUserActivity(userID string, day time.Time) (*UserActivity, error) {
+	activity := &UserActivity{}
+	query := `
+		SELECT
+			(SELECT COUNT(*) FROM completions c JOIN goals g ON g.id = c.goal_id
+			 WHERE g.user_id = $1 AND g.deleted_at IS NULL AND g.kind <> 'quit'),
+			(SELECT COUNT(*) FROM goals WHERE user_id = $1 AND is_public = true AND deleted_at IS NULL),
+			(SELECT MAX(c.date) FROM completions c JOIN goals g ON g.id = c.goal_id
+			 WHERE g.user_id = $1 AND g.deleted_at IS NULL AND g.kind <> 'quit' AND c.date < $2::date)
+	`
+	err := r.db.QueryRow(query, userID, day.Format("2006-01-02")).Scan(
+		&activity.TotalCompletions, &activity.PublicGoals, &activity.PreviousCompletion,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return activity, nil
+}
@@ -0,0 +1,128 @@
+package repositories
+
+import (
+	"DoToday/models"
+	"database/sql"
+	"time"
+)
+
+type AnalyticsRepository struct {
+	db *sql.DB
+}
+
+func NewAnalyticsRepository(db *sql.DB) *AnalyticsRepository {
+	return &AnalyticsRepository{db: db}
+}
+
+// GetWeeklyTrend counts the completions of the user's goals per week, for
+// every week overlapping from..to, with the change from the week before.
+// Slips of quit goals are not completions.
+func (r *AnalyticsRepository) GetWeeklyTrend(userID string, from, to time.Time) ([]*models.WeeklyTrend, error) {
+	query := `
+		WITH weeks AS (
+			SELECT w.week::date AS week, COUNT(c.id) AS completions
+			FROM generate_series(
+				date_trunc('week', $2::date::timestamp) - interval '1 week', $3::date::timestamp, interval '1 week'
+			) AS w(week)
+			LEFT JOIN (
+				completions c
+				JOIN goals g ON g.id = c.goal_id AND g.user_id = $1 AND g.kind <> 'quit' AND g.deleted_at IS NULL
+			) ON c.date >= w.week::date AND c.date < (w.week + interval '1 week')::date AND c.date <= $3::date
+			GROUP BY w.week
+		),
+		trend AS (
+			SELECT week, completions, LAG(completions) OVER (ORDER BY week) AS previous
+			FROM weeks
+		)
+		SELECT week, completions,
+		       CASE WHEN previous > 0 THEN (completions - previous)::float / previous * 100 END
+		FROM trend
+		WHERE week >= date_trunc('week', $2::date::timestamp)::date
+		ORDER BY week ASC
+	`
+	rows, err := r.db.Query(query, userID, from.Format("2006-01-02"), to.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	weeks := []*models.WeeklyTrend{}
+	for rows.Next() {
+		w := &models.WeeklyTrend{}
+		if err := rows.Scan(&w.WeekStart, &w.Completions, &w.Change); err != nil {
+			return nil, err
+		}
+		weeks = append(weeks, w)
+	}
+	return weeks, nil
+}
+
+// GetWeekdays counts the user's completions between from and to per day of
+// the week, Monday first, with the average per occurrence of that day.
+func (r *AnalyticsRepository) GetWeekdays(userID string, from, to time.Time) ([]*models.WeekdayStats, error) {
+	query := `
+		WITH days AS (
+			SELECT EXTRACT(ISODOW FROM d)::int AS dow, COUNT(*) AS occurrences
+			FROM generate_series($2::date::timestamp, $3::date::timestamp, interval '1 day') AS d
+			GROUP BY 1
+		),
+		done AS (
+			SELECT EXTRACT(ISODOW FROM c.date)::int AS dow, COUNT(*) AS completions
+			FROM completions c
+			JOIN goals g ON g.id = c.goal_id
+			WHERE g.user_id = $1 AND g.kind <> 'quit' AND g.deleted_at IS NULL AND c.date BETWEEN $2 AND $3
+			GROUP BY 1
+		)
+		SELECT trim(to_char(date '2024-01-01' + (n - 1), 'Day')), COALESCE(done.completions, 0),
+		       COALESCE(done.completions, 0)::float / GREATEST(COALESCE(days.occurrences, 0), 1)
+		FROM generate_series(1, 7) AS n
+		LEFT JOIN days ON days.dow = n
+		LEFT JOIN done ON done.dow = n
+		ORDER BY n ASC
+	`
+	rows, err := r.db.Query(query, userID, from.Format("2006-01-02"), to.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	weekdays := []*models.WeekdayStats{}
+	for rows.Next() {
+		w := &models.WeekdayStats{}
+		if err := rows.Scan(&w.Weekday, &w.Completions, &w.AveragePerDay); err != nil {
+			return nil, err
+		}
+		weekdays = append(weekdays, w)
+	}
+	return weekdays, nil
+}
+
+// GetHours counts the user's completions between from and to by the hour of
+// the day they were logged at in timezone.
+func (r *AnalyticsRepository) GetHours(userID string, from, to time.Time, timezone string) ([]*models.HourStats, error) {
+	query := `
+		SELECT h, COUNT(c.id)
+		FROM generate_series(0, 23) AS h
+		LEFT JOIN (
+			completions c
+			JOIN goals g ON g.id = c.goal_id AND g.user_id = $1 AND g.kind <> 'quit' AND g.deleted_at IS NULL
+		) ON c.date BETWEEN $2 AND $3 AND EXTRACT(HOUR FROM c.created_at AT TIME ZONE $4)::int = h
+		GROUP BY h
+		ORDER BY h ASC
+	`
+	rows, err := r.db.Query(query, userID, from.Format("2006-01-02"), to.Format("2006-01-02"), timezone)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	hours := []*models.HourStats{}
+	for rows.Next() {
+		h := &models.HourStats{}
+		if err := rows.Scan(&h.Hour, &h.Completions); err != nil {
+			return nil, err
+		}
+		hours = append(hours, h)
+	}
+	return hours, nil
+}
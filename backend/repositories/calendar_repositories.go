@@ -0,0 +1,51 @@
+package repositories
+
+import (
+	"DoToday/models"
+	"database/sql"
+	"time"
+)
+
+type CalendarRepository struct {
+	db *sql.DB
+}
+
+func NewCalendarRepository(db *sql.DB) *CalendarRepository {
+	return &CalendarRepository{db: db}
+}
+
+// SaveToken sets the user's feed token hash, replacing any earlier token.
+func (r *CalendarRepository) SaveToken(userID, tokenHash string, at time.Time) error {
+	query := `
+		INSERT INTO calendar_feeds (user_id, token_hash, created_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id) DO UPDATE SET token_hash = $2, created_at = $3, last_used_at = NULL
+	`
+	_, err := r.db.Exec(query, userID, tokenHash, at)
+	return err
+}
+
+// GetByUserID returns the user's feed, or sql.ErrNoRows when they have none.
+func (r *CalendarRepository) GetByUserID(userID string) (*models.CalendarFeed, error) {
+	feed := &models.CalendarFeed{}
+	query := `SELECT created_at, last_used_at FROM calendar_feeds WHERE user_id = $1`
+	err := r.db.QueryRow(query, userID).Scan(&feed.CreatedAt, &feed.LastUsedAt)
+	if err != nil {
+		return nil, err
+	}
+	feed.Enabled = true
+	return feed, nil
+}
+
+// UseToken returns the owner of the token hash and records the access.
+func (r *CalendarRepository) UseToken(tokenHash string, at time.Time) (string, error) {
+	var userID string
+	query := `UPDATE calendar_feeds SET last_used_at = $2 WHERE token_hash = $1 RETURNING user_id`
+	err := r.db.QueryRow(query, tokenHash, at).Scan(&userID)
+	return userID, err
+}
+
+func (r *CalendarRepository) DeleteToken(userID string) error {
+	_, err := r.db.Exec(`DELETE FROM calendar_feeds WHERE user_id = $1`, userID)
+	return err
+}
@@ -0,0 +1,164 @@
+package repositories
+
+import (
+	"DoToday/models"
+	"database/sql"
+)
+
+type CategoryRepository struct {
+	db *sql.DB
+}
+
+func NewCategoryRepository(db *sql.DB) *CategoryRepository {
+	return &CategoryRepository{db: db}
+}
+
+const categoryColumns = `c.id, c.user_id, c.name, c.color, c.icon, c.created_at`
+
+func scanCategory(row interface{ Scan(...interface{}) error }) (*models.Category, error) {
+	category := &models.Category{}
+	err := row.Scan(&category.ID, &category.UserID, &category.Name, &category.Color, &category.Icon, &category.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	category.IsSystem = category.UserID == nil
+	return category, nil
+}
+
+func (r *CategoryRepository) Create(category *models.Category) error {
+	query := `
+		INSERT INTO categories (id, user_id, name, color, icon, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+	_, err := r.db.Exec(query,
+		category.ID, category.UserID, category.Name, category.Color, category.Icon, category.CreatedAt,
+	)
+	return err
+}
+
+func (r *CategoryRepository) GetByID(id string) (*models.Category, error) {
+	query := `SELECT ` + categoryColumns + ` FROM categories c WHERE c.id = $1`
+	return scanCategory(r.db.QueryRow(query, id))
+}
+
+// GetByName finds a system or user category by name, ignoring case and
+// surrounding space. System categories win over user ones.
+func (r *CategoryRepository) GetByName(userID, name string) (*models.Category, error) {
+	query := `
+		SELECT ` + categoryColumns + `
+		FROM categories c
+		WHERE (c.user_id IS NULL OR c.user_id = $1) AND lower(c.name) = lower(trim($2))
+		ORDER BY c.user_id NULLS FIRST
+		LIMIT 1
+	`
+	return scanCategory(r.db.QueryRow(query, userID, name))
+}
+
+// GetForUser returns the system categories followed by the user's own.
+func (r *CategoryRepository) GetForUser(userID string) ([]*models.Category, error) {
+	query := `
+		SELECT ` + categoryColumns + `
+		FROM categories c
+		WHERE c.user_id IS NULL OR c.user_id = $1
+		ORDER BY c.user_id NULLS FIRST, lower(c.name) ASC
+	`
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	categories := []*models.Category{}
+	for rows.Next() {
+		category, err := scanCategory(rows)
+		if err != nil {
+			return nil, err
+		}
+		categories = append(categories, category)
+	}
+	return categories, nil
+}
+
+// Update saves the category and renames it on the goals linked to it.
+func (r *CategoryRepository) Update(category *models.Category) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`UPDATE categories SET name = $1, color = $2, icon = $3 WHERE id = $4`,
+		category.Name, category.Color, category.Icon, category.ID)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`UPDATE goals SET category = $1 WHERE category_id = $2`, category.Name, category.ID)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Delete removes the category and moves its goals to the fallback category.
+func (r *CategoryRepository) Delete(category, fallback *models.Category) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`UPDATE goals SET category_id = $1, category = $2 WHERE category_id = $3`,
+		fallback.ID, fallback.Name, category.ID)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM categories WHERE id = $1`, category.ID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetStats summarises the user's goals per category over their non-trashed
+// goals. RecentCompletions covers the last days days. An empty categoryID
+// returns every category visible to the user.
+func (r *CategoryRepository) GetStats(userID, categoryID string, days int) ([]*models.CategoryStats, error) {
+	query := `
+		SELECT c.id, c.name, c.color, c.icon,
+		       COUNT(DISTINCT g.id) FILTER (WHERE g.archived = false),
+		       COUNT(DISTINCT g.id) FILTER (WHERE g.archived = true),
+		       COALESCE(SUM(gc.total), 0),
+		       COALESCE(SUM(gc.recent), 0),
+		       COALESCE(MAX(g.current_streak), 0),
+		       COALESCE(AVG(g.current_streak), 0)
+		FROM categories c
+		LEFT JOIN goals g ON g.category_id = c.id AND g.user_id = $1 AND g.deleted_at IS NULL
+		LEFT JOIN LATERAL (
+			SELECT COUNT(*) AS total, COUNT(*) FILTER (WHERE co.date > CURRENT_DATE - $3::int) AS recent
+			FROM completions co
+			WHERE co.goal_id = g.id
+		) gc ON true
+		WHERE (c.user_id IS NULL OR c.user_id = $1)
+		  AND ($2::uuid IS NULL OR c.id = $2)
+		GROUP BY c.id, c.name, c.color, c.icon, c.user_id
+		ORDER BY c.user_id NULLS FIRST, lower(c.name) ASC
+	`
+	rows, err := r.db.Query(query, userID, nullableID(categoryID), days)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := []*models.CategoryStats{}
+	for rows.Next() {
+		s := &models.CategoryStats{}
+		err := rows.Scan(
+			&s.CategoryID, &s.Name, &s.Color, &s.Icon, &s.ActiveGoals, &s.ArchivedGoals,
+			&s.Completions, &s.RecentCompletions, &s.BestStreak, &s.AverageStreak,
+		)
+		if err != nil {
+			return nil, err
+		}
+		stats = append(stats, s)
+	}
+	return stats, nil
+}
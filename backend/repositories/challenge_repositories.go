@@ -0,0 +1,180 @@
+package repositories
+
+import (
+	"DoToday/models"
+	"database/sql"
+)
+
+type ChallengeRepository struct {
+	db *sql.DB
+}
+
+func NewChallengeRepository(db *sql.DB) *ChallengeRepository {
+	return &ChallengeRepository{db: db}
+}
+
+const challengeColumns = `
+	c.id, c.owner_id, c.title, c.description, c.start_date, c.end_date, c.goal_title, c.goal_category,
+	c.goal_description, c.frequency, c.target_count, c.invite_code, c.is_public, c.created_at,
+	(SELECT COUNT(*) FROM challenge_participants cp WHERE cp.challenge_id = c.id)
+`
+
+func scanChallenge(row interface{ Scan(...interface{}) error }) (*models.Challenge, error) {
+	challenge := &models.Challenge{}
+	err := row.Scan(
+		&challenge.ID, &challenge.OwnerID, &challenge.Title, &challenge.Description, &challenge.StartDate,
+		&challenge.EndDate, &challenge.GoalTitle, &challenge.GoalCategory, &challenge.GoalDescription,
+		&challenge.Frequency, &challenge.TargetCount, &challenge.InviteCode, &challenge.IsPublic,
+		&challenge.CreatedAt, &challenge.Participants,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return challenge, nil
+}
+
+func (r *ChallengeRepository) Create(challenge *models.Challenge) error {
+	query := `
+		INSERT INTO challenges (id, owner_id, title, description, start_date, end_date, goal_title, goal_category,
+		                        goal_description, frequency, target_count, invite_code, is_public, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+	`
+	_, err := r.db.Exec(query,
+		challenge.ID, challenge.OwnerID, challenge.Title, challenge.Description, challenge.StartDate,
+		challenge.EndDate, challenge.GoalTitle, challenge.GoalCategory, challenge.GoalDescription,
+		challenge.Frequency, challenge.TargetCount, challenge.InviteCode, challenge.IsPublic, challenge.CreatedAt,
+	)
+	return err
+}
+
+func (r *ChallengeRepository) GetByID(id string) (*models.Challenge, error) {
+	query := `SELECT ` + challengeColumns + ` FROM challenges c WHERE c.id = $1`
+	return scanChallenge(r.db.QueryRow(query, id))
+}
+
+func (r *ChallengeRepository) GetByInviteCode(code string) (*models.Challenge, error) {
+	query := `SELECT ` + challengeColumns + ` FROM challenges c WHERE c.invite_code = $1`
+	return scanChallenge(r.db.QueryRow(query, code))
+}
+
+// GetByUserID returns the challenges the user takes part in.
+func (r *ChallengeRepository) GetByUserID(userID string) ([]*models.Challenge, error) {
+	query := `
+		SELECT ` + challengeColumns + `
+		FROM challenges c
+		JOIN challenge_participants p ON p.challenge_id = c.id
+		WHERE p.user_id = $1
+		ORDER BY c.start_date DESC
+	`
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	challenges := []*models.Challenge{}
+	for rows.Next() {
+		challenge, err := scanChallenge(rows)
+		if err != nil {
+			return nil, err
+		}
+		challenges = append(challenges, challenge)
+	}
+	return challenges, nil
+}
+
+func (r *ChallengeRepository) AddParticipant(participant *models.ChallengeParticipant) error {
+	query := `
+		INSERT INTO challenge_participants (challenge_id, user_id, goal_id, joined_at)
+		VALUES ($1, $2, $3, $4)
+	`
+	_, err := r.db.Exec(query, participant.ChallengeID, participant.UserID, participant.GoalID, participant.JoinedAt)
+	return err
+}
+
+func (r *ChallengeRepository) RemoveParticipant(challengeID, userID string) error {
+	query := `DELETE FROM challenge_participants WHERE challenge_id = $1 AND user_id = $2`
+	_, err := r.db.Exec(query, challengeID, userID)
+	return err
+}
+
+func (r *ChallengeRepository) GetParticipant(challengeID, userID string) (*models.ChallengeParticipant, error) {
+	participant := &models.ChallengeParticipant{}
+	query := `
+		SELECT cp.challenge_id, cp.user_id, cp.goal_id, p.username, cp.joined_at
+		FROM challenge_participants cp
+		JOIN profiles p ON p.id = cp.user_id
+		WHERE cp.challenge_id = $1 AND cp.user_id = $2
+	`
+	err := r.db.QueryRow(query, challengeID, userID).Scan(
+		&participant.ChallengeID, &participant.UserID, &participant.GoalID, &participant.Username, &participant.JoinedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return participant, nil
+}
+
+func (r *ChallengeRepository) GetParticipants(challengeID string) ([]*models.ChallengeParticipant, error) {
+	query := `
+		SELECT cp.challenge_id, cp.user_id, cp.goal_id, p.username, cp.joined_at
+		FROM challenge_participants cp
+		JOIN profiles p ON p.id = cp.user_id
+		WHERE cp.challenge_id = $1
+		ORDER BY cp.joined_at ASC
+	`
+	rows, err := r.db.Query(query, challengeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	participants := []*models.ChallengeParticipant{}
+	for rows.Next() {
+		participant := &models.ChallengeParticipant{}
+		err := rows.Scan(
+			&participant.ChallengeID, &participant.UserID, &participant.GoalID, &participant.Username, &participant.JoinedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		participants = append(participants, participant)
+	}
+	return participants, nil
+}
+
+func (r *ChallengeRepository) CreatePost(post *models.ChallengePost) error {
+	query := `
+		INSERT INTO challenge_posts (id, challenge_id, user_id, content, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+	_, err := r.db.Exec(query, post.ID, post.ChallengeID, post.UserID, post.Content, post.CreatedAt)
+	return err
+}
+
+// GetPosts returns a challenge's posts, newest first, without posts from
+// users the viewer has blocked or muted.
+func (r *ChallengeRepository) GetPosts(challengeID, viewerID string) ([]*models.ChallengePost, error) {
+	query := `
+		SELECT cp.id, cp.challenge_id, cp.user_id, cp.content, cp.created_at
+		FROM challenge_posts cp
+		WHERE cp.challenge_id = $1
+		  AND ` + visibleAuthorClause("cp.user_id", "$2") + `
+		ORDER BY cp.created_at DESC
+	`
+	rows, err := r.db.Query(query, challengeID, nullableID(viewerID))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	posts := []*models.ChallengePost{}
+	for rows.Next() {
+		post := &models.ChallengePost{}
+		if err := rows.Scan(&post.ID, &post.ChallengeID, &post.UserID, &post.Content, &post.CreatedAt); err != nil {
+			return nil, err
+		}
+		posts = append(posts, post)
+	}
+	return posts, nil
+}
@@ -0,0 +1,140 @@
+package repositories
+
+import (
+	"DoToday/models"
+	"database/sql"
+	"time"
+)
+
+type ChecklistRepository struct {
+	db *sql.DB
+}
+
+func NewChecklistRepository(db *sql.DB) *ChecklistRepository {
+	return &ChecklistRepository{db: db}
+}
+
+// CreateItem appends the item to the end of the goal's checklist and sets its
+// position.
+func (r *ChecklistRepository) CreateItem(item *models.ChecklistItem) error {
+	query := `
+		INSERT INTO checklist_items (id, goal_id, title, position, required, created_at)
+		SELECT $1, $2, $3, COALESCE(MAX(position) + 1, 0), $4, $5
+		FROM checklist_items
+		WHERE goal_id = $2
+		RETURNING position
+	`
+	return r.db.QueryRow(query, item.ID, item.GoalID, item.Title, item.Required, item.CreatedAt).Scan(&item.Position)
+}
+
+func (r *ChecklistRepository) GetItemByID(id string) (*models.ChecklistItem, error) {
+	item := &models.ChecklistItem{}
+	query := `
+		SELECT id, goal_id, title, position, required, created_at
+		FROM checklist_items
+		WHERE id = $1
+	`
+	err := r.db.QueryRow(query, id).Scan(&item.ID, &item.GoalID, &item.Title, &item.Position, &item.Required, &item.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// GetItems returns the goal's checklist in order.
+func (r *ChecklistRepository) GetItems(goalID string) ([]*models.ChecklistItem, error) {
+	query := `
+		SELECT id, goal_id, title, position, required, created_at
+		FROM checklist_items
+		WHERE goal_id = $1
+		ORDER BY position ASC, created_at ASC
+	`
+	rows, err := r.db.Query(query, goalID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []*models.ChecklistItem{}
+	for rows.Next() {
+		item := &models.ChecklistItem{}
+		if err := rows.Scan(&item.ID, &item.GoalID, &item.Title, &item.Position, &item.Required, &item.CreatedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func (r *ChecklistRepository) UpdateItem(item *models.ChecklistItem) error {
+	query := `UPDATE checklist_items SET title = $1, required = $2 WHERE id = $3`
+	_, err := r.db.Exec(query, item.Title, item.Required, item.ID)
+	return err
+}
+
+// DeleteItem removes the item along with its checks.
+func (r *ChecklistRepository) DeleteItem(id string) error {
+	query := `DELETE FROM checklist_items WHERE id = $1`
+	_, err := r.db.Exec(query, id)
+	return err
+}
+
+// Reorder sets each item's position to its index in itemIDs.
+func (r *ChecklistRepository) Reorder(goalID string, itemIDs []string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for i, id := range itemIDs {
+		_, err := tx.Exec(`UPDATE checklist_items SET position = $1 WHERE id = $2 AND goal_id = $3`, i, id, goalID)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetChecks returns when each of the goal's items was checked on the day,
+// keyed by item ID.
+func (r *ChecklistRepository) GetChecks(goalID string, date time.Time) (map[string]time.Time, error) {
+	query := `
+		SELECT k.item_id, k.checked_at
+		FROM checklist_checks k
+		JOIN checklist_items i ON i.id = k.item_id
+		WHERE i.goal_id = $1 AND k.date = $2
+	`
+	rows, err := r.db.Query(query, goalID, date.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	checks := make(map[string]time.Time)
+	for rows.Next() {
+		var itemID string
+		var checkedAt time.Time
+		if err := rows.Scan(&itemID, &checkedAt); err != nil {
+			return nil, err
+		}
+		checks[itemID] = checkedAt
+	}
+	return checks, nil
+}
+
+func (r *ChecklistRepository) Check(itemID string, date, checkedAt time.Time) error {
+	query := `
+		INSERT INTO checklist_checks (item_id, date, checked_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (item_id, date) DO NOTHING
+	`
+	_, err := r.db.Exec(query, itemID, date.Format("2006-01-02"), checkedAt)
+	return err
+}
+
+func (r *ChecklistRepository) Uncheck(itemID string, date time.Time) error {
+	query := `DELETE FROM checklist_checks WHERE item_id = $1 AND date = $2`
+	_, err := r.db.Exec(query, itemID, date.Format("2006-01-02"))
+	return err
+}
@@ -13,6 +13,22 @@ func NewCommentRepository(db *sql.DB) *CommentRepository {
 	return &CommentRepository{db: db}
 }
 
+// scanComment reads id, feed_id, user_id, content, hidden, created_at. Comments
+// whose author was erased have no user_id and are attributed to the deleted
+// user placeholder.
+func scanComment(row interface{ Scan(...interface{}) error }, comment *models.Comment) error {
+	var userID sql.NullString
+	err := row.Scan(&comment.ID, &comment.FeedID, &userID, &comment.Content, &comment.Hidden, &comment.CreatedAt)
+	if err != nil {
+		return err
+	}
+	comment.UserID = userID.String
+	if !userID.Valid {
+		comment.Author = models.DeletedUserName
+	}
+	return nil
+}
+
 func (r *CommentRepository) Create(comment *models.Comment) error {
 	query := `
 		INSERT INTO comments (id, feed_id, user_id, content, created_at)
@@ -22,24 +38,41 @@ func (r *CommentRepository) Create(comment *models.Comment) error {
 	return err
 }
 
-func (r *CommentRepository) GetByFeedID(feedID string) ([]*models.Comment, error) {
+func (r *CommentRepository) GetByID(id string) (*models.Comment, error) {
+	comment := &models.Comment{}
 	query := `
-		SELECT id, feed_id, user_id, content, created_at
+		SELECT id, feed_id, user_id, content, hidden, created_at
 		FROM comments
-		WHERE feed_id = $1
-		ORDER BY created_at ASC
+		WHERE id = $1
 	`
-	rows, err := r.db.Query(query, feedID)
+	if err := scanComment(r.db.QueryRow(query, id), comment); err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
+
+// GetByFeedID returns the visible comments on a post for the given viewer.
+// Posts on trashed goals have none.
+func (r *CommentRepository) GetByFeedID(feedID, viewerID string) ([]*models.Comment, error) {
+	query := `
+		SELECT c.id, c.feed_id, c.user_id, c.content, c.hidden, c.created_at
+		FROM comments c
+		JOIN feeds f ON f.id = c.feed_id
+		JOIN goals g ON g.id = f.goal_id
+		WHERE c.feed_id = $1 AND c.hidden = false AND g.deleted_at IS NULL
+		  AND ` + visibleAuthorClause("c.user_id", "$2") + `
+		ORDER BY c.created_at ASC
+	`
+	rows, err := r.db.Query(query, feedID, nullableID(viewerID))
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var comments []*models.Comment
+	comments := []*models.Comment{}
 	for rows.Next() {
 		comment := &models.Comment{}
-		err := rows.Scan(&comment.ID, &comment.FeedID, &comment.UserID, &comment.Content, &comment.CreatedAt)
-		if err != nil {
+		if err := scanComment(rows, comment); err != nil {
 			return nil, err
 		}
 		comments = append(comments, comment)
@@ -52,3 +85,28 @@ func (r *CommentRepository) Delete(id, userID string) error {
 	_, err := r.db.Exec(query, id, userID)
 	return err
 }
+
+// GetByUserID returns every comment the user has written, hidden ones included.
+func (r *CommentRepository) GetByUserID(userID string) ([]*models.Comment, error) {
+	query := `
+		SELECT id, feed_id, user_id, content, hidden, created_at
+		FROM comments
+		WHERE user_id = $1
+		ORDER BY created_at ASC
+	`
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	comments := []*models.Comment{}
+	for rows.Next() {
+		comment := &models.Comment{}
+		if err := scanComment(rows, comment); err != nil {
+			return nil, err
+		}
+		comments = append(comments, comment)
+	}
+	return comments, nil
+}
@@ -11,6 +11,21 @@ type CompletionRepository struct {
 	db *sql.DB
 }
 
+// activeGoalCompletion leaves out completions of goals in the trash.
+const activeGoalCompletion = `goal_id IN (SELECT id FROM goals WHERE deleted_at IS NULL)`
+
+// streakDay keeps the completions that count towards a streak: a day of a
+// daily measurable goal only counts once its value meets the target.
+const streakDay = `NOT EXISTS (
+	SELECT 1 FROM goals g
+	WHERE g.id = completions.goal_id AND g.kind = 'measurable' AND g.frequency = 'daily'
+	  AND NOT COALESCE(CASE g.comparison
+		WHEN 'at_most' THEN completions.value <= g.target_value
+		WHEN 'exactly' THEN abs(completions.value - g.target_value) < 1e-9
+		ELSE completions.value >= g.target_value
+	  END, false)
+)`
+
 func NewCompletionRepository(db *sql.DB) *CompletionRepository {
 	return &CompletionRepository{db: db}
 }
@@ -27,11 +42,54 @@ func (r *CompletionRepository) Create(completion *models.Completion) error {
 	return err
 }
 
+// CreateIfAbsent inserts the completion unless the goal already has one on
+// that date, and reports whether a row was written.
+func (r *CompletionRepository) CreateIfAbsent(completion *models.Completion) (bool, error) {
+	query := `
+	       INSERT INTO completions (id, goal_id, date, count, value, created_at)
+	       VALUES ($1, $2, $3, $4, $5, $6)
+	       ON CONFLICT (goal_id, date) DO NOTHING
+       `
+	res, err := r.db.Exec(query,
+		completion.ID, completion.GoalID, completion.Date, completion.Count, completion.Value, completion.CreatedAt,
+	)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// RecordMeasurement adds a measured value to the goal's completion for the
+// day, combining it with earlier measurements that day by the goal's
+// aggregation: added up for sum, the largest for max and the running mean for
+// avg. It fills in completion.Value and Count with the day's totals and
+// reports whether this was the day's first measurement.
+func (r *CompletionRepository) RecordMeasurement(completion *models.Completion, aggregation string) (bool, error) {
+	query := `
+	       INSERT INTO completions (id, goal_id, date, count, value, created_at)
+	       VALUES ($1, $2, $3, 1, $4, $5)
+	       ON CONFLICT (goal_id, date) DO UPDATE SET
+		       count = completions.count + 1,
+		       value = CASE $6
+			       WHEN 'max' THEN GREATEST(completions.value, EXCLUDED.value)
+			       WHEN 'avg' THEN (COALESCE(completions.value, 0) * completions.count + EXCLUDED.value) / (completions.count + 1)
+			       ELSE COALESCE(completions.value, 0) + EXCLUDED.value
+		       END
+	       RETURNING id, count, value, (xmax = 0)
+       `
+	var inserted bool
+	err := r.db.QueryRow(query,
+		completion.ID, completion.GoalID, completion.Date, completion.Value, completion.CreatedAt, aggregation,
+	).Scan(&completion.ID, &completion.Count, &completion.Value, &inserted)
+	return inserted, err
+}
+
 func (r *CompletionRepository) GetByGoalID(goalID string) ([]*models.Completion, error) {
 	query := `
-	       SELECT id, goal_id, date, count, created_at
+	       SELECT id, goal_id, date, count, value, created_at
 	       FROM completions
-	       WHERE goal_id = $1
+	       WHERE goal_id = $1 AND ` + activeGoalCompletion + `
 	       ORDER BY date DESC
        `
 	rows, err := r.db.Query(query, goalID)
@@ -40,11 +98,42 @@ func (r *CompletionRepository) GetByGoalID(goalID string) ([]*models.Completion,
 	}
 	defer rows.Close()
 
-	var completions []*models.Completion
+	completions := []*models.Completion{}
+	for rows.Next() {
+		completion := &models.Completion{}
+		err := rows.Scan(
+			&completion.ID, &completion.GoalID, &completion.Date, &completion.Count, &completion.Value, &completion.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		completions = append(completions, completion)
+	}
+	return completions, nil
+}
+
+// GetByUserInRange returns the completions of the user's active goals
+// between from and to, inclusive.
+func (r *CompletionRepository) GetByUserInRange(userID string, from, to time.Time) ([]*models.Completion, error) {
+	query := `
+		SELECT c.id, c.goal_id, c.date, c.count, c.value, c.created_at
+		FROM completions c
+		JOIN goals g ON g.id = c.goal_id
+		WHERE g.user_id = $1 AND g.archived = false AND g.deleted_at IS NULL
+		  AND c.date BETWEEN $2 AND $3
+		ORDER BY c.date ASC
+	`
+	rows, err := r.db.Query(query, userID, from.Format("2006-01-02"), to.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	completions := []*models.Completion{}
 	for rows.Next() {
 		completion := &models.Completion{}
 		err := rows.Scan(
-			&completion.ID, &completion.GoalID, &completion.Date, &completion.Count, &completion.CreatedAt,
+			&completion.ID, &completion.GoalID, &completion.Date, &completion.Count, &completion.Value, &completion.CreatedAt,
 		)
 		if err != nil {
 			return nil, err
@@ -54,6 +143,35 @@ func (r *CompletionRepository) GetByGoalID(goalID string) ([]*models.Completion,
 	return completions, nil
 }
 
+func (r *CompletionRepository) GetByGoalAndDate(goalID string, date time.Time) (*models.Completion, error) {
+	completion := &models.Completion{}
+	query := `
+	       SELECT id, goal_id, date, count, value, created_at
+	       FROM completions
+	       WHERE goal_id = $1 AND date = $2
+       `
+	err := r.db.QueryRow(query, goalID, date.Format("2006-01-02")).Scan(
+		&completion.ID, &completion.GoalID, &completion.Date, &completion.Count, &completion.Value, &completion.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return completion, nil
+}
+
+func (r *CompletionRepository) Delete(id string) error {
+	query := `DELETE FROM completions WHERE id = $1`
+	_, err := r.db.Exec(query, id)
+	return err
+}
+
+func (r *CompletionRepository) CountByGoalID(goalID string) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM completions WHERE goal_id = $1 AND ` + activeGoalCompletion
+	err := r.db.QueryRow(query, goalID).Scan(&count)
+	return count, err
+}
+
 func (r *CompletionRepository) GetCompletionExists(goalID string, date time.Time) (bool, error) {
 	var exists bool
 	query := `SELECT EXISTS(SELECT 1 FROM completions WHERE goal_id = $1 AND date = $2)`
@@ -61,6 +179,79 @@ func (r *CompletionRepository) GetCompletionExists(goalID string, date time.Time
 	return exists, err
 }
 
+// CountInRange counts the days with a completion between from and to, inclusive.
+func (r *CompletionRepository) CountInRange(goalID string, from, to time.Time) (int, error) {
+	var count int
+	query := `
+		SELECT COUNT(*)
+		FROM completions
+		WHERE goal_id = $1 AND date BETWEEN $2 AND $3 AND ` + activeGoalCompletion
+	err := r.db.QueryRow(query, goalID, from.Format("2006-01-02"), to.Format("2006-01-02")).Scan(&count)
+	return count, err
+}
+
+// cleanRunSQL counts the days since quit goal g's last slip, or since the day
+// before it was created when there are none. Today counts as clean unless
+// there is a slip today.
+const cleanRunSQL = `(CURRENT_DATE - COALESCE(
+	(SELECT MAX(s.date) FROM completions s WHERE s.goal_id = g.id AND s.date <= CURRENT_DATE),
+	g.created_at::date - 1
+))`
+
+// CalculateCleanRun is CalculateCurrentStreak for quit goals, whose
+// completions are slips: it returns the days since the last slip.
+func (r *CompletionRepository) CalculateCleanRun(goalID string) (int, error) {
+	var run int
+	query := `SELECT ` + cleanRunSQL + ` FROM goals g WHERE g.id = $1`
+	err := r.db.QueryRow(query, goalID).Scan(&run)
+	return run, err
+}
+
+// CalculateLongestCleanRun returns the longest gap between the slips of a
+// quit goal, counting the days from creation to the first slip and from the
+// last slip to today.
+func (r *CompletionRepository) CalculateLongestCleanRun(goalID string) (int, error) {
+	query := `
+		WITH marks AS (
+			SELECT date FROM completions WHERE goal_id = $1 AND date <= CURRENT_DATE
+			UNION
+			SELECT created_at::date - 1 FROM goals WHERE id = $1
+			UNION
+			SELECT CURRENT_DATE + 1
+		)
+		SELECT COALESCE(MAX(gap), 0)
+		FROM (SELECT date - LAG(date) OVER (ORDER BY date) - 1 AS gap FROM marks) gaps
+	`
+	var run int
+	err := r.db.QueryRow(query, goalID).Scan(&run)
+	return run, err
+}
+
+// GetSlipSummary returns a quit goal's number of slips, the days with a
+// slip and the most recent slip.
+func (r *CompletionRepository) GetSlipSummary(goalID string) (*models.QuitStats, error) {
+	stats := &models.QuitStats{}
+	query := `SELECT COALESCE(SUM(count), 0), COUNT(*), MAX(date) FROM completions WHERE goal_id = $1`
+	err := r.db.QueryRow(query, goalID).Scan(&stats.Slips, &stats.SlipDays, &stats.LastSlip)
+	if err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// SumProgress adds up a goal's progress between from and to, inclusive. A
+// completion counts its value when it has one and its count otherwise.
+func (r *CompletionRepository) SumProgress(goalID string, from, to time.Time) (float64, error) {
+	var total float64
+	query := `
+		SELECT COALESCE(SUM(COALESCE(value, count)), 0)
+		FROM completions
+		WHERE goal_id = $1 AND date BETWEEN $2 AND $3
+	`
+	err := r.db.QueryRow(query, goalID, from.Format("2006-01-02"), to.Format("2006-01-02")).Scan(&total)
+	return total, err
+}
+
 func (r *CompletionRepository) CalculateCurrentStreak(goalID string) (int, error) {
 	query := `
 	       WITH consecutive_dates AS (
@@ -69,6 +260,7 @@ func (r *CompletionRepository) CalculateCurrentStreak(goalID string) (int, error
 		       FROM completions
 		       WHERE goal_id = $1
 			 AND date <= CURRENT_DATE
+			 AND ` + streakDay + `
 		       ORDER BY date DESC
 	       ),
 	       streak_groups AS (
@@ -85,7 +277,7 @@ func (r *CompletionRepository) CalculateCurrentStreak(goalID string) (int, error
 		       END, 0
 	       ) as current_streak
 	       FROM completions c
-	       WHERE c.goal_id = $1
+	       WHERE c.goal_id = $1 AND c.date IN (SELECT date FROM consecutive_dates)
        `
 
 	var streak int
@@ -103,10 +295,13 @@ func (r *CompletionRepository) GetCompletionGraphData(goalID string, days int) (
 		       )::date AS date
 	       )
 	       SELECT 
-		       ds.date::text,
-		       CASE WHEN c.date IS NOT NULL THEN 1 ELSE 0 END as count
+		       ds.date,
+		       COALESCE(c.count, 0) as completions,
+		       CASE WHEN c.date IS NOT NULL THEN 1 ELSE 0 END as count,
+		       c.value
 	       FROM date_series ds
 	       LEFT JOIN completions c ON c.goal_id = $1 AND c.date = ds.date
+		       AND c.goal_id IN (SELECT id FROM goals WHERE deleted_at IS NULL)
 	       ORDER BY ds.date
        `
 
@@ -116,10 +311,10 @@ func (r *CompletionRepository) GetCompletionGraphData(goalID string, days int) (
 	}
 	defer rows.Close()
 
-	var data []models.CompletionGraphData
+	data := []models.CompletionGraphData{}
 	for rows.Next() {
 		var item models.CompletionGraphData
-		err := rows.Scan(&item.Date, &item.Count)
+		err := rows.Scan(&item.Date, &item.Completions, &item.Count, &item.Value)
 		if err != nil {
 			return nil, err
 		}
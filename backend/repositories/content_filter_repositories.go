@@ -0,0 +1,92 @@
+package repositories
+
+import (
+	"DoToday/models"
+	"database/sql"
+	"time"
+)
+
+type ContentFilterRepository struct {
+	db *sql.DB
+}
+
+func NewContentFilterRepository(db *sql.DB) *ContentFilterRepository {
+	return &ContentFilterRepository{db: db}
+}
+
+func (r *ContentFilterRepository) CreateFlag(flag *models.ContentFlag) error {
+	query := `
+		INSERT INTO content_flags (id, user_id, kind, target_id, content, reasons, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	_, err := r.db.Exec(query,
+		flag.ID, flag.UserID, flag.Kind, flag.TargetID, flag.Content, flag.Reasons, flag.CreatedAt,
+	)
+	return err
+}
+
+func (r *ContentFilterRepository) GetFlags(limit int) ([]*models.ContentFlag, error) {
+	query := `
+		SELECT id, user_id, kind, target_id, content, reasons, created_at
+		FROM content_flags
+		ORDER BY created_at DESC
+		LIMIT $1
+	`
+	rows, err := r.db.Query(query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	flags := []*models.ContentFlag{}
+	for rows.Next() {
+		flag := &models.ContentFlag{}
+		err := rows.Scan(
+			&flag.ID, &flag.UserID, &flag.Kind, &flag.TargetID, &flag.Content, &flag.Reasons, &flag.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		flags = append(flags, flag)
+	}
+	return flags, nil
+}
+
+// CountRecentDuplicates counts how many times the user has posted exactly the
+// same text of the given kind since the given time.
+func (r *ContentFilterRepository) CountRecentDuplicates(userID, kind, text string, since time.Time) (int, error) {
+	var query string
+	switch kind {
+	case models.ContentKindFeed:
+		query = `
+			SELECT COUNT(*)
+			FROM feeds f
+			JOIN goals g ON g.id = f.goal_id
+			WHERE g.user_id = $1 AND lower(f.description) = lower($2) AND f.created_at >= $3
+		`
+	case models.ContentKindComment:
+		query = `
+			SELECT COUNT(*)
+			FROM comments
+			WHERE user_id = $1 AND lower(content) = lower($2) AND created_at >= $3
+		`
+	case models.ContentKindChallenge:
+		query = `
+			SELECT COUNT(*)
+			FROM challenge_posts
+			WHERE user_id = $1 AND lower(content) = lower($2) AND created_at >= $3
+		`
+	case models.ContentKindGoalTitle:
+		query = `
+			SELECT COUNT(*)
+			FROM goals
+			WHERE user_id = $1 AND lower(title) = lower($2) AND created_at >= $3
+		`
+	default:
+		return 0, nil
+	}
+
+	var count int
+	err := r.db.QueryRow(query, userID, text, since).Scan(&count)
+	return count, err
+}
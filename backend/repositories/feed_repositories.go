@@ -13,33 +13,43 @@ func NewFeedRepository(db *sql.DB) *FeedRepository {
 	return &FeedRepository{db: db}
 }
 
-func (r *FeedRepository) Create(feed *models.Feed) error {
+// Create inserts the post unless the goal already has one on that date, and
+// reports whether a row was written.
+func (r *FeedRepository) Create(feed *models.Feed) (bool, error) {
 	query := `
 		INSERT INTO feeds (id, goal_id, date, description)
 		VALUES ($1, $2, $3, $4)
 		ON CONFLICT (goal_id, date) DO NOTHING
 	`
-	_, err := r.db.Exec(query, feed.ID, feed.GoalID, feed.Date, feed.Description)
-	return err
+	res, err := r.db.Exec(query, feed.ID, feed.GoalID, feed.Date, feed.Description)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
 }
 
-func (r *FeedRepository) GetByGoalID(goalID string) ([]*models.Feed, error) {
+// GetByGoalID returns the visible posts for a goal. viewerID may be empty for
+// anonymous callers.
+func (r *FeedRepository) GetByGoalID(goalID, viewerID string) ([]*models.Feed, error) {
 	query := `
-		SELECT id, goal_id, date, description
-		FROM feeds
-		WHERE goal_id = $1
-		ORDER BY date DESC
+		SELECT f.id, f.goal_id, f.date, f.description, f.hidden
+		FROM feeds f
+		JOIN goals g ON g.id = f.goal_id
+		WHERE f.goal_id = $1 AND f.hidden = false AND g.deleted_at IS NULL
+		  AND ` + visibleAuthorClause("g.user_id", "$2") + `
+		ORDER BY f.date DESC
 	`
-	rows, err := r.db.Query(query, goalID)
+	rows, err := r.db.Query(query, goalID, nullableID(viewerID))
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var feeds []*models.Feed
+	feeds := []*models.Feed{}
 	for rows.Next() {
 		feed := &models.Feed{}
-		err := rows.Scan(&feed.ID, &feed.GoalID, &feed.Date, &feed.Description)
+		err := rows.Scan(&feed.ID, &feed.GoalID, &feed.Date, &feed.Description, &feed.Hidden)
 		if err != nil {
 			return nil, err
 		}
@@ -48,16 +58,63 @@ func (r *FeedRepository) GetByGoalID(goalID string) ([]*models.Feed, error) {
 	return feeds, nil
 }
 
-func (r *FeedRepository) GetByID(id string) (*models.Feed, error) {
+// GetByID returns a visible post. Hidden posts and posts by blocked or muted
+// authors are reported as sql.ErrNoRows.
+func (r *FeedRepository) GetByID(id, viewerID string) (*models.Feed, error) {
 	feed := &models.Feed{}
 	query := `
-		SELECT id, goal_id, date, description
-		FROM feeds
-		WHERE id = $1
+		SELECT f.id, f.goal_id, f.date, f.description, f.hidden
+		FROM feeds f
+		JOIN goals g ON g.id = f.goal_id
+		WHERE f.id = $1 AND f.hidden = false AND g.deleted_at IS NULL
+		  AND ` + visibleAuthorClause("g.user_id", "$2") + `
 	`
-	err := r.db.QueryRow(query, id).Scan(&feed.ID, &feed.GoalID, &feed.Date, &feed.Description)
+	err := r.db.QueryRow(query, id, nullableID(viewerID)).Scan(
+		&feed.ID, &feed.GoalID, &feed.Date, &feed.Description, &feed.Hidden,
+	)
 	if err != nil {
 		return nil, err
 	}
 	return feed, nil
 }
+
+// GetOwnerID returns the ID of the user whose goal the post belongs to.
+// Posts on trashed goals are not found.
+func (r *FeedRepository) GetOwnerID(feedID string) (string, error) {
+	var ownerID string
+	query := `
+		SELECT g.user_id
+		FROM feeds f
+		JOIN goals g ON g.id = f.goal_id
+		WHERE f.id = $1 AND g.deleted_at IS NULL
+	`
+	err := r.db.QueryRow(query, feedID).Scan(&ownerID)
+	return ownerID, err
+}
+
+// GetByUserID returns every post on the user's goals, hidden ones included.
+func (r *FeedRepository) GetByUserID(userID string) ([]*models.Feed, error) {
+	query := `
+		SELECT f.id, f.goal_id, f.date, f.description, f.hidden
+		FROM feeds f
+		JOIN goals g ON g.id = f.goal_id
+		WHERE g.user_id = $1 AND g.deleted_at IS NULL
+		ORDER BY f.date ASC
+	`
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	feeds := []*models.Feed{}
+	for rows.Next() {
+		feed := &models.Feed{}
+		err := rows.Scan(&feed.ID, &feed.GoalID, &feed.Date, &feed.Description, &feed.Hidden)
+		if err != nil {
+			return nil, err
+		}
+		feeds = append(feeds, feed)
+	}
+	return feeds, nil
+}
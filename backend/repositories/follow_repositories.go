@@ -0,0 +1,56 @@
+package repositories
+
+import (
+	"DoToday/models"
+	"database/sql"
+	"time"
+)
+
+type FollowRepository struct {
+	db *sql.DB
+}
+
+func NewFollowRepository(db *sql.DB) *FollowRepository {
+	return &FollowRepository{db: db}
+}
+
+func (r *FollowRepository) Follow(followerID, followeeID string) error {
+	query := `
+		INSERT INTO follows (follower_id, followee_id, created_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (follower_id, followee_id) DO NOTHING
+	`
+	_, err := r.db.Exec(query, followerID, followeeID, time.Now())
+	return err
+}
+
+func (r *FollowRepository) Unfollow(followerID, followeeID string) error {
+	query := `DELETE FROM follows WHERE follower_id = $1 AND followee_id = $2`
+	_, err := r.db.Exec(query, followerID, followeeID)
+	return err
+}
+
+func (r *FollowRepository) GetFollowing(followerID string) ([]*models.Follow, error) {
+	query := `
+		SELECT f.follower_id, f.followee_id, p.username, f.created_at
+		FROM follows f
+		JOIN profiles p ON p.id = f.followee_id
+		WHERE f.follower_id = $1
+		ORDER BY p.username ASC
+	`
+	rows, err := r.db.Query(query, followerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	follows := []*models.Follow{}
+	for rows.Next() {
+		f := &models.Follow{}
+		if err := rows.Scan(&f.FollowerID, &f.FolloweeID, &f.Username, &f.CreatedAt); err != nil {
+			return nil, err
+		}
+		follows = append(follows, f)
+	}
+	return follows, nil
+}
@@ -3,6 +3,10 @@ package repositories
 import (
 	"DoToday/models"
 	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 type GoalRepository struct {
@@ -13,28 +17,23 @@ func NewGoalRepository(db *sql.DB) *GoalRepository {
 	return &GoalRepository{db: db}
 }
 
-func (r *GoalRepository) Create(goal *models.Goal) error {
-	query := `
-	       INSERT INTO goals (id, user_id, title, category, description, frequency, target_count, deadline, is_public, current_streak, archived, created_at)
-	       VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
-       `
-	_, err := r.db.Exec(query,
-		goal.ID, goal.UserID, goal.Title, goal.Category, goal.Description, goal.Frequency, goal.TargetCount,
-		goal.Deadline, goal.IsPublic, goal.CurrentStreak, goal.Archived, goal.CreatedAt,
-	)
-	return err
-}
+// goalColumns lists the goal columns read by scanGoal; queries alias goals as g.
+// The streak of a quit goal grows every day without a slip, so it is computed
+// rather than read.
+const goalColumns = `
+	g.id, g.user_id, g.title, g.category, g.category_id, g.description, g.frequency, g.target_count,
+	g.kind, g.unit, g.target_value, g.comparison, g.aggregation, g.deadline, g.is_public,
+	CASE WHEN g.kind = 'quit' THEN ` + cleanRunSQL + ` ELSE g.current_streak END,
+	g.archived, g.created_at, g.deleted_at,
+	ARRAY(SELECT t.tag FROM goal_tags t WHERE t.goal_id = g.id ORDER BY t.tag)
+`
 
-func (r *GoalRepository) GetByID(id string) (*models.Goal, error) {
+func scanGoal(row interface{ Scan(...interface{}) error }) (*models.Goal, error) {
 	goal := &models.Goal{}
-	query := `
-	       SELECT id, user_id, title, category, description, frequency, target_count, deadline, is_public, current_streak, archived, created_at
-	       FROM goals
-	       WHERE id = $1
-       `
-	err := r.db.QueryRow(query, id).Scan(
-		&goal.ID, &goal.UserID, &goal.Title, &goal.Category, &goal.Description, &goal.Frequency, &goal.TargetCount,
-		&goal.Deadline, &goal.IsPublic, &goal.CurrentStreak, &goal.Archived, &goal.CreatedAt,
+	err := row.Scan(
+		&goal.ID, &goal.UserID, &goal.Title, &goal.Category, &goal.CategoryID, &goal.Description, &goal.Frequency,
+		&goal.TargetCount, &goal.Kind, &goal.Unit, &goal.TargetValue, &goal.Comparison, &goal.Aggregation, &goal.Deadline, &goal.IsPublic, &goal.CurrentStreak, &goal.Archived, &goal.CreatedAt,
+		&goal.DeletedAt, (*pq.StringArray)(&goal.Tags),
 	)
 	if err != nil {
 		return nil, err
@@ -42,26 +41,17 @@ func (r *GoalRepository) GetByID(id string) (*models.Goal, error) {
 	return goal, nil
 }
 
-func (r *GoalRepository) GetByUserID(userID string) ([]*models.Goal, error) {
-	query := `
-	       SELECT id, user_id, title, category, description, frequency, target_count, deadline, is_public, current_streak, archived, created_at
-	       FROM goals
-	       WHERE user_id = $1 AND archived = false
-	       ORDER BY created_at DESC
-       `
-	rows, err := r.db.Query(query, userID)
+// queryGoals runs a query selecting goalColumns and scans every row.
+func queryGoals(db *sql.DB, query string, args ...interface{}) ([]*models.Goal, error) {
+	rows, err := db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var goals []*models.Goal
+	goals := []*models.Goal{}
 	for rows.Next() {
-		goal := &models.Goal{}
-		err := rows.Scan(
-			&goal.ID, &goal.UserID, &goal.Title, &goal.Category, &goal.Description, &goal.Frequency, &goal.TargetCount,
-			&goal.Deadline, &goal.IsPublic, &goal.CurrentStreak, &goal.Archived, &goal.CreatedAt,
-		)
+		goal, err := scanGoal(rows)
 		if err != nil {
 			return nil, err
 		}
@@ -70,61 +60,331 @@ func (r *GoalRepository) GetByUserID(userID string) ([]*models.Goal, error) {
 	return goals, nil
 }
 
-func (r *GoalRepository) GetPublicGoals(limit int) ([]*models.Goal, error) {
+// Create stores the goal along with its first schedule revision.
+func (r *GoalRepository) Create(goal *models.Goal) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
 	query := `
-		SELECT g.id, g.user_id, g.title, g.description, g.deadline, g.is_public, 
-		       g.current_streak, g.archived, g.created_at, p.username
-		FROM goals g
-		JOIN profiles p ON g.user_id = p.id
-		WHERE g.is_public = true AND g.archived = false
-		ORDER BY g.created_at DESC
-		LIMIT $1
+	       INSERT INTO goals (id, user_id, title, category, category_id, description, frequency, target_count,
+	                          kind, unit, target_value, comparison, aggregation, deadline, is_public, current_streak, archived, created_at)
+	       VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
+       `
+	_, err = tx.Exec(query,
+		goal.ID, goal.UserID, goal.Title, goal.Category, goal.CategoryID, goal.Description, goal.Frequency, goal.TargetCount,
+		goal.Kind, goal.Unit, goal.TargetValue, goal.Comparison, goal.Aggregation,
+		goal.Deadline, goal.IsPublic, goal.CurrentStreak, goal.Archived, goal.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	err = saveRevision(tx, &models.GoalRevision{
+		ID:            uuid.NewString(),
+		GoalID:        goal.ID,
+		Frequency:     goal.Frequency,
+		TargetCount:   goal.TargetCount,
+		EffectiveFrom: goal.CreatedAt,
+		CreatedAt:     goal.CreatedAt,
+	})
+	if err != nil {
+		return err
+	}
+	if err := saveTags(tx, goal.ID, goal.Tags); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// saveTags replaces the goal's tags.
+func saveTags(tx *sql.Tx, goalID string, tags []string) error {
+	if _, err := tx.Exec(`DELETE FROM goal_tags WHERE goal_id = $1`, goalID); err != nil {
+		return err
+	}
+	query := `
+		INSERT INTO goal_tags (goal_id, tag)
+		SELECT $1, unnest($2::text[])
+		ON CONFLICT (goal_id, tag) DO NOTHING
+	`
+	_, err := tx.Exec(query, goalID, pq.Array(tags))
+	return err
+}
+
+// GetTags returns the tags on the user's goals with how many goals use each,
+// most used first.
+func (r *GoalRepository) GetTags(userID string) ([]*models.TagCount, error) {
+	query := `
+		SELECT t.tag, COUNT(*)
+		FROM goal_tags t
+		JOIN goals g ON g.id = t.goal_id
+		WHERE g.user_id = $1 AND g.deleted_at IS NULL
+		GROUP BY t.tag
+		ORDER BY COUNT(*) DESC, t.tag ASC
+	`
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := []*models.TagCount{}
+	for rows.Next() {
+		tag := &models.TagCount{}
+		if err := rows.Scan(&tag.Tag, &tag.Goals); err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+// saveRevision stores a revision, replacing any other revision of the goal
+// taking effect on the same date.
+func saveRevision(tx *sql.Tx, rev *models.GoalRevision) error {
+	query := `
+		INSERT INTO goal_revisions (id, goal_id, frequency, target_count, effective_from, created_at)
+		VALUES ($1, $2, $3, $4, $5::date, $6)
+		ON CONFLICT (goal_id, effective_from)
+		DO UPDATE SET frequency = EXCLUDED.frequency, target_count = EXCLUDED.target_count, created_at = EXCLUDED.created_at
+	`
+	_, err := tx.Exec(query,
+		rev.ID, rev.GoalID, rev.Frequency, rev.TargetCount, rev.EffectiveFrom.Format("2006-01-02"), rev.CreatedAt,
+	)
+	return err
+}
+
+// GetRevisions returns the goal's schedule revisions, oldest first.
+func (r *GoalRepository) GetRevisions(goalID string) ([]*models.GoalRevision, error) {
+	query := `
+		SELECT id, goal_id, frequency, target_count, effective_from, created_at
+		FROM goal_revisions
+		WHERE goal_id = $1
+		ORDER BY effective_from ASC
 	`
-	rows, err := r.db.Query(query, limit)
+	rows, err := r.db.Query(query, goalID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var goals []*models.Goal
+	revisions := []*models.GoalRevision{}
 	for rows.Next() {
-		goal := &models.Goal{}
-		err := rows.Scan(
-			&goal.ID, &goal.UserID, &goal.Title, &goal.Category, &goal.Description, &goal.Frequency, &goal.TargetCount,
-			&goal.Deadline, &goal.IsPublic, &goal.CurrentStreak, &goal.Archived, &goal.CreatedAt,
-		)
+		rev := &models.GoalRevision{}
+		err := rows.Scan(&rev.ID, &rev.GoalID, &rev.Frequency, &rev.TargetCount, &rev.EffectiveFrom, &rev.CreatedAt)
 		if err != nil {
 			return nil, err
 		}
-		goals = append(goals, goal)
+		revisions = append(revisions, rev)
 	}
-	return goals, nil
+	return revisions, nil
+}
+
+func (r *GoalRepository) GetByID(id string) (*models.Goal, error) {
+	query := `SELECT ` + goalColumns + ` FROM goals g WHERE g.id = $1 AND g.deleted_at IS NULL`
+	return scanGoal(r.db.QueryRow(query, id))
+}
+
+func (r *GoalRepository) GetByUserID(userID string) ([]*models.Goal, error) {
+	query := `
+		SELECT ` + goalColumns + `
+		FROM goals g
+		WHERE g.user_id = $1 AND g.archived = false AND g.deleted_at IS NULL
+		ORDER BY g.created_at DESC
+	`
+	return queryGoals(r.db, query, userID)
+}
+
+// GetArchivedByUserID returns the user's archived goals, most recent first.
+func (r *GoalRepository) GetArchivedByUserID(userID string) ([]*models.Goal, error) {
+	query := `
+		SELECT ` + goalColumns + `
+		FROM goals g
+		WHERE g.user_id = $1 AND g.archived = true AND g.deleted_at IS NULL
+		ORDER BY g.created_at DESC
+	`
+	return queryGoals(r.db, query, userID)
+}
+
+// GetAllByUserID returns every goal the user owns, archived ones included but
+// not those in the trash.
+func (r *GoalRepository) GetAllByUserID(userID string) ([]*models.Goal, error) {
+	query := `
+		SELECT ` + goalColumns + `
+		FROM goals g
+		WHERE g.user_id = $1 AND g.deleted_at IS NULL
+		ORDER BY g.created_at ASC
+	`
+	return queryGoals(r.db, query, userID)
+}
+
+// GetPublicGoals returns recent public goals, leaving out goals from users the
+// viewer has blocked or muted. viewerID may be empty for anonymous callers.
+func (r *GoalRepository) GetPublicGoals(limit int, viewerID string) ([]*models.Goal, error) {
+	query := `
+		SELECT ` + goalColumns + `
+		FROM goals g
+		WHERE g.is_public = true AND g.archived = false AND g.deleted_at IS NULL
+		  AND ` + visibleAuthorClause("g.user_id", "$2") + `
+		ORDER BY g.created_at DESC
+		LIMIT $1
+	`
+	return queryGoals(r.db, query, limit, nullableID(viewerID))
 }
 
-func (r *GoalRepository) Update(goal *models.Goal) error {
+// Update saves the goal's editable fields. When rev is not nil the schedule
+// revision is stored in the same transaction.
+func (r *GoalRepository) Update(goal *models.Goal, rev *models.GoalRevision) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
 	query := `
 		UPDATE goals
-		SET title = $1, description = $2, deadline = $3, is_public = $4
-		WHERE id = $5 AND user_id = $6
+		SET title = $1, category = $2, category_id = $3, description = $4, frequency = $5, target_count = $6,
+		    unit = $7, target_value = $8, comparison = $9, aggregation = $10,
+		    deadline = $11, is_public = $12, archived = $13
+		WHERE id = $14 AND user_id = $15 AND deleted_at IS NULL
 	`
-	_, err := r.db.Exec(query,
-		goal.Title, goal.Description, goal.Deadline, goal.IsPublic, goal.ID, goal.UserID,
+	_, err = tx.Exec(query,
+		goal.Title, goal.Category, goal.CategoryID, goal.Description, goal.Frequency, goal.TargetCount,
+		goal.Unit, goal.TargetValue, goal.Comparison, goal.Aggregation,
+		goal.Deadline, goal.IsPublic, goal.Archived, goal.ID, goal.UserID,
 	)
-	return err
+	if err != nil {
+		return err
+	}
+
+	if rev != nil {
+		if err := saveRevision(tx, rev); err != nil {
+			return err
+		}
+	}
+	if err := saveTags(tx, goal.ID, goal.Tags); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
+// Delete removes the goal together with its completions, feed posts and the
+// comments and likes on those posts.
 func (r *GoalRepository) Delete(id, userID string) error {
-	query := `DELETE FROM goals WHERE id = $1 AND user_id = $2`
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	goalIDs := `SELECT id FROM goals WHERE id = $1 AND user_id = $2`
+	if _, err := deleteGoalData(tx, goalIDs, id, userID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM goals WHERE id = $1 AND user_id = $2`, id, userID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Trash moves the goal to the trash. Its rows are kept until it is restored
+// or purged.
+func (r *GoalRepository) Trash(id, userID string, at time.Time) error {
+	query := `UPDATE goals SET deleted_at = $1 WHERE id = $2 AND user_id = $3 AND deleted_at IS NULL`
+	_, err := r.db.Exec(query, at, id, userID)
+	return err
+}
+
+func (r *GoalRepository) Restore(id, userID string) error {
+	query := `UPDATE goals SET deleted_at = NULL WHERE id = $1 AND user_id = $2`
 	_, err := r.db.Exec(query, id, userID)
 	return err
 }
 
+// GetTrashedByID returns a goal in the trash, or sql.ErrNoRows.
+func (r *GoalRepository) GetTrashedByID(id string) (*models.Goal, error) {
+	query := `SELECT ` + goalColumns + ` FROM goals g WHERE g.id = $1 AND g.deleted_at IS NOT NULL`
+	return scanGoal(r.db.QueryRow(query, id))
+}
+
+// GetTrashed returns goals in the trash, most recently deleted first. An empty
+// userID matches every user; deletedBefore, when set, limits the result to
+// goals trashed before that time.
+func (r *GoalRepository) GetTrashed(userID string, deletedBefore *time.Time) ([]*models.Goal, error) {
+	query := `
+		SELECT ` + goalColumns + `
+		FROM goals g
+		WHERE g.deleted_at IS NOT NULL
+		  AND ($1::uuid IS NULL OR g.user_id = $1)
+		  AND ($2::timestamptz IS NULL OR g.deleted_at < $2)
+		ORDER BY g.deleted_at DESC
+	`
+	return queryGoals(r.db, query, nullableID(userID), deletedBefore)
+}
+
+// GoalDataCounts reports how many rows deleteGoalData removed.
+type GoalDataCounts struct {
+	Comments    int
+	Likes       int
+	Feeds       int
+	Completions int
+}
+
+// deleteGoalData removes the rows hanging off the goals selected by goalIDs,
+// a subquery returning goal IDs that may use args as placeholders.
+func deleteGoalData(tx *sql.Tx, goalIDs string, args ...interface{}) (*GoalDataCounts, error) {
+	counts := &GoalDataCounts{}
+	statements := []struct {
+		query string
+		count *int
+	}{
+		{`DELETE FROM comments WHERE feed_id IN (SELECT id FROM feeds WHERE goal_id IN (` + goalIDs + `))`, &counts.Comments},
+		{`DELETE FROM likes WHERE feed_id IN (SELECT id FROM feeds WHERE goal_id IN (` + goalIDs + `))`, &counts.Likes},
+		{`DELETE FROM feeds WHERE goal_id IN (` + goalIDs + `)`, &counts.Feeds},
+		{`DELETE FROM completions WHERE goal_id IN (` + goalIDs + `)`, &counts.Completions},
+	}
+	for _, stmt := range statements {
+		res, err := tx.Exec(stmt.query, args...)
+		if err != nil {
+			return nil, err
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return nil, err
+		}
+		*stmt.count = int(n)
+	}
+	return counts, nil
+}
+
 func (r *GoalRepository) Archive(id, userID string) error {
 	query := `UPDATE goals SET archived = true WHERE id = $1 AND user_id = $2`
 	_, err := r.db.Exec(query, id, userID)
 	return err
 }
 
+func (r *GoalRepository) Unarchive(id, userID string) error {
+	query := `UPDATE goals SET archived = false, unarchived_at = now() WHERE id = $1 AND user_id = $2`
+	_, err := r.db.Exec(query, id, userID)
+	return err
+}
+
+// ArchiveExpired archives active goals whose deadline has passed and returns
+// them. Deadlines earlier than the goal's creation are ignored, as are goals
+// the user unarchived after their deadline.
+func (r *GoalRepository) ArchiveExpired(now time.Time) ([]*models.Goal, error) {
+	query := `
+		UPDATE goals g
+		SET archived = true
+		WHERE g.archived = false AND g.deleted_at IS NULL
+		  AND g.deadline IS NOT NULL AND g.deadline > g.created_at AND g.deadline < $1
+		  AND (g.unarchived_at IS NULL OR g.unarchived_at < g.deadline)
+		RETURNING ` + goalColumns
+	return queryGoals(r.db, query, now)
+}
+
 func (r *GoalRepository) UpdateStreak(goalID string, streak int) error {
 	query := `UPDATE goals SET current_streak = $1 WHERE id = $2`
 	_, err := r.db.Exec(query, streak, goalID)
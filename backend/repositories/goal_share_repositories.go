@@ -0,0 +1,165 @@
+package repositories
+
+import (
+	"DoToday/models"
+	"database/sql"
+	"time"
+)
+
+type GoalShareRepository struct {
+	db *sql.DB
+}
+
+func NewGoalShareRepository(db *sql.DB) *GoalShareRepository {
+	return &GoalShareRepository{db: db}
+}
+
+// Upsert grants access to a goal, changing the role if a grant already exists.
+func (r *GoalShareRepository) Upsert(share *models.GoalShare) error {
+	query := `
+		INSERT INTO goal_shares (goal_id, user_id, role, created_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (goal_id, user_id) DO UPDATE SET role = EXCLUDED.role
+	`
+	_, err := r.db.Exec(query, share.GoalID, share.UserID, share.Role, share.CreatedAt)
+	return err
+}
+
+func (r *GoalShareRepository) Delete(goalID, userID string) error {
+	query := `DELETE FROM goal_shares WHERE goal_id = $1 AND user_id = $2`
+	_, err := r.db.Exec(query, goalID, userID)
+	return err
+}
+
+func (r *GoalShareRepository) GetByGoalID(goalID string) ([]*models.GoalShare, error) {
+	query := `
+		SELECT s.goal_id, s.user_id, p.username, s.role, s.created_at
+		FROM goal_shares s
+		JOIN profiles p ON p.id = s.user_id
+		WHERE s.goal_id = $1
+		ORDER BY s.created_at ASC
+	`
+	rows, err := r.db.Query(query, goalID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	shares := []*models.GoalShare{}
+	for rows.Next() {
+		share := &models.GoalShare{}
+		if err := rows.Scan(&share.GoalID, &share.UserID, &share.Username, &share.Role, &share.CreatedAt); err != nil {
+			return nil, err
+		}
+		shares = append(shares, share)
+	}
+	return shares, nil
+}
+
+// GetRole returns the user's share role on a goal, or sql.ErrNoRows when the
+// goal is not shared with them.
+func (r *GoalShareRepository) GetRole(goalID, userID string) (string, error) {
+	var role string
+	query := `SELECT role FROM goal_shares WHERE goal_id = $1 AND user_id = $2`
+	err := r.db.QueryRow(query, goalID, userID).Scan(&role)
+	return role, err
+}
+
+func (r *GoalShareRepository) CountByRole(goalID, role string) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM goal_shares WHERE goal_id = $1 AND role = $2`
+	err := r.db.QueryRow(query, goalID, role).Scan(&count)
+	return count, err
+}
+
+// GetSharedWithUser returns the active goals other users have shared with the user.
+func (r *GoalShareRepository) GetSharedWithUser(userID string) ([]*models.Goal, error) {
+	query := `
+		SELECT ` + goalColumns + `
+		FROM goals g
+		JOIN goal_shares s ON s.goal_id = g.id
+		WHERE s.user_id = $1 AND g.archived = false AND g.deleted_at IS NULL
+		ORDER BY g.created_at DESC
+	`
+	return queryGoals(r.db, query, userID)
+}
+
+func (r *GoalShareRepository) CreateEncouragement(encouragement *models.Encouragement) error {
+	query := `
+		INSERT INTO encouragements (id, goal_id, user_id, message, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+	_, err := r.db.Exec(query,
+		encouragement.ID, encouragement.GoalID, encouragement.UserID, encouragement.Message, encouragement.CreatedAt,
+	)
+	return err
+}
+
+func (r *GoalShareRepository) GetEncouragements(goalID string) ([]*models.Encouragement, error) {
+	query := `
+		SELECT id, goal_id, user_id, message, created_at
+		FROM encouragements
+		WHERE goal_id = $1
+		ORDER BY created_at DESC
+	`
+	rows, err := r.db.Query(query, goalID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	encouragements := []*models.Encouragement{}
+	for rows.Next() {
+		e := &models.Encouragement{}
+		if err := rows.Scan(&e.ID, &e.GoalID, &e.UserID, &e.Message, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		encouragements = append(encouragements, e)
+	}
+	return encouragements, nil
+}
+
+// MissedGoal is a partner who should hear that a shared daily goal was missed.
+type MissedGoal struct {
+	GoalID        string
+	GoalTitle     string
+	OwnerUsername string
+	PartnerID     string
+}
+
+// GetMissedPartnerGoals finds active goals with partners that were on a daily
+// schedule on the given day and have no completion for it.
+func (r *GoalShareRepository) GetMissedPartnerGoals(day time.Time) ([]*MissedGoal, error) {
+	query := `
+		SELECT g.id, g.title, p.username, s.user_id
+		FROM goals g
+		JOIN goal_shares s ON s.goal_id = g.id AND s.role = 'partner'
+		JOIN profiles p ON p.id = g.user_id
+		WHERE g.archived = false AND g.deleted_at IS NULL AND g.kind IN ('habit', 'measurable')
+		  AND COALESCE(
+			(SELECT r.frequency FROM goal_revisions r
+			 WHERE r.goal_id = g.id AND r.effective_from <= $1::date
+			 ORDER BY r.effective_from DESC LIMIT 1),
+			NULLIF(g.frequency, ''), 'daily'
+		  ) = 'daily'
+		  AND g.created_at::date < $1::date
+		  AND NOT EXISTS (
+			SELECT 1 FROM completions c WHERE c.goal_id = g.id AND c.date = $1::date
+		  )
+	`
+	rows, err := r.db.Query(query, day.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var missed []*MissedGoal
+	for rows.Next() {
+		m := &MissedGoal{}
+		if err := rows.Scan(&m.GoalID, &m.GoalTitle, &m.OwnerUsername, &m.PartnerID); err != nil {
+			return nil, err
+		}
+		missed = append(missed, m)
+	}
+	return missed, nil
+}
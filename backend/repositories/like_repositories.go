@@ -29,10 +29,17 @@ func (r *LikeRepository) Delete(feedID, userID string) error {
 	return err
 }
 
-func (r *LikeRepository) Count(feedID string) (int, error) {
+// Count returns the number of likes on a post, leaving out likes from users
+// the viewer has blocked or muted.
+func (r *LikeRepository) Count(feedID, viewerID string) (int, error) {
 	var count int
-	query := `SELECT COUNT(*) FROM likes WHERE feed_id = $1`
-	err := r.db.QueryRow(query, feedID).Scan(&count)
+	query := `
+		SELECT COUNT(*)
+		FROM likes l
+		WHERE l.feed_id = $1
+		  AND ` + visibleAuthorClause("l.user_id", "$2") + `
+	`
+	err := r.db.QueryRow(query, feedID, nullableID(viewerID)).Scan(&count)
 	return count, err
 }
 
@@ -42,3 +49,28 @@ func (r *LikeRepository) Exists(feedID, userID string) (bool, error) {
 	err := r.db.QueryRow(query, feedID, userID).Scan(&exists)
 	return exists, err
 }
+
+// GetByUserID returns every like the user has given.
+func (r *LikeRepository) GetByUserID(userID string) ([]*models.Like, error) {
+	query := `
+		SELECT id, feed_id, user_id, created_at
+		FROM likes
+		WHERE user_id = $1
+		ORDER BY created_at ASC
+	`
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	likes := []*models.Like{}
+	for rows.Next() {
+		like := &models.Like{}
+		if err := rows.Scan(&like.ID, &like.FeedID, &like.UserID, &like.CreatedAt); err != nil {
+			return nil, err
+		}
+		likes = append(likes, like)
+	}
+	return likes, nil
+}
@@ -0,0 +1,206 @@
+package repositories
+
+import (
+	"DoToday/models"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+type ModerationRepository struct {
+	db *sql.DB
+}
+
+func NewModerationRepository(db *sql.DB) *ModerationRepository {
+	return &ModerationRepository{db: db}
+}
+
+// visibleAuthorClause builds a condition that drops rows written by users the
+// viewer has blocked or muted, and by users who have blocked the viewer.
+// viewerParam is the placeholder holding the viewer ID; when it is NULL
+// (anonymous viewer) nothing is filtered.
+func visibleAuthorClause(authorCol, viewerParam string) string {
+	return fmt.Sprintf(`NOT EXISTS (
+			SELECT 1 FROM blocks b
+			WHERE (b.blocker_id = %[2]s AND b.blocked_id = %[1]s)
+			   OR (b.blocker_id = %[1]s AND b.blocked_id = %[2]s)
+		)
+		AND NOT EXISTS (
+			SELECT 1 FROM mutes m
+			WHERE m.muter_id = %[2]s AND m.muted_id = %[1]s
+		)`, authorCol, viewerParam)
+}
+
+// nullableID maps an empty ID to NULL so it can be bound to a uuid column.
+func nullableID(id string) interface{} {
+	if id == "" {
+		return nil
+	}
+	return id
+}
+
+func (r *ModerationRepository) Block(blockerID, blockedID string) error {
+	query := `
+		INSERT INTO blocks (blocker_id, blocked_id, created_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (blocker_id, blocked_id) DO NOTHING
+	`
+	_, err := r.db.Exec(query, blockerID, blockedID, time.Now())
+	return err
+}
+
+func (r *ModerationRepository) Unblock(blockerID, blockedID string) error {
+	query := `DELETE FROM blocks WHERE blocker_id = $1 AND blocked_id = $2`
+	_, err := r.db.Exec(query, blockerID, blockedID)
+	return err
+}
+
+func (r *ModerationRepository) GetBlocks(blockerID string) ([]*models.Block, error) {
+	query := `
+		SELECT blocker_id, blocked_id, created_at
+		FROM blocks
+		WHERE blocker_id = $1
+		ORDER BY created_at DESC
+	`
+	rows, err := r.db.Query(query, blockerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	blocks := []*models.Block{}
+	for rows.Next() {
+		block := &models.Block{}
+		if err := rows.Scan(&block.BlockerID, &block.BlockedID, &block.CreatedAt); err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks, nil
+}
+
+func (r *ModerationRepository) Mute(muterID, mutedID string) error {
+	query := `
+		INSERT INTO mutes (muter_id, muted_id, created_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (muter_id, muted_id) DO NOTHING
+	`
+	_, err := r.db.Exec(query, muterID, mutedID, time.Now())
+	return err
+}
+
+func (r *ModerationRepository) Unmute(muterID, mutedID string) error {
+	query := `DELETE FROM mutes WHERE muter_id = $1 AND muted_id = $2`
+	_, err := r.db.Exec(query, muterID, mutedID)
+	return err
+}
+
+func (r *ModerationRepository) GetMutes(muterID string) ([]*models.Mute, error) {
+	query := `
+		SELECT muter_id, muted_id, created_at
+		FROM mutes
+		WHERE muter_id = $1
+		ORDER BY created_at DESC
+	`
+	rows, err := r.db.Query(query, muterID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	mutes := []*models.Mute{}
+	for rows.Next() {
+		mute := &models.Mute{}
+		if err := rows.Scan(&mute.MuterID, &mute.MutedID, &mute.CreatedAt); err != nil {
+			return nil, err
+		}
+		mutes = append(mutes, mute)
+	}
+	return mutes, nil
+}
+
+func (r *ModerationRepository) CreateReport(report *models.Report) error {
+	query := `
+		INSERT INTO reports (id, reporter_id, target_type, target_id, reason, status, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	_, err := r.db.Exec(query,
+		report.ID, report.ReporterID, report.TargetType, report.TargetID, report.Reason, report.Status, report.CreatedAt,
+	)
+	return err
+}
+
+func (r *ModerationRepository) GetReportByID(id string) (*models.Report, error) {
+	report := &models.Report{}
+	query := `
+		SELECT id, reporter_id, target_type, target_id, reason, status, resolved_by, resolved_at, created_at
+		FROM reports
+		WHERE id = $1
+	`
+	err := r.db.QueryRow(query, id).Scan(
+		&report.ID, &report.ReporterID, &report.TargetType, &report.TargetID, &report.Reason, &report.Status,
+		&report.ResolvedBy, &report.ResolvedAt, &report.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// GetReports returns the moderation queue, oldest first, optionally filtered by status.
+func (r *ModerationRepository) GetReports(status string, limit int) ([]*models.Report, error) {
+	query := `
+		SELECT id, reporter_id, target_type, target_id, reason, status, resolved_by, resolved_at, created_at
+		FROM reports
+		WHERE ($1 = '' OR status = $1)
+		ORDER BY created_at ASC
+		LIMIT $2
+	`
+	rows, err := r.db.Query(query, status, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reports := []*models.Report{}
+	for rows.Next() {
+		report := &models.Report{}
+		err := rows.Scan(
+			&report.ID, &report.ReporterID, &report.TargetType, &report.TargetID, &report.Reason, &report.Status,
+			&report.ResolvedBy, &report.ResolvedAt, &report.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+// ResolveReports sets the status of every report against the same target, so
+// duplicate reports leave the queue together.
+func (r *ModerationRepository) ResolveReports(targetType, targetID, status, resolvedBy string) error {
+	query := `
+		UPDATE reports
+		SET status = $1, resolved_by = $2, resolved_at = $3
+		WHERE target_type = $4 AND target_id = $5
+	`
+	_, err := r.db.Exec(query, status, resolvedBy, time.Now(), targetType, targetID)
+	return err
+}
+
+// SetHidden hides or restores a reported feed post or comment.
+func (r *ModerationRepository) SetHidden(targetType, targetID string, hidden bool) error {
+	var query string
+	switch targetType {
+	case models.ReportTargetFeed:
+		query = `UPDATE feeds SET hidden = $1 WHERE id = $2`
+	case models.ReportTargetComment:
+		query = `UPDATE comments SET hidden = $1 WHERE id = $2`
+	default:
+		return errors.New("invalid report target type")
+	}
+	_, err := r.db.Exec(query, hidden, targetID)
+	return err
+}
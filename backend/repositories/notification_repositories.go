@@ -0,0 +1,68 @@
+package repositories
+
+import (
+	"DoToday/models"
+	"database/sql"
+	"time"
+)
+
+type NotificationRepository struct {
+	db *sql.DB
+}
+
+func NewNotificationRepository(db *sql.DB) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+// Create stores a notification. Notifications tied to a day are only stored
+// once per recipient, kind and goal.
+func (r *NotificationRepository) Create(notification *models.Notification) error {
+	query := `
+		INSERT INTO notifications (id, user_id, kind, goal_id, message, ref_date, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (user_id, kind, goal_id, ref_date) DO NOTHING
+	`
+	_, err := r.db.Exec(query,
+		notification.ID, notification.UserID, notification.Kind, notification.GoalID, notification.Message,
+		notification.RefDate, notification.CreatedAt,
+	)
+	return err
+}
+
+func (r *NotificationRepository) GetByUserID(userID string, unreadOnly bool, limit int) ([]*models.Notification, error) {
+	query := `
+		SELECT id, user_id, kind, goal_id, message, ref_date, read_at, created_at
+		FROM notifications
+		WHERE user_id = $1 AND ($2 = false OR read_at IS NULL)
+		ORDER BY created_at DESC
+		LIMIT $3
+	`
+	rows, err := r.db.Query(query, userID, unreadOnly, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notifications := []*models.Notification{}
+	for rows.Next() {
+		n := &models.Notification{}
+		err := rows.Scan(&n.ID, &n.UserID, &n.Kind, &n.GoalID, &n.Message, &n.RefDate, &n.ReadAt, &n.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, n)
+	}
+	return notifications, nil
+}
+
+func (r *NotificationRepository) MarkRead(id, userID string) error {
+	query := `UPDATE notifications SET read_at = $1 WHERE id = $2 AND user_id = $3 AND read_at IS NULL`
+	_, err := r.db.Exec(query, time.Now(), id, userID)
+	return err
+}
+
+func (r *NotificationRepository) MarkAllRead(userID string) error {
+	query := `UPDATE notifications SET read_at = $1 WHERE user_id = $2 AND read_at IS NULL`
+	_, err := r.db.Exec(query, time.Now(), userID)
+	return err
+}
@@ -0,0 +1,237 @@
+package repositories
+
+import (
+	"DoToday/models"
+	"database/sql"
+	"time"
+)
+
+type OrganizationRepository struct {
+	db *sql.DB
+}
+
+func NewOrganizationRepository(db *sql.DB) *OrganizationRepository {
+	return &OrganizationRepository{db: db}
+}
+
+// Create stores the organization and makes its owner the first member.
+func (r *OrganizationRepository) Create(org *models.Organization) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO organizations (id, name, owner_id, created_at)
+		VALUES ($1, $2, $3, $4)
+	`, org.ID, org.Name, org.OwnerID, org.CreatedAt)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`
+		INSERT INTO organization_members (organization_id, user_id, role, joined_at)
+		VALUES ($1, $2, $3, $4)
+	`, org.ID, org.OwnerID, models.OrgRoleOwner, org.CreatedAt)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *OrganizationRepository) GetByID(id string) (*models.Organization, error) {
+	org := &models.Organization{}
+	query := `
+		SELECT id, name, owner_id, created_at
+		FROM organizations
+		WHERE id = $1
+	`
+	err := r.db.QueryRow(query, id).Scan(&org.ID, &org.Name, &org.OwnerID, &org.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return org, nil
+}
+
+// GetByUserID returns the organizations the user belongs to, with the user's role.
+func (r *OrganizationRepository) GetByUserID(userID string) ([]*models.Organization, error) {
+	query := `
+		SELECT o.id, o.name, o.owner_id, m.role, o.created_at
+		FROM organizations o
+		JOIN organization_members m ON m.organization_id = o.id
+		WHERE m.user_id = $1
+		ORDER BY o.name ASC
+	`
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	orgs := []*models.Organization{}
+	for rows.Next() {
+		org := &models.Organization{}
+		if err := rows.Scan(&org.ID, &org.Name, &org.OwnerID, &org.Role, &org.CreatedAt); err != nil {
+			return nil, err
+		}
+		orgs = append(orgs, org)
+	}
+	return orgs, nil
+}
+
+// GetMemberRole returns the user's role, or sql.ErrNoRows when they are not a member.
+func (r *OrganizationRepository) GetMemberRole(orgID, userID string) (string, error) {
+	var role string
+	query := `SELECT role FROM organization_members WHERE organization_id = $1 AND user_id = $2`
+	err := r.db.QueryRow(query, orgID, userID).Scan(&role)
+	return role, err
+}
+
+func (r *OrganizationRepository) GetMembers(orgID string) ([]*models.OrganizationMember, error) {
+	query := `
+		SELECT m.organization_id, m.user_id, p.username, m.role, m.joined_at
+		FROM organization_members m
+		JOIN profiles p ON p.id = m.user_id
+		WHERE m.organization_id = $1
+		ORDER BY m.joined_at ASC
+	`
+	rows, err := r.db.Query(query, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := []*models.OrganizationMember{}
+	for rows.Next() {
+		m := &models.OrganizationMember{}
+		if err := rows.Scan(&m.OrganizationID, &m.UserID, &m.Username, &m.Role, &m.JoinedAt); err != nil {
+			return nil, err
+		}
+		members = append(members, m)
+	}
+	return members, nil
+}
+
+func (r *OrganizationRepository) AddMember(member *models.OrganizationMember) error {
+	query := `
+		INSERT INTO organization_members (organization_id, user_id, role, joined_at)
+		VALUES ($1, $2, $3, $4)
+	`
+	_, err := r.db.Exec(query, member.OrganizationID, member.UserID, member.Role, member.JoinedAt)
+	return err
+}
+
+func (r *OrganizationRepository) UpdateMemberRole(orgID, userID, role string) error {
+	query := `UPDATE organization_members SET role = $1 WHERE organization_id = $2 AND user_id = $3`
+	_, err := r.db.Exec(query, role, orgID, userID)
+	return err
+}
+
+// RemoveMember drops the membership along with the member's team goal links.
+func (r *OrganizationRepository) RemoveMember(orgID, userID string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		DELETE FROM organization_goals og
+		USING goals g
+		WHERE og.goal_id = g.id AND og.organization_id = $1 AND g.user_id = $2
+	`, orgID, userID)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`DELETE FROM organization_members WHERE organization_id = $1 AND user_id = $2`, orgID, userID)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *OrganizationRepository) AddGoal(orgID, goalID string) error {
+	query := `
+		INSERT INTO organization_goals (organization_id, goal_id, created_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (organization_id, goal_id) DO NOTHING
+	`
+	_, err := r.db.Exec(query, orgID, goalID, time.Now())
+	return err
+}
+
+func (r *OrganizationRepository) RemoveGoal(orgID, goalID string) error {
+	query := `DELETE FROM organization_goals WHERE organization_id = $1 AND goal_id = $2`
+	_, err := r.db.Exec(query, orgID, goalID)
+	return err
+}
+
+// GetGoals returns the active goals members have made visible to the team.
+func (r *OrganizationRepository) GetGoals(orgID string) ([]*models.Goal, error) {
+	query := `
+		SELECT ` + goalColumns + `
+		FROM goals g
+		JOIN organization_goals og ON og.goal_id = g.id
+		WHERE og.organization_id = $1 AND g.archived = false AND g.deleted_at IS NULL
+		ORDER BY g.created_at DESC
+	`
+	return queryGoals(r.db, query, orgID)
+}
+
+// IsGoalVisibleToMember reports whether the goal is shared with a team the
+// user belongs to.
+func (r *OrganizationRepository) IsGoalVisibleToMember(goalID, userID string) (bool, error) {
+	var visible bool
+	query := `
+		SELECT EXISTS(
+			SELECT 1
+			FROM organization_goals og
+			JOIN organization_members m ON m.organization_id = og.organization_id
+			WHERE og.goal_id = $1 AND m.user_id = $2
+		)
+	`
+	err := r.db.QueryRow(query, goalID, userID).Scan(&visible)
+	return visible, err
+}
+
+// GetMemberStats joins every member with the user_stats view and counts their
+// team goals, active streaks and completions over the last days days.
+func (r *OrganizationRepository) GetMemberStats(orgID string, days int) ([]*models.TeamMemberStats, error) {
+	query := `
+		SELECT m.user_id, p.username, m.role,
+		       COALESCE(us.total_goals, 0), COALESCE(us.total_completions, 0), COALESCE(us.longest_streak, 0),
+		       COUNT(DISTINCT g.id),
+		       COUNT(DISTINCT g.id) FILTER (WHERE g.current_streak > 0),
+		       COUNT(c.id)
+		FROM organization_members m
+		JOIN profiles p ON p.id = m.user_id
+		LEFT JOIN user_stats us ON us.user_id = m.user_id
+		LEFT JOIN (
+			organization_goals og
+			JOIN goals g ON g.id = og.goal_id AND g.archived = false AND g.deleted_at IS NULL
+		) ON og.organization_id = m.organization_id AND g.user_id = m.user_id
+		LEFT JOIN completions c ON c.goal_id = g.id AND g.kind <> 'quit' AND c.date > CURRENT_DATE - $2::int
+		WHERE m.organization_id = $1
+		GROUP BY m.user_id, p.username, m.role, us.total_goals, us.total_completions, us.longest_streak
+		ORDER BY p.username ASC
+	`
+	rows, err := r.db.Query(query, orgID, days)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := []*models.TeamMemberStats{}
+	for rows.Next() {
+		s := &models.TeamMemberStats{}
+		err := rows.Scan(
+			&s.UserID, &s.Username, &s.Role, &s.TotalGoals, &s.TotalCompletions, &s.LongestStreak,
+			&s.TeamGoals, &s.ActiveStreaks, &s.WindowCompletions,
+		)
+		if err != nil {
+			return nil, err
+		}
+		stats = append(stats, s)
+	}
+	return stats, nil
+}
@@ -0,0 +1,127 @@
+package repositories
+
+import (
+	"DoToday/models"
+	"database/sql"
+	"encoding/json"
+	"time"
+)
+
+type ReviewRepository struct {
+	db *sql.DB
+}
+
+func NewReviewRepository(db *sql.DB) *ReviewRepository {
+	return &ReviewRepository{db: db}
+}
+
+const reviewColumns = `id, user_id, period, period_start, period_end, summary, created_at, emailed_at`
+
+func scanReview(row interface{ Scan(...interface{}) error }) (*models.Review, error) {
+	review := &models.Review{}
+	var summary []byte
+	err := row.Scan(
+		&review.ID, &review.UserID, &review.Period, &review.PeriodStart, &review.PeriodEnd,
+		&summary, &review.CreatedAt, &review.EmailedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(summary, &review.Summary); err != nil {
+		return nil, err
+	}
+	return review, nil
+}
+
+// Create stores the review unless the user already has one for the same
+// period and start date, reporting whether it was inserted.
+func (r *ReviewRepository) Create(review *models.Review) (bool, error) {
+	summary, err := json.Marshal(review.Summary)
+	if err != nil {
+		return false, err
+	}
+	query := `
+		INSERT INTO reviews (id, user_id, period, period_start, period_end, summary, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (user_id, period, period_start) DO NOTHING
+	`
+	res, err := r.db.Exec(query,
+		review.ID, review.UserID, review.Period, review.PeriodStart.Format("2006-01-02"),
+		review.PeriodEnd.Format("2006-01-02"), summary, review.CreatedAt,
+	)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func (r *ReviewRepository) GetByID(id string) (*models.Review, error) {
+	query := `SELECT ` + reviewColumns + ` FROM reviews WHERE id = $1`
+	return scanReview(r.db.QueryRow(query, id))
+}
+
+// GetByPeriod returns the user's review of the period starting on start.
+func (r *ReviewRepository) GetByPeriod(userID, period string, start time.Time) (*models.Review, error) {
+	query := `SELECT ` + reviewColumns + ` FROM reviews WHERE user_id = $1 AND period = $2 AND period_start = $3`
+	return scanReview(r.db.QueryRow(query, userID, period, start.Format("2006-01-02")))
+}
+
+// GetByUserID returns the user's latest reviews, optionally of one period.
+func (r *ReviewRepository) GetByUserID(userID, period string, limit int) ([]*models.Review, error) {
+	query := `
+		SELECT ` + reviewColumns + `
+		FROM reviews
+		WHERE user_id = $1 AND ($2 = '' OR period = $2)
+		ORDER BY period_start DESC, period ASC
+		LIMIT $3
+	`
+	rows, err := r.db.Query(query, userID, period, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reviews := []*models.Review{}
+	for rows.Next() {
+		review, err := scanReview(rows)
+		if err != nil {
+			return nil, err
+		}
+		reviews = append(reviews, review)
+	}
+	return reviews, nil
+}
+
+// GetUsersDue returns the users who had a goal during the period from start
+// to end but no review of it yet.
+func (r *ReviewRepository) GetUsersDue(period string, start, end time.Time) ([]string, error) {
+	query := `
+		SELECT DISTINCT g.user_id
+		FROM goals g
+		WHERE g.deleted_at IS NULL AND g.created_at::date <= $3
+		  AND NOT EXISTS (
+			SELECT 1 FROM reviews rv WHERE rv.user_id = g.user_id AND rv.period = $1 AND rv.period_start = $2
+		  )
+	`
+	rows, err := r.db.Query(query, period, start.Format("2006-01-02"), end.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var userIDs []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		userIDs = append(userIDs, id)
+	}
+	return userIDs, nil
+}
+
+func (r *ReviewRepository) MarkEmailed(id string, at time.Time) error {
+	_, err := r.db.Exec(`UPDATE reviews SET emailed_at = $1 WHERE id = $2`, at, id)
+	return err
+}
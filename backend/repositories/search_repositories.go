@@ -0,0 +1,197 @@
+package repositories
+
+import (
+	"DoToday/models"
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Markers ts_headline wraps around matched words; the service turns them into
+// <mark> tags after escaping the snippet.
+const (
+	SearchMarkStart = "\x01"
+	SearchMarkStop  = "\x02"
+)
+
+type SearchRepository struct {
+	db *sql.DB
+}
+
+func NewSearchRepository(db *sql.DB) *SearchRepository {
+	return &SearchRepository{db: db}
+}
+
+// SupportsFullText reports whether the database can run the full-text queries
+// Search uses, which need websearch_to_tsquery.
+func (r *SearchRepository) SupportsFullText() bool {
+	var ok bool
+	err := r.db.QueryRow(`SELECT to_tsvector('english', 'probe') @@ websearch_to_tsquery('english', 'probe')`).Scan(&ok)
+	return err == nil && ok
+}
+
+// searchSource describes how one result type is read. Every source joins the
+// visible_goals CTE as g.
+type searchSource struct {
+	kind   string
+	from   string
+	id     string
+	text   string
+	vector string
+	date   string
+	where  string
+}
+
+// Search finds goals, feed posts and comments the viewer can see. With
+// fullText it matches and ranks with Postgres full-text search and returns
+// highlighted snippets; otherwise every term must appear as a substring, the
+// newest limit matches are returned with their full text as the snippet and a
+// zero rank.
+func (r *SearchRepository) Search(viewerID string, q *models.SearchQuery, fullText bool, limit int) ([]*models.SearchResult, error) {
+	var args []interface{}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	viewer := arg(viewerID)
+	goalFilters := []string{
+		"g.deleted_at IS NULL",
+		fmt.Sprintf(`(
+			g.user_id = %[1]s OR g.is_public
+			OR EXISTS (SELECT 1 FROM goal_shares s WHERE s.goal_id = g.id AND s.user_id = %[1]s)
+			OR EXISTS (
+				SELECT 1
+				FROM organization_goals og
+				JOIN organization_members m ON m.organization_id = og.organization_id
+				WHERE og.goal_id = g.id AND m.user_id = %[1]s
+			)
+		)`, viewer),
+		visibleAuthorClause("g.user_id", viewer),
+	}
+	if q.Tag != "" {
+		goalFilters = append(goalFilters, "EXISTS (SELECT 1 FROM goal_tags t WHERE t.goal_id = g.id AND t.tag = "+arg(q.Tag)+")")
+	}
+	if q.CategoryID != "" {
+		goalFilters = append(goalFilters, "g.category_id = "+arg(q.CategoryID))
+	}
+
+	sources := []searchSource{
+		{
+			kind:   models.SearchTypeGoal,
+			from:   "visible_goals g",
+			id:     "g.id",
+			text:   "concat_ws(': ', g.title, NULLIF(g.description, ''))",
+			vector: "g.search_vector",
+			date:   "g.created_at",
+			where:  "true",
+		},
+		{
+			kind:   models.SearchTypeFeed,
+			from:   "feeds f JOIN visible_goals g ON g.id = f.goal_id",
+			id:     "f.id",
+			text:   "f.description",
+			vector: "f.search_vector",
+			date:   "f.date",
+			where:  "f.hidden = false",
+		},
+		{
+			kind:   models.SearchTypeComment,
+			from:   "comments c JOIN feeds f ON f.id = c.feed_id JOIN visible_goals g ON g.id = f.goal_id",
+			id:     "c.id",
+			text:   "c.content",
+			vector: "c.search_vector",
+			date:   "c.created_at",
+			where:  "c.hidden = false AND f.hidden = false AND " + visibleAuthorClause("c.user_id", viewer),
+		},
+	}
+
+	var tsQuery, headlineOptions string
+	if fullText {
+		tsQuery = arg(q.Text)
+		headlineOptions = arg("StartSel=" + SearchMarkStart + ", StopSel=" + SearchMarkStop + ", MaxFragments=2, MaxWords=25, MinWords=8")
+	}
+	var dateFilters []string
+	if q.From != nil {
+		dateFilters = append(dateFilters, "%[1]s::date >= "+arg(q.From.Format("2006-01-02"))+"::date")
+	}
+	if q.To != nil {
+		dateFilters = append(dateFilters, "%[1]s::date <= "+arg(q.To.Format("2006-01-02"))+"::date")
+	}
+	var termPatterns []string
+	if !fullText {
+		for _, term := range q.Terms {
+			termPatterns = append(termPatterns, arg("%"+escapeLike(term)+"%"))
+		}
+	}
+
+	var parts []string
+	for _, source := range sources {
+		if !slices.Contains(q.Types, source.kind) {
+			continue
+		}
+
+		conditions := []string{source.where}
+		for _, filter := range dateFilters {
+			conditions = append(conditions, fmt.Sprintf(filter, source.date))
+		}
+		from := source.from
+		var snippet, rank string
+		if fullText {
+			from += ", q"
+			conditions = append(conditions, source.vector+" @@ q.query")
+			snippet = fmt.Sprintf("ts_headline('english', %s, q.query, %s)", source.text, headlineOptions)
+			rank = fmt.Sprintf("ts_rank(%s, q.query)::float8", source.vector)
+		} else {
+			for _, pattern := range termPatterns {
+				conditions = append(conditions, source.text+" ILIKE "+pattern)
+			}
+			snippet = source.text
+			rank = "0::float8"
+		}
+
+		parts = append(parts, fmt.Sprintf(`
+			SELECT '%s' AS kind, %s AS id, g.id AS goal_id, g.title AS goal_title, %s AS snippet, %s AS rank,
+			       %s::timestamptz AS date
+			FROM %s
+			WHERE %s`,
+			source.kind, source.id, snippet, rank, source.date, from, strings.Join(conditions, " AND "),
+		))
+	}
+	if len(parts) == 0 {
+		return nil, nil
+	}
+
+	with := "WITH visible_goals AS (SELECT g.* FROM goals g WHERE " + strings.Join(goalFilters, " AND ") + ")"
+	if fullText {
+		with += ", q AS (SELECT websearch_to_tsquery('english', " + tsQuery + ") AS query)"
+	}
+	query := with + `
+		SELECT kind, id, goal_id, goal_title, snippet, rank, date
+		FROM (` + strings.Join(parts, " UNION ALL ") + `) results
+		ORDER BY rank DESC, date DESC
+		LIMIT ` + arg(limit)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := []*models.SearchResult{}
+	for rows.Next() {
+		result := &models.SearchResult{}
+		err := rows.Scan(&result.Type, &result.ID, &result.GoalID, &result.GoalTitle, &result.Snippet, &result.Rank, &result.Date)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// escapeLike escapes the LIKE wildcards in s.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -18,22 +18,23 @@ func NewUserRepository(db *sql.DB) *UserRepository {
 
 func (r *UserRepository) Create(profile *models.Profile) error {
 	query := `
-	       INSERT INTO profiles (id, username, email, created_at)
-	       VALUES ($1, $2, $3, $4)
+	       INSERT INTO profiles (id, username, email, role, created_at)
+	       VALUES ($1, $2, $3, $4, $5)
        `
-	_, err := r.db.Exec(query, profile.ID, profile.Username, profile.Email, profile.CreatedAt)
+	_, err := r.db.Exec(query, profile.ID, profile.Username, profile.Email, profile.Role, profile.CreatedAt)
 	return err
 }
 
 func (r *UserRepository) GetByID(id string) (*models.Profile, error) {
 	profile := &models.Profile{}
 	query := `
-	       SELECT id, username, email, created_at
+	       SELECT id, username, email, role, share_achievements, review_emails, created_at
 	       FROM profiles
 	       WHERE id = $1
        `
 	err := r.db.QueryRow(query, id).Scan(
-		&profile.ID, &profile.Username, &profile.Email, &profile.CreatedAt,
+		&profile.ID, &profile.Username, &profile.Email, &profile.Role, &profile.ShareAchievements, &profile.ReviewEmails,
+		&profile.CreatedAt,
 	)
 	if err != nil {
 		return nil, err
@@ -44,12 +45,13 @@ func (r *UserRepository) GetByID(id string) (*models.Profile, error) {
 func (r *UserRepository) GetByUsername(username string) (*models.Profile, error) {
 	profile := &models.Profile{}
 	query := `
-	       SELECT id, username, email, created_at
+	       SELECT id, username, email, role, share_achievements, review_emails, created_at
 	       FROM profiles
 	       WHERE username = $1
        `
 	err := r.db.QueryRow(query, username).Scan(
-		&profile.ID, &profile.Username, &profile.Email, &profile.CreatedAt,
+		&profile.ID, &profile.Username, &profile.Email, &profile.Role, &profile.ShareAchievements, &profile.ReviewEmails,
+		&profile.CreatedAt,
 	)
 	if err != nil {
 		return nil, err
@@ -67,6 +69,26 @@ func (r *UserRepository) UpdateUsername(id string, username string) error {
 	return err
 }
 
+func (r *UserRepository) UpdateShareAchievements(id string, share bool) error {
+	query := `
+	       UPDATE profiles
+	       SET share_achievements = $1
+	       WHERE id = $2
+       `
+	_, err := r.db.Exec(query, share, id)
+	return err
+}
+
+func (r *UserRepository) UpdateReviewEmails(id string, enabled bool) error {
+	query := `
+	       UPDATE profiles
+	       SET review_emails = $1
+	       WHERE id = $2
+       `
+	_, err := r.db.Exec(query, enabled, id)
+	return err
+}
+
 func (r *UserRepository) GetStats(id uuid.UUID) (*models.UserStats, error) {
 	stats := &models.UserStats{}
 	query := `
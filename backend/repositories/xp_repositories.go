@@ -0,0 +1,127 @@
+package repositories
+
+import (
+	"DoToday/models"
+	"database/sql"
+	"time"
+)
+
+type XPRepository struct {
+	db *sql.DB
+}
+
+func NewXPRepository(db *sql.DB) *XPRepository {
+	return &XPRepository{db: db}
+}
+
+// Append adds an entry to the ledger. Entries with a ref are only recorded
+// once, so replaying the same event does not award points twice.
+func (r *XPRepository) Append(entry *models.XPEntry) error {
+	query := `
+		INSERT INTO xp_ledger (id, user_id, amount, reason, ref, goal_id, completion_id, reverses, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT DO NOTHING
+	`
+	_, err := r.db.Exec(query,
+		entry.ID, entry.UserID, entry.Amount, entry.Reason, entry.Ref, entry.GoalID, entry.CompletionID,
+		entry.Reverses, entry.CreatedAt,
+	)
+	return err
+}
+
+// ReverseCompletion appends a negative entry for every unreversed entry
+// earned by the completion.
+func (r *XPRepository) ReverseCompletion(completionID string) error {
+	return r.reverse(`l.completion_id = $1`, completionID)
+}
+
+// ReverseGoal appends a negative entry for every unreversed entry earned by the goal.
+func (r *XPRepository) ReverseGoal(goalID string) error {
+	return r.reverse(`l.goal_id = $1`, goalID)
+}
+
+// ReverseRef appends a negative entry for the unreversed entry recorded under ref.
+func (r *XPRepository) ReverseRef(ref string) error {
+	return r.reverse(`l.ref = $1`, ref)
+}
+
+func (r *XPRepository) reverse(condition string, arg string) error {
+	query := `
+		INSERT INTO xp_ledger (id, user_id, amount, reason, goal_id, completion_id, reverses, created_at)
+		SELECT gen_random_uuid(), l.user_id, -l.amount, 'reversal', l.goal_id, l.completion_id, l.id, $2
+		FROM xp_ledger l
+		WHERE ` + condition + `
+		  AND l.reverses IS NULL
+		  AND NOT EXISTS (SELECT 1 FROM xp_ledger r WHERE r.reverses = l.id)
+	`
+	_, err := r.db.Exec(query, arg, time.Now())
+	return err
+}
+
+func (r *XPRepository) GetTotal(userID string) (int, error) {
+	var total int
+	query := `SELECT COALESCE(SUM(amount), 0) FROM xp_ledger WHERE user_id = $1`
+	err := r.db.QueryRow(query, userID).Scan(&total)
+	return total, err
+}
+
+func (r *XPRepository) GetHistory(userID string, limit int) ([]*models.XPEntry, error) {
+	query := `
+		SELECT id, user_id, amount, reason, goal_id, completion_id, reverses, created_at
+		FROM xp_ledger
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2
+	`
+	rows, err := r.db.Query(query, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []*models.XPEntry{}
+	for rows.Next() {
+		e := &models.XPEntry{}
+		err := rows.Scan(&e.ID, &e.UserID, &e.Amount, &e.Reason, &e.GoalID, &e.CompletionID, &e.Reverses, &e.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// GetWeeklyLeaderboard sums XP since the given time for the user and everyone
+// they follow, along with each user's total XP.
+func (r *XPRepository) GetWeeklyLeaderboard(userID string, since time.Time) ([]*models.XPLeaderboardEntry, error) {
+	query := `
+		WITH circle AS (
+			SELECT $1::uuid AS user_id
+			UNION
+			SELECT followee_id FROM follows WHERE follower_id = $1
+		)
+		SELECT p.id, p.username,
+		       COALESCE(SUM(l.amount) FILTER (WHERE l.created_at >= $2), 0) AS weekly_xp,
+		       COALESCE(SUM(l.amount), 0) AS total_xp
+		FROM circle c
+		JOIN profiles p ON p.id = c.user_id
+		LEFT JOIN xp_ledger l ON l.user_id = c.user_id
+		GROUP BY p.id, p.username
+		ORDER BY weekly_xp DESC, p.username ASC
+	`
+	rows, err := r.db.Query(query, userID, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []*models.XPLeaderboardEntry{}
+	for rows.Next() {
+		e := &models.XPLeaderboardEntry{}
+		if err := rows.Scan(&e.UserID, &e.Username, &e.WeeklyXP, &e.TotalXP); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"database/sql"
+	"log"
+	"time"
+
+	"DoToday/config"
+	"DoToday/models"
+	"DoToday/repositories"
+
+	"github.com/google/uuid"
+)
+
+type AccountService struct {
+	repo        *repositories.AccountRepository
+	authService *AuthService
+	cfg         *config.AccountConfig
+}
+
+func NewAccountService(
+	repo *repositories.AccountRepository,
+	authService *AuthService,
+	cfg *config.AccountConfig,
+) *AccountService {
+	return &AccountService{
+		repo:        repo,
+		authService: authService,
+		cfg:         cfg,
+	}
+}
+
+// RequestDeletion schedules the user's account for erasure once the grace
+// period has passed. The request can be cancelled until then.
+func (s *AccountService) RequestDeletion(userID string) (*models.AccountDeletion, error) {
+	if _, err := s.repo.GetPendingDeletion(userID); err == nil {
+		return nil, models.Conflict("deletion already requested")
+	} else if err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	now := time.Now()
+	deletion := &models.AccountDeletion{
+		ID:           uuid.NewString(),
+		UserID:       userID,
+		Status:       models.DeletionStatusPending,
+		RequestedAt:  now,
+		ScheduledFor: now.Add(s.cfg.DeletionGracePeriod),
+	}
+	if err := s.repo.CreateDeletion(deletion); err != nil {
+		return nil, err
+	}
+	return deletion, nil
+}
+
+func (s *AccountService) GetPendingDeletion(userID string) (*models.AccountDeletion, error) {
+	deletion, err := s.repo.GetPendingDeletion(userID)
+	if err == sql.ErrNoRows {
+		return nil, models.NotFound("no pending deletion")
+	}
+	return deletion, err
+}
+
+func (s *AccountService) CancelDeletion(userID string) error {
+	deletion, err := s.GetPendingDeletion(userID)
+	if err != nil {
+		return err
+	}
+	return s.repo.CancelDeletion(deletion.ID)
+}
+
+// ProcessDueDeletions erases every account whose grace period has ended.
+// Failures are logged and retried on the next run.
+func (s *AccountService) ProcessDueDeletions() error {
+	deletions, err := s.repo.GetDueDeletions(time.Now())
+	if err != nil {
+		return err
+	}
+	for _, deletion := range deletions {
+		if err := s.erase(deletion); err != nil {
+			log.Printf("erasing account %s failed: %v", deletion.UserID, err)
+		}
+	}
+	return nil
+}
+
+func (s *AccountService) erase(deletion *models.AccountDeletion) error {
+	if err := s.repo.EraseUser(deletion); err != nil {
+		return err
+	}
+
+	// The profile is gone at this point, so a Supabase failure only leaves
+	// auth_user_deleted false on the audit record for follow-up.
+	deleted, err := s.authService.DeleteAuthUser(deletion.UserID)
+	if err != nil {
+		log.Printf("deleting auth user %s failed: %v", deletion.UserID, err)
+		return nil
+	}
+	if deleted {
+		return s.repo.SetAuthUserDeleted(deletion.ID)
+	}
+	return nil
+}
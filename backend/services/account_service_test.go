@@ -0,0 +1,55 @@
+package services
+
+import (
+	"database/sql/driver"
+	"strings"
+	"testing"
+	"time"
+
+	"DoToday/config"
+	"DoToday/models"
+	"DoToday/repositories"
+)
+
+func TestErasureHandsOwnedTeamsAndChallengesOverBeforeDeletingTheProfile(t *testing.T) {
+	t.Setenv("SUPABASE_URL", "")
+	db, fake := openFakeDB(t)
+	due := time.Now().Add(-time.Hour)
+	fake.on(`FROM account_deletions WHERE status = $1 AND scheduled_for <= $2`, []driver.Value{
+		"deletion-1", testUserID, models.DeletionStatusPending, due.AddDate(0, 0, -30), due, nil, nil,
+		int64(0), int64(0), int64(0), int64(0), int64(0), int64(0), false,
+	})
+	service := NewAccountService(
+		repositories.NewAccountRepository(db),
+		NewAuthService(repositories.NewUserRepository(db), nil),
+		&config.AccountConfig{},
+	)
+
+	if err := service.ProcessDueDeletions(); err != nil {
+		t.Fatal(err)
+	}
+
+	order := map[string]int{}
+	for i, e := range fake.execs {
+		for _, stmt := range []string{"UPDATE organizations o", "UPDATE organization_members m", "UPDATE challenges c", "DELETE FROM profiles"} {
+			if strings.HasPrefix(e.query, stmt) {
+				order[stmt] = i
+				if e.args[0] != testUserID {
+					t.Errorf("%s ran for %v, want %s", stmt, e.args[0], testUserID)
+				}
+			}
+		}
+	}
+	profile, ok := order["DELETE FROM profiles"]
+	if !ok {
+		t.Fatal("profile was not deleted")
+	}
+	for _, stmt := range []string{"UPDATE organizations o", "UPDATE organization_members m", "UPDATE challenges c"} {
+		if i, ok := order[stmt]; !ok || i > profile {
+			t.Errorf("%q must run before the profile is deleted", stmt)
+		}
+	}
+	if len(fake.ran(`UPDATE account_deletions SET status = $1, completed_at = $2`)) != 1 {
+		t.Error("deletion was not marked completed")
+	}
+}
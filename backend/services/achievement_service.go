@@ -0,0 +1,225 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"DoToday/models"
+	"DoToday/repositories"
+
+	"github.com/google/uuid"
+)
+
+// AchievementContext is what achievement rules are evaluated against.
+type AchievementContext struct {
+	UserID   string
+	Goal     *models.Goal
+	Streak   int
+	Activity *repositories.UserActivity
+	Today    time.Time
+}
+
+// AchievementRule awards a badge when Earned returns true.
+type AchievementRule struct {
+	Code        string                             `json:"code"`
+	Title       string                             `json:"title"`
+	Description string                             `json:"description"`
+	Earned      func(ctx *AchievementContext) bool `json:"-"`
+}
+
+func streakRule(days int) AchievementRule {
+	return AchievementRule{
+		Code:        fmt.Sprintf("streak_%d", days),
+		Title:       fmt.Sprintf("%d-day streak", days),
+		Description: fmt.Sprintf("Completed a goal %d days in a row", days),
+		Earned:      func(ctx *AchievementContext) bool { return ctx.Streak >= days },
+	}
+}
+
+func completionsRule(n int) AchievementRule {
+	return AchievementRule{
+		Code:        fmt.Sprintf("completions_%d", n),
+		Title:       fmt.Sprintf("%d completions", n),
+		Description: fmt.Sprintf("Logged %d completions in total", n),
+		Earned:      func(ctx *AchievementContext) bool { return ctx.Activity.TotalCompletions >= n },
+	}
+}
+
+// comebackGap is how long a user must have been away for a comeback badge.
+const comebackGap = 7 * 24 * time.Hour
+
+// DefaultAchievementRules is the built-in badge catalog.
+var DefaultAchievementRules = []AchievementRule{
+	streakRule(7),
+	streakRule(30),
+	streakRule(100),
+	streakRule(365),
+	{
+		Code:        "first_public_goal",
+		Title:       "Going public",
+		Description: "Shared a goal publicly for the first time",
+		Earned:      func(ctx *AchievementContext) bool { return ctx.Activity.PublicGoals > 0 },
+	},
+	completionsRule(10),
+	completionsRule(50),
+	completionsRule(100),
+	completionsRule(500),
+	{
+		Code:        "comeback",
+		Title:       "Comeback",
+		Description: "Got back on track after a week or more away",
+		Earned: func(ctx *AchievementContext) bool {
+			prev := ctx.Activity.PreviousCompletion
+			return prev != nil && ctx.Today.Sub(*prev) >= comebackGap
+		},
+	},
+}
+
+type AchievementService struct {
+	repo     *repositories.AchievementRepository
+	userRepo *repositories.UserRepository
+	feedRepo *repositories.FeedRepository
+	rules    []AchievementRule
+	catalog  map[string]AchievementRule
+}
+
+func NewAchievementService(
+	repo *repositories.AchievementRepository,
+	userRepo *repositories.UserRepository,
+	feedRepo *repositories.FeedRepository,
+	rules []AchievementRule,
+) *AchievementService {
+	catalog := make(map[string]AchievementRule, len(rules))
+	for _, rule := range rules {
+		catalog[rule.Code] = rule
+	}
+	return &AchievementService{
+		repo:     repo,
+		userRepo: userRepo,
+		feedRepo: feedRepo,
+		rules:    rules,
+		catalog:  catalog,
+	}
+}
+
+func (s *AchievementService) GoalCreated(goal *models.Goal) error {
+	_, err := s.Evaluate(goal, 0)
+	return err
+}
+
+func (s *AchievementService) GoalCompleted(goal *models.Goal, completion *models.Completion, streak int) error {
+	_, err := s.Evaluate(goal, streak)
+	return err
+}
+
+// Achievements are kept once earned, even if the completion is undone.
+func (s *AchievementService) CompletionRemoved(goal *models.Goal, completion *models.Completion) error {
+	return nil
+}
+
+func (s *AchievementService) GoalChanged(goal *models.Goal) error {
+	return nil
+}
+
+func (s *AchievementService) GoalTrashed(goal *models.Goal) error {
+	return nil
+}
+
+func (s *AchievementService) GoalRestored(goal *models.Goal) error {
+	return nil
+}
+
+func (s *AchievementService) GoalDeleted(goal *models.Goal) error {
+	return nil
+}
+
+// Evaluate runs every rule for the goal's owner and stores the badges they
+// have newly earned. New badges are posted to the goal's feed when the user
+// opted in and the goal is public.
+func (s *AchievementService) Evaluate(goal *models.Goal, streak int) ([]*models.UserAchievement, error) {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	activity, err := s.repo.GetUserActivity(goal.UserID, today)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := &AchievementContext{
+		UserID:   goal.UserID,
+		Goal:     goal,
+		Streak:   streak,
+		Activity: activity,
+		Today:    today,
+	}
+
+	var awarded []*models.UserAchievement
+	for _, rule := range s.rules {
+		if !rule.Earned(ctx) {
+			continue
+		}
+		goalID := goal.ID
+		achievement := &models.UserAchievement{
+			UserID:      goal.UserID,
+			Code:        rule.Code,
+			Title:       rule.Title,
+			Description: rule.Description,
+			GoalID:      &goalID,
+			AwardedAt:   time.Now(),
+		}
+		isNew, err := s.repo.Award(achievement)
+		if err != nil {
+			return nil, err
+		}
+		if isNew {
+			awarded = append(awarded, achievement)
+		}
+	}
+
+	if len(awarded) > 0 && goal.IsPublic {
+		if err := s.postToFeed(goal, awarded, today); err != nil {
+			return nil, err
+		}
+	}
+	return awarded, nil
+}
+
+func (s *AchievementService) postToFeed(goal *models.Goal, awarded []*models.UserAchievement, today time.Time) error {
+	profile, err := s.userRepo.GetByID(goal.UserID)
+	if err != nil {
+		return err
+	}
+	if !profile.ShareAchievements {
+		return nil
+	}
+
+	description := "Unlocked achievement: " + awarded[0].Title
+	for _, a := range awarded[1:] {
+		description += ", " + a.Title
+	}
+	_, err = s.feedRepo.Create(&models.Feed{
+		ID:          uuid.NewString(),
+		GoalID:      goal.ID,
+		Date:        &today,
+		Description: description,
+	})
+	return err
+}
+
+// GetUserAchievements returns the user's badges with their catalog titles.
+func (s *AchievementService) GetUserAchievements(userID string) ([]*models.UserAchievement, error) {
+	achievements, err := s.repo.GetByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range achievements {
+		if rule, ok := s.catalog[a.Code]; ok {
+			a.Title = rule.Title
+			a.Description = rule.Description
+		}
+	}
+	return achievements, nil
+}
+
+// GetCatalog lists every achievement that can be earned.
+func (s *AchievementService) GetCatalog() []AchievementRule {
+	return s.rules
+}
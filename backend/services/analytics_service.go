@@ -0,0 +1,301 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"DoToday/config"
+	"DoToday/models"
+	"DoToday/repositories"
+)
+
+// analyticsDays is the default analytics window; windows are capped at a year.
+const (
+	analyticsDays    = 30
+	analyticsMaxDays = 365
+)
+
+type analyticsEntry struct {
+	analytics *models.UserAnalytics
+	expires   time.Time
+}
+
+// AnalyticsService computes a user's analytics and caches them per window and
+// timezone for the configured TTL. Every goal event, from completions to
+// edits, archiving and the trash, drops the user's cached analytics.
+type AnalyticsService struct {
+	repo           *repositories.AnalyticsRepository
+	goalRepo       *repositories.GoalRepository
+	completionRepo *repositories.CompletionRepository
+	goalService    *GoalService
+	config         *config.AnalyticsConfig
+
+	mu    sync.Mutex
+	cache map[string]map[string]analyticsEntry
+}
+
+func NewAnalyticsService(
+	repo *repositories.AnalyticsRepository,
+	goalRepo *repositories.GoalRepository,
+	completionRepo *repositories.CompletionRepository,
+	goalService *GoalService,
+	config *config.AnalyticsConfig,
+) *AnalyticsService {
+	return &AnalyticsService{
+		repo:           repo,
+		goalRepo:       goalRepo,
+		completionRepo: completionRepo,
+		goalService:    goalService,
+		config:         config,
+		cache:          make(map[string]map[string]analyticsEntry),
+	}
+}
+
+// GetAnalytics returns the user's analytics over the last days days, with
+// completion times in timezone (an IANA name, UTC when empty).
+func (s *AnalyticsService) GetAnalytics(userID string, days int, timezone string) (*models.UserAnalytics, error) {
+	if days <= 0 || days > analyticsMaxDays {
+		days = analyticsDays
+	}
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, models.InvalidFields("unknown timezone", models.FieldError{Field: "tz", Message: "unknown timezone"})
+	}
+
+	key := fmt.Sprintf("%d|%s", days, timezone)
+	if analytics := s.cached(userID, key); analytics != nil {
+		return analytics, nil
+	}
+
+	y, m, d := time.Now().In(loc).Date()
+	to := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	from := to.AddDate(0, 0, -(days - 1))
+	analytics := &models.UserAnalytics{
+		Days:        days,
+		From:        from,
+		To:          to,
+		Timezone:    timezone,
+		GeneratedAt: time.Now(),
+	}
+
+	if analytics.Goals, analytics.Categories, err = s.rates(userID, from, to); err != nil {
+		return nil, err
+	}
+	if analytics.Weeks, err = s.repo.GetWeeklyTrend(userID, from, to); err != nil {
+		return nil, err
+	}
+	if analytics.Weekdays, err = s.repo.GetWeekdays(userID, from, to); err != nil {
+		return nil, err
+	}
+	if analytics.Hours, err = s.repo.GetHours(userID, from, to, timezone); err != nil {
+		return nil, err
+	}
+	summarizeAnalytics(analytics)
+
+	s.store(userID, key, analytics)
+	return analytics, nil
+}
+
+// rates rates each of the user's active goals between from and to by the
+// share of its due periods that were met, each judged against the schedule in
+// force at the time, and adds them up per category. A category's consistency
+// is the mean of its goals'.
+func (s *AnalyticsService) rates(userID string, from, to time.Time) ([]*models.GoalAnalytics, []*models.CategoryAnalytics, error) {
+	goals, err := s.goalRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rates := []*models.GoalAnalytics{}
+	categories := []*models.CategoryAnalytics{}
+	byCategory := map[string]*models.CategoryAnalytics{}
+	for _, goal := range goals {
+		if truncateDay(goal.CreatedAt).After(to) {
+			continue
+		}
+		revisions, err := s.goalService.revisions(goal)
+		if err != nil {
+			return nil, nil, err
+		}
+		completions, err := s.completionRepo.GetByGoalID(goal.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		periods := duePeriods(goal, revisions, completions, from, to)
+		rate := &models.GoalAnalytics{
+			GoalID:           goal.ID,
+			Title:            goal.Title,
+			Category:         goal.Category,
+			Kind:             goal.Kind,
+			Frequency:        goal.Frequency,
+			DuePeriods:       len(periods),
+			ConsistencyScore: consistencyScore(periods),
+		}
+		for _, p := range periods {
+			if p.Met {
+				rate.MetPeriods++
+			}
+		}
+		if rate.DuePeriods > 0 {
+			rate.CompletionRate = float64(rate.MetPeriods) / float64(rate.DuePeriods)
+		}
+		rates = append(rates, rate)
+
+		key := goal.Category
+		if goal.CategoryID != nil {
+			key = *goal.CategoryID
+		}
+		c := byCategory[key]
+		if c == nil {
+			c = &models.CategoryAnalytics{CategoryID: goal.CategoryID, Name: goal.Category}
+			byCategory[key] = c
+			categories = append(categories, c)
+		}
+		c.Goals++
+		c.DuePeriods += rate.DuePeriods
+		c.MetPeriods += rate.MetPeriods
+		c.ConsistencyScore += rate.ConsistencyScore
+	}
+	for _, c := range categories {
+		if c.DuePeriods > 0 {
+			c.CompletionRate = float64(c.MetPeriods) / float64(c.DuePeriods)
+		}
+		c.ConsistencyScore /= float64(c.Goals)
+	}
+
+	sort.SliceStable(rates, func(i, j int) bool {
+		return strings.ToLower(rates[i].Title) < strings.ToLower(rates[j].Title)
+	})
+	sort.SliceStable(categories, func(i, j int) bool {
+		return strings.ToLower(categories[i].Name) < strings.ToLower(categories[j].Name)
+	})
+	return rates, categories, nil
+}
+
+// summarizeAnalytics fills in the overall rate and score and the best and
+// worst weekdays. Weekdays only rank once there are completions.
+func summarizeAnalytics(a *models.UserAnalytics) {
+	if a.Goals == nil {
+		a.Goals = []*models.GoalAnalytics{}
+	}
+	if a.Categories == nil {
+		a.Categories = []*models.CategoryAnalytics{}
+	}
+
+	var due, met int
+	var consistency float64
+	for _, g := range a.Goals {
+		due += g.DuePeriods
+		met += g.MetPeriods
+		consistency += g.ConsistencyScore
+	}
+	if due > 0 {
+		a.CompletionRate = float64(met) / float64(due)
+	}
+	if len(a.Goals) > 0 {
+		a.ConsistencyScore = consistency / float64(len(a.Goals))
+	}
+
+	var best, worst *models.WeekdayStats
+	total := 0
+	for _, d := range a.Weekdays {
+		total += d.Completions
+		if best == nil || d.AveragePerDay > best.AveragePerDay {
+			best = d
+		}
+		if worst == nil || d.AveragePerDay < worst.AveragePerDay {
+			worst = d
+		}
+	}
+	if total > 0 {
+		a.BestWeekday = best.Weekday
+		a.WorstWeekday = worst.Weekday
+	}
+}
+
+func (s *AnalyticsService) cached(userID, key string) *models.UserAnalytics {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.cache[userID][key]
+	if !ok || time.Now().After(entry.expires) {
+		return nil
+	}
+	return entry.analytics
+}
+
+func (s *AnalyticsService) store(userID, key string, analytics *models.UserAnalytics) {
+	if s.config.CacheTTL <= 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Expired entries of other users are dropped as new ones come in.
+	now := time.Now()
+	for user, entries := range s.cache {
+		for k, entry := range entries {
+			if now.After(entry.expires) {
+				delete(entries, k)
+			}
+		}
+		if len(entries) == 0 {
+			delete(s.cache, user)
+		}
+	}
+
+	if s.cache[userID] == nil {
+		s.cache[userID] = make(map[string]analyticsEntry)
+	}
+	s.cache[userID][key] = analyticsEntry{analytics: analytics, expires: now.Add(s.config.CacheTTL)}
+}
+
+// Invalidate drops the user's cached analytics.
+func (s *AnalyticsService) Invalidate(userID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.cache, userID)
+}
+
+func (s *AnalyticsService) GoalCreated(goal *models.Goal) error {
+	s.Invalidate(goal.UserID)
+	return nil
+}
+
+func (s *AnalyticsService) GoalCompleted(goal *models.Goal, completion *models.Completion, streak int) error {
+	s.Invalidate(goal.UserID)
+	return nil
+}
+
+func (s *AnalyticsService) CompletionRemoved(goal *models.Goal, completion *models.Completion) error {
+	s.Invalidate(goal.UserID)
+	return nil
+}
+
+func (s *AnalyticsService) GoalChanged(goal *models.Goal) error {
+	s.Invalidate(goal.UserID)
+	return nil
+}
+
+func (s *AnalyticsService) GoalTrashed(goal *models.Goal) error {
+	s.Invalidate(goal.UserID)
+	return nil
+}
+
+func (s *AnalyticsService) GoalRestored(goal *models.Goal) error {
+	s.Invalidate(goal.UserID)
+	return nil
+}
+
+func (s *AnalyticsService) GoalDeleted(goal *models.Goal) error {
+	s.Invalidate(goal.UserID)
+	return nil
+}
@@ -0,0 +1,48 @@
+package services
+
+import (
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"DoToday/config"
+	"DoToday/repositories"
+)
+
+func TestAnalyticsJudgeMeasurableGoalsAgainstTheirTarget(t *testing.T) {
+	db, fake := openFakeDB(t)
+	today := truncateDay(time.Now())
+	goal := goalRow(testGoalID, testUserID, today.AddDate(0, 0, -2))
+	goal[8], goal[9], goal[10], goal[11], goal[12] = "measurable", "km", 5.0, "at_least", "sum"
+	fake.on(`FROM goals g WHERE g.user_id = $1 AND g.archived = false`, goal).
+		on(`SELECT id, goal_id, date, count, value, created_at FROM completions`,
+			[]driver.Value{"c2", testGoalID, today.AddDate(0, 0, -1), int64(1), 6.0, today},
+			[]driver.Value{"c1", testGoalID, today.AddDate(0, 0, -2), int64(1), 3.0, today},
+		)
+
+	goalService := newTestGoalService(db, nil)
+	s := NewAnalyticsService(
+		repositories.NewAnalyticsRepository(db),
+		repositories.NewGoalRepository(db),
+		repositories.NewCompletionRepository(db),
+		goalService,
+		&config.AnalyticsConfig{},
+	)
+
+	analytics, err := s.GetAnalytics(testUserID, 3, "UTC")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(analytics.Goals) != 1 {
+		t.Fatalf("got %d goals, want 1", len(analytics.Goals))
+	}
+	// Two days have ended: one below the 5 km target, one above it. Today
+	// has no measurement yet and is not due.
+	g := analytics.Goals[0]
+	if g.DuePeriods != 2 || g.MetPeriods != 1 || g.CompletionRate != 0.5 {
+		t.Errorf("got %d of %d periods met (rate %v), want 1 of 2", g.MetPeriods, g.DuePeriods, g.CompletionRate)
+	}
+	if len(analytics.Categories) != 1 || analytics.Categories[0].DuePeriods != 2 {
+		t.Errorf("got categories %+v, want one with the goal's periods", analytics.Categories)
+	}
+}
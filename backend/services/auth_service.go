@@ -3,10 +3,12 @@ package services
 
 import (
 	"bytes"
+	"database/sql"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
 	"os"
 	"time"
@@ -19,18 +21,29 @@ import (
 
 type AuthService struct {
 	userRepo *repositories.UserRepository
+	filter   *ContentFilter
 }
 
-func NewAuthService(userRepo *repositories.UserRepository) *AuthService {
-	return &AuthService{userRepo: userRepo}
+func NewAuthService(userRepo *repositories.UserRepository, filter *ContentFilter) *AuthService {
+	return &AuthService{userRepo: userRepo, filter: filter}
 }
 
 func (s *AuthService) Register(req *models.RegisterRequest) (*models.AuthResponse, error) {
-	// ...existing code...
+	// The username is screened before the account exists, so a flag is
+	// recorded once the profile has been created.
+	username, reasons, flagged, err := s.filter.screen(&ContentInput{
+		Kind: models.ContentKindUsername,
+		Text: req.Username,
+	})
+	if err != nil {
+		return nil, err
+	}
+	req.Username = username
+
 	// Check if username or email already exists
-	_, err := s.userRepo.GetByUsername(req.Username)
+	_, err = s.userRepo.GetByUsername(req.Username)
 	if err == nil {
-		return nil, errors.New("username already exists")
+		return nil, models.Conflict("username already exists")
 	}
 
 	// Call Supabase Auth API to create user
@@ -107,6 +120,10 @@ func (s *AuthService) Register(req *models.RegisterRequest) (*models.AuthRespons
 	if resp.StatusCode >= 400 {
 		var errorResp supabaseError
 		if err := json.Unmarshal(body, &errorResp); err == nil && errorResp.Message != "" {
+			if resp.StatusCode < 500 {
+				// Rejected sign ups, such as a taken email or weak password
+				return nil, models.Invalid("%s", errorResp.Message)
+			}
 			return nil, fmt.Errorf("supabase auth error: %s (code=%d)", errorResp.Message, errorResp.Code)
 		}
 		return nil, fmt.Errorf("supabase auth error: status=%d, body=%s", resp.StatusCode, string(body))
@@ -137,11 +154,18 @@ func (s *AuthService) Register(req *models.RegisterRequest) (*models.AuthRespons
 		ID:        sbResp.ID,
 		Username:  username,
 		Email:     sbResp.Email,
+		Role:      models.RoleUser,
 		CreatedAt: time.Now(),
 	}
 	if err := s.userRepo.Create(profile); err != nil {
 		return nil, fmt.Errorf("failed to insert profile: %w", err)
 	}
+	if flagged {
+		input := &ContentInput{UserID: profile.ID, Kind: models.ContentKindUsername, TargetID: profile.ID, Text: username}
+		if err := s.filter.flag(input, profile.Username, reasons); err != nil {
+			log.Printf("flagging username failed for user %s: %v", profile.ID, err)
+		}
+	}
 	token, err := s.generateJWT(profile.ID)
 	if err != nil {
 		return nil, err
@@ -155,8 +179,10 @@ func (s *AuthService) Register(req *models.RegisterRequest) (*models.AuthRespons
 // Login should be handled by Supabase Auth. This is a placeholder for local/dev only.
 func (s *AuthService) Login(req *models.LoginRequest) (*models.AuthResponse, error) {
 	profile, err := s.userRepo.GetByUsername(req.Username)
-	if err != nil {
-		return nil, errors.New("invalid credentials")
+	if err == sql.ErrNoRows {
+		return nil, models.Unauthorized("invalid credentials")
+	} else if err != nil {
+		return nil, err
 	}
 
 	// In production, validate password via Supabase Auth, not locally.
@@ -172,6 +198,40 @@ func (s *AuthService) Login(req *models.LoginRequest) (*models.AuthResponse, err
 	}, nil
 }
 
+// DeleteAuthUser removes the user from Supabase Auth. It reports false
+// without error when Supabase is not configured.
+func (s *AuthService) DeleteAuthUser(userID string) (bool, error) {
+	sbUrl := os.Getenv("SUPABASE_URL")
+	sbKey := os.Getenv("SUPABASE_SERVICE_ROLE_KEY")
+	if sbUrl == "" || sbKey == "" {
+		return false, nil
+	}
+
+	reqHttp, err := http.NewRequest("DELETE", sbUrl+"/auth/v1/admin/users/"+userID, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to create request: %w", err)
+	}
+	reqHttp.Header.Set("apikey", sbKey)
+	reqHttp.Header.Set("Authorization", "Bearer "+sbKey)
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(reqHttp)
+	if err != nil {
+		return false, fmt.Errorf("failed to call Supabase Auth API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// A missing user has already been removed.
+	if resp.StatusCode == http.StatusNotFound {
+		return true, nil
+	}
+	if resp.StatusCode >= 400 {
+		body, _ := io.ReadAll(resp.Body)
+		return false, fmt.Errorf("supabase auth error: status=%d, body=%s", resp.StatusCode, string(body))
+	}
+	return true, nil
+}
+
 func (s *AuthService) generateJWT(userID string) (string, error) {
 	claims := &jwt.RegisteredClaims{
 		Subject:   userID,
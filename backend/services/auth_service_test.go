@@ -0,0 +1,95 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"DoToday/models"
+	"DoToday/repositories"
+)
+
+// stubSupabase stands in for the Supabase admin API, echoing the requested
+// username back as the new user's metadata. It records the usernames it was
+// asked to create.
+func stubSupabase(t *testing.T) *[]string {
+	t.Helper()
+	var created []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Email        string                 `json:"email"`
+			UserMetadata map[string]interface{} `json:"user_metadata"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decoding signup request: %v", err)
+		}
+		created = append(created, req.UserMetadata["username"].(string))
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"id":            testUserID,
+			"email":         req.Email,
+			"user_metadata": req.UserMetadata,
+		})
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("SUPABASE_URL", server.URL)
+	t.Setenv("SUPABASE_SERVICE_ROLE_KEY", "service-key")
+	t.Setenv("JWT_SECRET", "test-secret")
+	return &created
+}
+
+func registerRequest(username string) *models.RegisterRequest {
+	return &models.RegisterRequest{Username: username, Email: "reader@example.com", Password: "correct horse"}
+}
+
+func TestRegisterRejectsFilteredUsername(t *testing.T) {
+	created := stubSupabase(t)
+	db, fake := openFakeDB(t)
+	s := NewAuthService(repositories.NewUserRepository(db), wordFilter(FilterReject))
+
+	_, err := s.Register(registerRequest("spam"))
+	if !errors.Is(err, &models.Error{Code: models.ErrorRejected}) {
+		t.Fatalf("got %v, want a rejection", err)
+	}
+	if len(*created) != 0 || len(fake.ran(`INSERT INTO profiles`)) != 0 {
+		t.Error("a rejected username still created an account")
+	}
+}
+
+func TestRegisterMasksFilteredUsername(t *testing.T) {
+	created := stubSupabase(t)
+	db, fake := openFakeDB(t)
+	s := NewAuthService(repositories.NewUserRepository(db), wordFilter(FilterMask))
+
+	resp, err := s.Register(registerRequest("spam"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.User.Username != "****" {
+		t.Errorf("username is %q, want it masked", resp.User.Username)
+	}
+	if len(*created) != 1 || (*created)[0] != "****" {
+		t.Errorf("signed up %v, want the masked username", *created)
+	}
+	profiles := fake.ran(`INSERT INTO profiles`)
+	if len(profiles) != 1 || profiles[0].args[1] != "****" {
+		t.Errorf("got profile inserts %v, want the masked username stored", profiles)
+	}
+}
+
+func TestRegisterFlagsUsernameOnceTheProfileExists(t *testing.T) {
+	stubSupabase(t)
+	db, fake := openFakeDB(t)
+	flagRepo := repositories.NewContentFilterRepository(db)
+	filter := NewContentFilter(flagRepo, NewWordListRule([]string{"spam"}, FilterFlag))
+	s := NewAuthService(repositories.NewUserRepository(db), filter)
+
+	if _, err := s.Register(registerRequest("spam")); err != nil {
+		t.Fatal(err)
+	}
+	flags := fake.ran(`INSERT INTO content_flags`)
+	if len(flags) != 1 || flags[0].args[1] != testUserID {
+		t.Fatalf("got flags %v, want one for the new user", flags)
+	}
+}
@@ -0,0 +1,225 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"DoToday/models"
+	"DoToday/repositories"
+)
+
+// The calendar feed covers calendarPastDays days back and calendarFutureDays
+// days ahead of today.
+const (
+	calendarPastDays   = 30
+	calendarFutureDays = 30
+)
+
+// Calendar item kinds.
+const (
+	CalendarKindTodo  = "todo"
+	CalendarKindEvent = "event"
+)
+
+// CalendarOptions shape a rendered feed. Reminder is the time of day, in
+// minutes after midnight, to remind about an open occurrence on its last
+// day; a negative value turns reminders off.
+type CalendarOptions struct {
+	Kind     string
+	Reminder int
+}
+
+type CalendarService struct {
+	repo           *repositories.CalendarRepository
+	goalRepo       *repositories.GoalRepository
+	completionRepo *repositories.CompletionRepository
+	goalService    *GoalService
+}
+
+func NewCalendarService(
+	repo *repositories.CalendarRepository,
+	goalRepo *repositories.GoalRepository,
+	completionRepo *repositories.CompletionRepository,
+	goalService *GoalService,
+) *CalendarService {
+	return &CalendarService{
+		repo:           repo,
+		goalRepo:       goalRepo,
+		completionRepo: completionRepo,
+		goalService:    goalService,
+	}
+}
+
+func hashCalendarToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func (s *CalendarService) GetFeed(userID string) (*models.CalendarFeed, error) {
+	feed, err := s.repo.GetByUserID(userID)
+	if err == sql.ErrNoRows {
+		return &models.CalendarFeed{}, nil
+	}
+	return feed, err
+}
+
+// CreateToken issues a new feed token for the user, revoking the old one.
+// The token is only returned here.
+func (s *CalendarService) CreateToken(userID string) (string, *models.CalendarFeed, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", nil, err
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+
+	now := time.Now()
+	if err := s.repo.SaveToken(userID, hashCalendarToken(token), now); err != nil {
+		return "", nil, err
+	}
+	return token, &models.CalendarFeed{Enabled: true, CreatedAt: &now}, nil
+}
+
+func (s *CalendarService) DeleteToken(userID string) error {
+	return s.repo.DeleteToken(userID)
+}
+
+// RenderFeed returns the iCalendar feed of the token's owner.
+func (s *CalendarService) RenderFeed(token string, opts CalendarOptions) (string, error) {
+	userID, err := s.repo.UseToken(hashCalendarToken(token), time.Now())
+	if err == sql.ErrNoRows {
+		return "", models.NotFound("calendar feed not found")
+	} else if err != nil {
+		return "", err
+	}
+
+	goals, err := s.goalRepo.GetByUserID(userID)
+	if err != nil {
+		return "", err
+	}
+	today := truncateDay(time.Now())
+	from, to := today.AddDate(0, 0, -calendarPastDays), today.AddDate(0, 0, calendarFutureDays)
+
+	// Periods starting before from still need their earlier completions.
+	completions, err := s.completionRepo.GetByUserInRange(userID, from.AddDate(0, -1, 0), today)
+	if err != nil {
+		return "", err
+	}
+	byGoal := make(map[string][]*models.Completion)
+	for _, c := range completions {
+		byGoal[c.GoalID] = append(byGoal[c.GoalID], c)
+	}
+
+	w := &icalWriter{}
+	w.line("BEGIN", "VCALENDAR")
+	w.line("VERSION", "2.0")
+	w.line("PRODID", "-//DoToday//Goals//EN")
+	w.line("CALSCALE", "GREGORIAN")
+	w.line("METHOD", "PUBLISH")
+	w.line("X-WR-CALNAME", "DoToday")
+	w.line("REFRESH-INTERVAL;VALUE=DURATION", "PT1H")
+	w.line("X-PUBLISHED-TTL", "PT1H")
+
+	for _, goal := range goals {
+		switch goal.Kind {
+		case models.GoalKindQuit:
+			// Quit goals have nothing scheduled.
+		case models.GoalKindMilestone:
+			if goal.Deadline == nil || truncateDay(*goal.Deadline).Before(from) {
+				continue
+			}
+			if err := s.goalService.attachMilestone(goal); err != nil {
+				return "", err
+			}
+			deadline := truncateDay(*goal.Deadline)
+			done := goal.Milestone != nil && goal.Milestone.Achieved
+			writeCalendarItem(w, opts, goal, deadline, deadline, done, nil, today)
+		default:
+			revisions, err := s.goalService.revisions(goal)
+			if err != nil {
+				return "", err
+			}
+			// Whole periods keep each occurrence's UID and dates stable
+			// as the window moves.
+			start, _ := periodBounds(goal.Frequency, from)
+			if created := truncateDay(goal.CreatedAt); created.After(start) {
+				start = created
+			}
+			_, end := periodBounds(goal.Frequency, to)
+
+			for _, p := range evaluatePeriods(goal, revisions, byGoal[goal.ID], start, end) {
+				var completedAt *time.Time
+				for _, c := range byGoal[goal.ID] {
+					day := truncateDay(c.Date)
+					if !day.Before(p.Start) && !day.After(p.End) && (completedAt == nil || c.CreatedAt.After(*completedAt)) {
+						completedAt = &c.CreatedAt
+					}
+				}
+				writeCalendarItem(w, opts, goal, p.Start, p.End, p.Met, completedAt, today)
+			}
+		}
+	}
+
+	w.line("END", "VCALENDAR")
+	return w.String(), nil
+}
+
+// writeCalendarItem writes one occurrence of a goal, from start to end
+// inclusive, as a VTODO or an all-day VEVENT. Open occurrences that have not
+// ended get a reminder.
+func writeCalendarItem(w *icalWriter, opts CalendarOptions, goal *models.Goal, start, end time.Time, done bool, completedAt *time.Time, today time.Time) {
+	component := "VTODO"
+	if opts.Kind == CalendarKindEvent {
+		component = "VEVENT"
+	}
+	summary := goal.Title
+	if goal.TargetCount > 1 && goal.Kind == models.GoalKindHabit {
+		summary = fmt.Sprintf("%s (%d×)", goal.Title, goal.TargetCount)
+	}
+
+	w.line("BEGIN", component)
+	w.line("UID", fmt.Sprintf("%s-%s@dotoday", goal.ID, start.Format(icalDate)))
+	w.timestamp("DTSTAMP", time.Now())
+	w.date("DTSTART", start)
+	if component == "VTODO" {
+		w.date("DUE", end.AddDate(0, 0, 1))
+	} else {
+		w.date("DTEND", end.AddDate(0, 0, 1))
+		w.line("TRANSP", "TRANSPARENT")
+		if done {
+			summary = "✓ " + summary
+		}
+	}
+	w.line("SUMMARY", icalText(summary))
+	if goal.Description != "" {
+		w.line("DESCRIPTION", icalText(goal.Description))
+	}
+	if goal.Category != "" {
+		w.line("CATEGORIES", icalText(goal.Category))
+	}
+
+	if component == "VTODO" {
+		if done {
+			w.line("STATUS", "COMPLETED")
+			w.line("PERCENT-COMPLETE", "100")
+			if completedAt != nil {
+				w.timestamp("COMPLETED", *completedAt)
+			}
+		} else {
+			w.line("STATUS", "NEEDS-ACTION")
+		}
+	}
+
+	if !done && opts.Reminder >= 0 && !end.Before(today) {
+		w.line("BEGIN", "VALARM")
+		w.line("ACTION", "DISPLAY")
+		w.line("DESCRIPTION", icalText(goal.Title))
+		w.line("TRIGGER;RELATED=END", fmt.Sprintf("-PT%dM", 24*60-opts.Reminder))
+		w.line("END", "VALARM")
+	}
+	w.line("END", component)
+}
@@ -0,0 +1,184 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"DoToday/models"
+	"DoToday/repositories"
+
+	"github.com/google/uuid"
+)
+
+// categoryStatsDays is the window for recent completions in category stats.
+const categoryStatsDays = 30
+
+const (
+	defaultCategoryColor = "#6b7280"
+	defaultCategoryIcon  = "tag"
+)
+
+var (
+	errCategoryNotFound = models.NotFound("category not found")
+	errUnknownCategory  = models.NotFound("unknown category")
+)
+
+type CategoryService struct {
+	repo *repositories.CategoryRepository
+}
+
+func NewCategoryService(repo *repositories.CategoryRepository) *CategoryService {
+	return &CategoryService{repo: repo}
+}
+
+func (s *CategoryService) GetCategories(userID string) ([]*models.Category, error) {
+	return s.repo.GetForUser(userID)
+}
+
+func (s *CategoryService) CreateCategory(userID string, req *models.CreateCategoryRequest) (*models.Category, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, models.InvalidFields("category name is required", models.FieldError{Field: "name", Message: "is required"})
+	}
+	if _, err := s.repo.GetByName(userID, name); err == nil {
+		return nil, models.Conflict("category already exists")
+	} else if err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	category := &models.Category{
+		ID:        uuid.NewString(),
+		UserID:    &userID,
+		Name:      name,
+		Color:     firstNonEmpty(req.Color, defaultCategoryColor),
+		Icon:      firstNonEmpty(req.Icon, defaultCategoryIcon),
+		CreatedAt: time.Now(),
+	}
+	if err := s.repo.Create(category); err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+// getOwned returns one of the user's own categories. System categories
+// cannot be changed.
+func (s *CategoryService) getOwned(categoryID, userID string) (*models.Category, error) {
+	category, err := s.repo.GetByID(categoryID)
+	if err == sql.ErrNoRows {
+		return nil, errCategoryNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	if category.IsSystem {
+		return nil, models.Forbidden("cannot modify system category")
+	}
+	if *category.UserID != userID {
+		return nil, errCategoryNotFound
+	}
+	return category, nil
+}
+
+func (s *CategoryService) UpdateCategory(categoryID, userID string, req *models.UpdateCategoryRequest) (*models.Category, error) {
+	category, err := s.getOwned(categoryID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return nil, models.InvalidFields("category name is required", models.FieldError{Field: "name", Message: "is required"})
+		}
+		if existing, err := s.repo.GetByName(userID, name); err == nil && existing.ID != category.ID {
+			return nil, models.Conflict("category already exists")
+		} else if err != nil && err != sql.ErrNoRows {
+			return nil, err
+		}
+		category.Name = name
+	}
+	if req.Color != nil {
+		category.Color = *req.Color
+	}
+	if req.Icon != nil {
+		category.Icon = *req.Icon
+	}
+
+	if err := s.repo.Update(category); err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+// DeleteCategory removes one of the user's categories; its goals move to the
+// default system category.
+func (s *CategoryService) DeleteCategory(categoryID, userID string) error {
+	category, err := s.getOwned(categoryID, userID)
+	if err != nil {
+		return err
+	}
+	fallback, err := s.repo.GetByName(userID, models.DefaultCategoryName)
+	if err != nil {
+		return err
+	}
+	return s.repo.Delete(category, fallback)
+}
+
+// Resolve finds the category a goal should use: by ID when given, otherwise
+// by name. Only system categories and the user's own are visible.
+func (s *CategoryService) Resolve(userID, categoryID, name string) (*models.Category, error) {
+	if categoryID != "" {
+		category, err := s.repo.GetByID(categoryID)
+		if err == sql.ErrNoRows || (err == nil && !category.IsSystem && *category.UserID != userID) {
+			return nil, errCategoryNotFound
+		}
+		return category, err
+	}
+
+	category, err := s.repo.GetByName(userID, name)
+	if err == sql.ErrNoRows {
+		return nil, errUnknownCategory
+	}
+	return category, err
+}
+
+// Ensure returns the category with the given name, creating it for the user
+// if neither a system nor a user category matches.
+func (s *CategoryService) Ensure(userID, name string) (*models.Category, error) {
+	category, err := s.Resolve(userID, "", name)
+	if err == nil || !errors.Is(err, errUnknownCategory) {
+		return category, err
+	}
+	return s.CreateCategory(userID, &models.CreateCategoryRequest{Name: name})
+}
+
+// categoryError reports a category that Resolve could not find as an invalid
+// field of the goal request naming it.
+func categoryError(err error) error {
+	switch {
+	case errors.Is(err, errCategoryNotFound):
+		return models.InvalidFields("category not found", models.FieldError{Field: "category_id", Message: "category not found"})
+	case errors.Is(err, errUnknownCategory):
+		return models.InvalidFields("unknown category", models.FieldError{Field: "category", Message: "unknown category"})
+	}
+	return err
+}
+
+func (s *CategoryService) GetStats(userID string) ([]*models.CategoryStats, error) {
+	return s.repo.GetStats(userID, "", categoryStatsDays)
+}
+
+func (s *CategoryService) GetCategoryStats(categoryID, userID string) (*models.CategoryStats, error) {
+	if _, err := s.Resolve(userID, categoryID, ""); err != nil {
+		return nil, err
+	}
+	stats, err := s.repo.GetStats(userID, categoryID, categoryStatsDays)
+	if err != nil {
+		return nil, err
+	}
+	if len(stats) == 0 {
+		return nil, errCategoryNotFound
+	}
+	return stats[0], nil
+}
@@ -0,0 +1,339 @@
+package services
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/base32"
+	"errors"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"DoToday/models"
+	"DoToday/repositories"
+
+	"github.com/google/uuid"
+)
+
+type ChallengeService struct {
+	repo           *repositories.ChallengeRepository
+	completionRepo *repositories.CompletionRepository
+	goalService    *GoalService
+	filter         *ContentFilter
+}
+
+func NewChallengeService(
+	repo *repositories.ChallengeRepository,
+	completionRepo *repositories.CompletionRepository,
+	goalService *GoalService,
+	filter *ContentFilter,
+) *ChallengeService {
+	return &ChallengeService{
+		repo:           repo,
+		completionRepo: completionRepo,
+		goalService:    goalService,
+		filter:         filter,
+	}
+}
+
+func (s *ChallengeService) CreateChallenge(userID string, req *models.CreateChallengeRequest) (*models.Challenge, error) {
+	start := req.StartDate.UTC().Truncate(24 * time.Hour)
+	end := req.EndDate.UTC().Truncate(24 * time.Hour)
+	if end.Before(start) {
+		return nil, models.InvalidFields("end date must not be before start date", models.FieldError{Field: "end_date", Message: "must not be before start_date"})
+	}
+
+	code, err := newInviteCode()
+	if err != nil {
+		return nil, err
+	}
+
+	targetCount := req.TargetCount
+	if targetCount < 1 {
+		targetCount = 1
+	}
+	frequency := req.Frequency
+	if frequency == "" {
+		frequency = "daily"
+	}
+
+	challenge := &models.Challenge{
+		ID:              uuid.NewString(),
+		OwnerID:         userID,
+		Title:           req.Title,
+		Description:     req.Description,
+		StartDate:       start,
+		EndDate:         end,
+		GoalTitle:       req.GoalTitle,
+		GoalCategory:    req.GoalCategory,
+		GoalDescription: req.GoalDescription,
+		Frequency:       frequency,
+		TargetCount:     targetCount,
+		InviteCode:      code,
+		IsPublic:        req.IsPublic,
+		CreatedAt:       time.Now(),
+	}
+	if err := s.repo.Create(challenge); err != nil {
+		return nil, err
+	}
+
+	// The owner takes part in their own challenge
+	if _, err := s.join(challenge, userID); err != nil {
+		return nil, err
+	}
+	challenge.Participants = 1
+	return challenge, nil
+}
+
+func (s *ChallengeService) GetUserChallenges(userID string) ([]*models.Challenge, error) {
+	challenges, err := s.repo.GetByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, challenge := range challenges {
+		if challenge.OwnerID != userID {
+			challenge.InviteCode = ""
+		}
+	}
+	return challenges, nil
+}
+
+func (s *ChallengeService) GetChallenge(challengeID, userID string) (*models.Challenge, error) {
+	challenge, err := s.repo.GetByID(challengeID)
+	if err != nil {
+		return nil, notFound(err, "Challenge not found")
+	}
+	if err := s.checkAccess(challenge, userID); err != nil {
+		return nil, err
+	}
+	if challenge.OwnerID != userID {
+		challenge.InviteCode = ""
+	}
+	return challenge, nil
+}
+
+// JoinByID joins a public challenge without an invite code.
+func (s *ChallengeService) JoinByID(challengeID, userID string) (*models.ChallengeParticipant, error) {
+	challenge, err := s.repo.GetByID(challengeID)
+	if err != nil {
+		return nil, notFound(err, "Challenge not found")
+	}
+	if !challenge.IsPublic {
+		return nil, errForbidden
+	}
+	return s.join(challenge, userID)
+}
+
+func (s *ChallengeService) JoinByInviteCode(code, userID string) (*models.ChallengeParticipant, error) {
+	challenge, err := s.repo.GetByInviteCode(strings.ToUpper(strings.TrimSpace(code)))
+	if err == sql.ErrNoRows {
+		return nil, models.NotFound("invalid invite code")
+	} else if err != nil {
+		return nil, err
+	}
+	return s.join(challenge, userID)
+}
+
+// join creates the participant's linked goal from the challenge template and
+// records the membership. The goal is purged again if the membership cannot
+// be recorded, so a failed join leaves no orphaned goal behind.
+func (s *ChallengeService) join(challenge *models.Challenge, userID string) (*models.ChallengeParticipant, error) {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	if today.After(challenge.EndDate) {
+		return nil, models.Conflict("challenge has ended")
+	}
+
+	_, err := s.repo.GetParticipant(challenge.ID, userID)
+	if err == nil {
+		return nil, models.Conflict("already joined")
+	} else if err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	// The challenge's category may be one the participant does not have yet.
+	category, err := s.goalService.categories.Ensure(userID, firstNonEmpty(challenge.GoalCategory, models.DefaultCategoryName))
+	if err != nil {
+		return nil, err
+	}
+
+	goal, err := s.goalService.CreateGoal(userID, &models.CreateGoalRequest{
+		Title:       challenge.GoalTitle,
+		CategoryID:  category.ID,
+		Description: challenge.GoalDescription,
+		Frequency:   challenge.Frequency,
+		TargetCount: challenge.TargetCount,
+		Deadline:    &challenge.EndDate,
+		IsPublic:    challenge.IsPublic,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	participant := &models.ChallengeParticipant{
+		ChallengeID: challenge.ID,
+		UserID:      userID,
+		GoalID:      goal.ID,
+		JoinedAt:    time.Now(),
+	}
+	if err := s.repo.AddParticipant(participant); err != nil {
+		if purgeErr := s.goalService.purge(goal); purgeErr != nil {
+			log.Printf("removing goal %s after failed challenge join failed: %v", goal.ID, purgeErr)
+		}
+		// A concurrent join for the same user got in first.
+		if repositories.IsUniqueViolation(err) {
+			return nil, models.Conflict("already joined")
+		}
+		return nil, err
+	}
+	return participant, nil
+}
+
+// LeaveChallenge removes the membership and archives the linked goal so its
+// history is kept.
+func (s *ChallengeService) LeaveChallenge(challengeID, userID string) error {
+	challenge, err := s.repo.GetByID(challengeID)
+	if err != nil {
+		return notFound(err, "Challenge not found")
+	}
+	if challenge.OwnerID == userID {
+		return models.Invalid("owner cannot leave challenge")
+	}
+
+	participant, err := s.repo.GetParticipant(challengeID, userID)
+	if err == sql.ErrNoRows {
+		return models.Invalid("not a participant")
+	} else if err != nil {
+		return err
+	}
+
+	if err := s.repo.RemoveParticipant(challengeID, userID); err != nil {
+		return err
+	}
+	// The linked goal may already be in the trash, leaving nothing to archive.
+	err = s.goalService.ArchiveGoal(participant.GoalID, userID)
+	if errors.Is(err, &models.Error{Code: models.ErrorNotFound}) {
+		return nil
+	}
+	return err
+}
+
+// GetLeaderboard ranks participants by completions inside the challenge
+// window, then by current streak. Tied participants share a rank.
+func (s *ChallengeService) GetLeaderboard(challengeID, userID string) ([]*models.ChallengeLeaderboardEntry, error) {
+	challenge, err := s.repo.GetByID(challengeID)
+	if err != nil {
+		return nil, notFound(err, "Challenge not found")
+	}
+	if err := s.checkAccess(challenge, userID); err != nil {
+		return nil, err
+	}
+
+	participants, err := s.repo.GetParticipants(challengeID)
+	if err != nil {
+		return nil, err
+	}
+
+	to := time.Now().UTC().Truncate(24 * time.Hour)
+	if to.After(challenge.EndDate) {
+		to = challenge.EndDate
+	}
+
+	entries := make([]*models.ChallengeLeaderboardEntry, 0, len(participants))
+	for _, participant := range participants {
+		completions, err := s.completionRepo.CountInRange(participant.GoalID, challenge.StartDate, to)
+		if err != nil {
+			return nil, err
+		}
+		streak, err := s.completionRepo.CalculateCurrentStreak(participant.GoalID)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, &models.ChallengeLeaderboardEntry{
+			UserID:        participant.UserID,
+			Username:      participant.Username,
+			GoalID:        participant.GoalID,
+			Completions:   completions,
+			CurrentStreak: streak,
+		})
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Completions != entries[j].Completions {
+			return entries[i].Completions > entries[j].Completions
+		}
+		return entries[i].CurrentStreak > entries[j].CurrentStreak
+	})
+	for i, entry := range entries {
+		entry.Rank = i + 1
+		if i > 0 {
+			prev := entries[i-1]
+			if prev.Completions == entry.Completions && prev.CurrentStreak == entry.CurrentStreak {
+				entry.Rank = prev.Rank
+			}
+		}
+	}
+	return entries, nil
+}
+
+func (s *ChallengeService) CreatePost(challengeID, userID string, req *models.CreateChallengePostRequest) (*models.ChallengePost, error) {
+	if _, err := s.repo.GetParticipant(challengeID, userID); err == sql.ErrNoRows {
+		return nil, errForbidden
+	} else if err != nil {
+		return nil, err
+	}
+
+	post := &models.ChallengePost{
+		ID:          uuid.NewString(),
+		ChallengeID: challengeID,
+		UserID:      userID,
+		CreatedAt:   time.Now(),
+	}
+	content, err := s.filter.Apply(&ContentInput{
+		UserID:   userID,
+		Kind:     models.ContentKindChallenge,
+		TargetID: post.ID,
+		Text:     req.Content,
+	})
+	if err != nil {
+		return nil, err
+	}
+	post.Content = content
+
+	if err := s.repo.CreatePost(post); err != nil {
+		return nil, err
+	}
+	return post, nil
+}
+
+func (s *ChallengeService) GetPosts(challengeID, userID string) ([]*models.ChallengePost, error) {
+	challenge, err := s.repo.GetByID(challengeID)
+	if err != nil {
+		return nil, notFound(err, "Challenge not found")
+	}
+	if err := s.checkAccess(challenge, userID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetPosts(challengeID, userID)
+}
+
+// checkAccess lets anyone see public challenges and only participants see
+// private ones.
+func (s *ChallengeService) checkAccess(challenge *models.Challenge, userID string) error {
+	if challenge.IsPublic || challenge.OwnerID == userID {
+		return nil
+	}
+	_, err := s.repo.GetParticipant(challenge.ID, userID)
+	if err == sql.ErrNoRows {
+		return errForbidden
+	}
+	return err
+}
+
+func newInviteCode() (string, error) {
+	b := make([]byte, 5)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base32.StdEncoding.EncodeToString(b), nil
+}
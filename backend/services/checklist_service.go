@@ -0,0 +1,246 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"sort"
+	"time"
+
+	"DoToday/models"
+	"DoToday/repositories"
+
+	"github.com/google/uuid"
+)
+
+// ChecklistService manages the checklist items of multi-step goals. Checking
+// the last required item of the day records the day's completion through
+// GoalService.MarkComplete.
+type ChecklistService struct {
+	repo           *repositories.ChecklistRepository
+	goalRepo       *repositories.GoalRepository
+	completionRepo *repositories.CompletionRepository
+	goalService    *GoalService
+}
+
+func NewChecklistService(
+	repo *repositories.ChecklistRepository,
+	goalRepo *repositories.GoalRepository,
+	completionRepo *repositories.CompletionRepository,
+	goalService *GoalService,
+) *ChecklistService {
+	return &ChecklistService{
+		repo:           repo,
+		goalRepo:       goalRepo,
+		completionRepo: completionRepo,
+		goalService:    goalService,
+	}
+}
+
+var errItemListMismatch = models.InvalidFields("item list does not match checklist",
+	models.FieldError{Field: "item_ids", Message: "must list every checklist item once"})
+
+// ownedGoal loads the goal and checks the user owns it.
+func (s *ChecklistService) ownedGoal(goalID, userID string) (*models.Goal, error) {
+	goal, err := s.goalRepo.GetByID(goalID)
+	if err != nil {
+		return nil, notFound(err, "Goal not found")
+	}
+	if goal.UserID != userID {
+		return nil, errForbidden
+	}
+	return goal, nil
+}
+
+// ownedItem loads an item of one of the user's goals.
+func (s *ChecklistService) ownedItem(goalID, itemID, userID string) (*models.ChecklistItem, error) {
+	if _, err := s.ownedGoal(goalID, userID); err != nil {
+		return nil, err
+	}
+	item, err := s.repo.GetItemByID(itemID)
+	if err == sql.ErrNoRows || (err == nil && item.GoalID != goalID) {
+		return nil, models.NotFound("checklist item not found")
+	}
+	return item, err
+}
+
+func (s *ChecklistService) AddItem(goalID, userID string, req *models.CreateChecklistItemRequest) (*models.ChecklistItem, error) {
+	goal, err := s.ownedGoal(goalID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if goal.Kind != models.GoalKindHabit {
+		return nil, models.Invalid("checklists are only available for habit goals")
+	}
+
+	item := &models.ChecklistItem{
+		ID:        uuid.NewString(),
+		GoalID:    goalID,
+		Title:     req.Title,
+		Required:  req.Required == nil || *req.Required,
+		CreatedAt: time.Now(),
+	}
+	if err := s.repo.CreateItem(item); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+func (s *ChecklistService) UpdateItem(goalID, itemID, userID string, req *models.UpdateChecklistItemRequest) (*models.ChecklistItem, error) {
+	item, err := s.ownedItem(goalID, itemID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Title != nil {
+		item.Title = *req.Title
+	}
+	if req.Required != nil {
+		item.Required = *req.Required
+	}
+	if err := s.repo.UpdateItem(item); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+func (s *ChecklistService) DeleteItem(goalID, itemID, userID string) error {
+	if _, err := s.ownedItem(goalID, itemID, userID); err != nil {
+		return err
+	}
+	return s.repo.DeleteItem(itemID)
+}
+
+// Reorder puts the checklist in the given order. itemIDs must name every item
+// exactly once.
+func (s *ChecklistService) Reorder(goalID, userID string, itemIDs []string) ([]*models.ChecklistItem, error) {
+	if _, err := s.ownedGoal(goalID, userID); err != nil {
+		return nil, err
+	}
+
+	items, err := s.repo.GetItems(goalID)
+	if err != nil {
+		return nil, err
+	}
+	current := make([]string, 0, len(items))
+	for _, item := range items {
+		current = append(current, item.ID)
+	}
+	requested := append([]string(nil), itemIDs...)
+	sort.Strings(current)
+	sort.Strings(requested)
+	if len(current) != len(requested) {
+		return nil, errItemListMismatch
+	}
+	for i := range current {
+		if current[i] != requested[i] {
+			return nil, errItemListMismatch
+		}
+	}
+
+	if err := s.repo.Reorder(goalID, itemIDs); err != nil {
+		return nil, err
+	}
+	return s.repo.GetItems(goalID)
+}
+
+// GetDay returns the checklist with its state on the given day. Anyone who
+// can view the goal can read it.
+func (s *ChecklistService) GetDay(goalID, userID string, date time.Time) (*models.ChecklistDay, error) {
+	goal, err := s.goalRepo.GetByID(goalID)
+	if err != nil {
+		return nil, notFound(err, "Goal not found")
+	}
+	allowed, err := s.goalService.canView(goal, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, errForbidden
+	}
+	return s.day(goalID, date)
+}
+
+func (s *ChecklistService) day(goalID string, date time.Time) (*models.ChecklistDay, error) {
+	items, err := s.repo.GetItems(goalID)
+	if err != nil {
+		return nil, err
+	}
+	checks, err := s.repo.GetChecks(goalID, date)
+	if err != nil {
+		return nil, err
+	}
+	completed, err := s.completionRepo.GetCompletionExists(goalID, date)
+	if err != nil {
+		return nil, err
+	}
+
+	day := &models.ChecklistDay{
+		GoalID:    goalID,
+		Date:      date,
+		Items:     []*models.ChecklistItemState{},
+		Completed: completed,
+	}
+	for _, item := range items {
+		state := &models.ChecklistItemState{ChecklistItem: item}
+		if checkedAt, ok := checks[item.ID]; ok {
+			state.Checked = true
+			state.CheckedAt = &checkedAt
+		}
+		day.Items = append(day.Items, state)
+	}
+	day.Done = checklistDone(day.Items)
+	return day, nil
+}
+
+// checklistDone reports whether every required item is checked. A checklist
+// with no required items is done once every item is checked.
+func checklistDone(items []*models.ChecklistItemState) bool {
+	if len(items) == 0 {
+		return false
+	}
+	anyRequired := false
+	for _, item := range items {
+		if item.Required {
+			anyRequired = true
+		}
+	}
+	for _, item := range items {
+		if (item.Required || !anyRequired) && !item.Checked {
+			return false
+		}
+	}
+	return true
+}
+
+// SetChecked checks or unchecks an item for today. When the checklist becomes
+// done the day's completion is recorded. Unchecking never removes a
+// completion; that is done through RemoveCompletion.
+func (s *ChecklistService) SetChecked(goalID, itemID, userID string, checked bool) (*models.ChecklistDay, error) {
+	if _, err := s.ownedItem(goalID, itemID, userID); err != nil {
+		return nil, err
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	var err error
+	if checked {
+		err = s.repo.Check(itemID, today, time.Now())
+	} else {
+		err = s.repo.Uncheck(itemID, today)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	day, err := s.day(goalID, today)
+	if err != nil {
+		return nil, err
+	}
+	if checked && day.Done && !day.Completed {
+		err := s.goalService.MarkComplete(goalID, userID)
+		if err != nil && !errors.Is(err, errAlreadyCompleted) {
+			return nil, err
+		}
+		day.Completed = true
+	}
+	return day, nil
+}
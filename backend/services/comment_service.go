@@ -1,26 +1,92 @@
 package services
 
 import (
+	"log"
+	"time"
+
 	"DoToday/models"
 	"DoToday/repositories"
+
+	"github.com/google/uuid"
 )
 
 type CommentService struct {
-	repo *repositories.CommentRepository
+	repo     *repositories.CommentRepository
+	feedRepo *repositories.FeedRepository
+	xp       *XPService
+	filter   *ContentFilter
 }
 
-func NewCommentService(repo *repositories.CommentRepository) *CommentService {
-	return &CommentService{repo: repo}
+func NewCommentService(
+	repo *repositories.CommentRepository,
+	feedRepo *repositories.FeedRepository,
+	xp *XPService,
+	filter *ContentFilter,
+) *CommentService {
+	return &CommentService{
+		repo:     repo,
+		feedRepo: feedRepo,
+		xp:       xp,
+		filter:   filter,
+	}
 }
 
+// CreateComment comments on a post the user can see. Hidden posts and posts
+// the user cannot see because of a block or mute are not found.
 func (s *CommentService) CreateComment(comment *models.Comment) error {
-	return s.repo.Create(comment)
+	if _, err := s.feedRepo.GetByID(comment.FeedID, comment.UserID); err != nil {
+		return notFound(err, "Feed not found")
+	}
+
+	if comment.ID == "" {
+		comment.ID = uuid.NewString()
+	}
+	if comment.CreatedAt.IsZero() {
+		comment.CreatedAt = time.Now()
+	}
+
+	content, err := s.filter.Apply(&ContentInput{
+		UserID:   comment.UserID,
+		Kind:     models.ContentKindComment,
+		TargetID: comment.ID,
+		Text:     comment.Content,
+	})
+	if err != nil {
+		return err
+	}
+	comment.Content = content
+
+	if err := s.repo.Create(comment); err != nil {
+		return err
+	}
+
+	if err := s.xp.RecordComment(comment.UserID, comment.ID); err != nil {
+		log.Printf("recording comment XP failed for comment %s: %v", comment.ID, err)
+	}
+	return nil
 }
 
-func (s *CommentService) GetCommentsByFeedID(feedID string) ([]*models.Comment, error) {
-	return s.repo.GetByFeedID(feedID)
+func (s *CommentService) GetCommentsByFeedID(feedID, viewerID string) ([]*models.Comment, error) {
+	return s.repo.GetByFeedID(feedID, viewerID)
 }
 
+// DeleteComment removes one of the user's comments and takes back the XP it
+// earned.
 func (s *CommentService) DeleteComment(id, userID string) error {
-	return s.repo.Delete(id, userID)
+	comment, err := s.repo.GetByID(id)
+	if err != nil {
+		return notFound(err, "Comment not found")
+	}
+	if comment.UserID != userID {
+		return errForbidden
+	}
+
+	if err := s.repo.Delete(id, userID); err != nil {
+		return err
+	}
+
+	if err := s.xp.CommentDeleted(id); err != nil {
+		log.Printf("reversing comment XP failed for comment %s: %v", id, err)
+	}
+	return nil
 }
@@ -0,0 +1,91 @@
+package services
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+	"DoToday/models"
+	"DoToday/repositories"
+)
+
+const testFeedID = "3c4d5e6f-7a8b-4c9d-8e0f-a1b2c3d4e5f6"
+
+func newTestCommentService(db *sql.DB) *CommentService {
+	return NewCommentService(
+		repositories.NewCommentRepository(db),
+		repositories.NewFeedRepository(db),
+		newTestXPService(db),
+		nil,
+	)
+}
+
+func commentRow(id, userID string) []driver.Value {
+	return []driver.Value{id, testFeedID, userID, "Nice", false, time.Now()}
+}
+
+// feedRow is a visible post on testGoalID as the feed queries return it.
+func feedRow() []driver.Value {
+	return []driver.Value{testFeedID, testGoalID, time.Now(), "Done", false}
+}
+
+func TestDeleteCommentReversesItsXP(t *testing.T) {
+	db, fake := openFakeDB(t)
+	fake.on(`SELECT f.id, f.goal_id, f.date, f.description, f.hidden`, feedRow())
+	s := newTestCommentService(db)
+
+	first := &models.Comment{FeedID: testFeedID, UserID: testUserID, Content: "Nice"}
+	if err := s.CreateComment(first); err != nil {
+		t.Fatalf("posting: %v", err)
+	}
+	fake.on(`FROM comments WHERE id = $1`, commentRow(first.ID, testUserID))
+	if err := s.DeleteComment(first.ID, testUserID); err != nil {
+		t.Fatalf("deleting: %v", err)
+	}
+	second := &models.Comment{FeedID: testFeedID, UserID: testUserID, Content: "Nice"}
+	if err := s.CreateComment(second); err != nil {
+		t.Fatalf("posting again: %v", err)
+	}
+
+	awards := fake.ran(`INSERT INTO xp_ledger (id, user_id, amount, reason, ref,`)
+	if len(awards) != 2 {
+		t.Fatalf("got %d comment awards, want 2", len(awards))
+	}
+	reversals := fake.ran(`SELECT gen_random_uuid(), l.user_id, -l.amount`)
+	if len(reversals) != 1 || reversals[0].args[0] != "comment:"+first.ID {
+		t.Fatalf("got reversals %v, want one for the deleted comment", reversals)
+	}
+}
+
+func TestDeleteCommentByAnotherUserIsForbidden(t *testing.T) {
+	db, fake := openFakeDB(t)
+	fake.on(`FROM comments WHERE id = $1`, commentRow(testFeedID, testOtherID))
+	s := newTestCommentService(db)
+
+	if err := s.DeleteComment(testFeedID, testUserID); err != errForbidden {
+		t.Fatalf("got %v, want errForbidden", err)
+	}
+	if len(fake.ran(`DELETE FROM comments`)) != 0 || len(fake.ran(`xp_ledger`)) != 0 {
+		t.Error("a forbidden delete still wrote to the database")
+	}
+}
+
+func TestCommentsAndLikesOnPostsTheUserCannotSeeAreNotFound(t *testing.T) {
+	db, fake := openFakeDB(t)
+	comments := newTestCommentService(db)
+	likes := NewLikeService(repositories.NewLikeRepository(db), repositories.NewFeedRepository(db), newTestXPService(db))
+
+	err := comments.CreateComment(&models.Comment{FeedID: testFeedID, UserID: testUserID, Content: "Nice"})
+	if !errors.Is(err, &models.Error{Code: models.ErrorNotFound}) {
+		t.Errorf("commenting: got %v, want not found", err)
+	}
+	err = likes.CreateLike(&models.Like{FeedID: testFeedID, UserID: testUserID})
+	if !errors.Is(err, &models.Error{Code: models.ErrorNotFound}) {
+		t.Errorf("liking: got %v, want not found", err)
+	}
+	if len(fake.ran(`INSERT INTO comments`)) != 0 || len(fake.ran(`INSERT INTO likes`)) != 0 || len(fake.ran(`xp_ledger`)) != 0 {
+		t.Error("a comment or like on an invisible post was still stored")
+	}
+}
@@ -0,0 +1,229 @@
+package services
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"DoToday/config"
+	"DoToday/models"
+	"DoToday/repositories"
+
+	"github.com/google/uuid"
+)
+
+// Content filter actions, ordered from least to most severe.
+const (
+	FilterAllow  = "allow"
+	FilterMask   = "mask"
+	FilterFlag   = "flag"
+	FilterReject = "reject"
+)
+
+var filterSeverity = map[string]int{
+	FilterAllow:  0,
+	FilterMask:   1,
+	FilterFlag:   2,
+	FilterReject: 3,
+}
+
+// ContentInput is a piece of user text on its way to storage.
+type ContentInput struct {
+	UserID   string
+	Kind     string
+	TargetID string
+	Text     string
+}
+
+// RuleMatch describes why a rule fired and what should happen. Masked holds
+// the rewritten text for FilterMask matches.
+type RuleMatch struct {
+	Rule   string
+	Action string
+	Reason string
+	Masked string
+}
+
+// ContentRule is a single check in the filter pipeline. Check returns nil when
+// the input passes.
+type ContentRule interface {
+	Check(input *ContentInput) (*RuleMatch, error)
+}
+
+// ContentFilter runs user content through a list of rules. The most severe
+// action wins: reject stops the write, flag stores the content and records it
+// for review, mask stores a rewritten version.
+type ContentFilter struct {
+	rules    []ContentRule
+	flagRepo *repositories.ContentFilterRepository
+}
+
+func NewContentFilter(flagRepo *repositories.ContentFilterRepository, rules ...ContentRule) *ContentFilter {
+	return &ContentFilter{rules: rules, flagRepo: flagRepo}
+}
+
+// DefaultContentRules builds the word list, link and repeat rules from config.
+func DefaultContentRules(cfg *config.ContentFilterConfig, repo *repositories.ContentFilterRepository) []ContentRule {
+	var rules []ContentRule
+	if len(cfg.Words) > 0 {
+		rules = append(rules, NewWordListRule(cfg.Words, cfg.WordAction))
+	}
+	rules = append(rules,
+		&LinkRule{MaxLinks: cfg.MaxLinks, MaxDensity: cfg.MaxLinkDensity, Action: cfg.LinkAction},
+		&RepeatRule{repo: repo, Window: cfg.RepeatWindow, MaxRepeats: cfg.MaxRepeats, Action: cfg.RepeatAction},
+	)
+	return rules
+}
+
+// AddRule appends a rule to the end of the pipeline.
+func (f *ContentFilter) AddRule(rule ContentRule) {
+	f.rules = append(f.rules, rule)
+}
+
+// Apply screens the input and returns the text that should be stored. A
+// rejection is returned as an error starting with "content rejected".
+func (f *ContentFilter) Apply(input *ContentInput) (string, error) {
+	text, reasons, flagged, err := f.screen(input)
+	if err != nil {
+		return "", err
+	}
+	if flagged {
+		if err := f.flag(input, text, reasons); err != nil {
+			return "", err
+		}
+	}
+	return text, nil
+}
+
+// screen runs the rules without recording anything. It returns the text to
+// store, and the reasons it should be flagged for when flagged is set. Apply
+// records the flag itself; callers whose content has no owner yet record it
+// with flag once it does.
+func (f *ContentFilter) screen(input *ContentInput) (string, []string, bool, error) {
+	if f == nil {
+		return input.Text, nil, false, nil
+	}
+
+	text := input.Text
+	action := FilterAllow
+	var reasons []string
+
+	for _, rule := range f.rules {
+		match, err := rule.Check(&ContentInput{
+			UserID: input.UserID, Kind: input.Kind, TargetID: input.TargetID, Text: text,
+		})
+		if err != nil {
+			return "", nil, false, err
+		}
+		if match == nil || match.Action == FilterAllow {
+			continue
+		}
+		reasons = append(reasons, match.Reason)
+		if match.Action == FilterMask && match.Masked != "" {
+			text = match.Masked
+		}
+		if filterSeverity[match.Action] > filterSeverity[action] {
+			action = match.Action
+		}
+	}
+
+	if action == FilterReject {
+		return "", nil, false, models.Rejected("content rejected: %s", strings.Join(reasons, "; "))
+	}
+	return text, reasons, action == FilterFlag, nil
+}
+
+func (f *ContentFilter) flag(input *ContentInput, text string, reasons []string) error {
+	if f.flagRepo == nil {
+		return nil
+	}
+	var targetID *string
+	if input.TargetID != "" {
+		targetID = &input.TargetID
+	}
+	return f.flagRepo.CreateFlag(&models.ContentFlag{
+		ID:        uuid.NewString(),
+		UserID:    input.UserID,
+		Kind:      input.Kind,
+		TargetID:  targetID,
+		Content:   text,
+		Reasons:   strings.Join(reasons, "; "),
+		CreatedAt: time.Now(),
+	})
+}
+
+// WordListRule matches configured words case-insensitively on word boundaries.
+type WordListRule struct {
+	pattern *regexp.Regexp
+	Action  string
+}
+
+func NewWordListRule(words []string, action string) *WordListRule {
+	quoted := make([]string, 0, len(words))
+	for _, word := range words {
+		quoted = append(quoted, regexp.QuoteMeta(strings.ToLower(word)))
+	}
+	return &WordListRule{
+		pattern: regexp.MustCompile(`(?i)\b(` + strings.Join(quoted, "|") + `)\b`),
+		Action:  action,
+	}
+}
+
+func (r *WordListRule) Check(input *ContentInput) (*RuleMatch, error) {
+	if !r.pattern.MatchString(input.Text) {
+		return nil, nil
+	}
+	masked := r.pattern.ReplaceAllStringFunc(input.Text, func(word string) string {
+		return strings.Repeat("*", len([]rune(word)))
+	})
+	return &RuleMatch{Rule: "word_list", Action: r.Action, Reason: "contains blocked words", Masked: masked}, nil
+}
+
+var linkPattern = regexp.MustCompile(`(?i)\b(https?://|www\.)\S+`)
+
+// LinkRule catches link spam: too many links, or links making up too large a
+// share of the words.
+type LinkRule struct {
+	MaxLinks   int
+	MaxDensity float64
+	Action     string
+}
+
+func (r *LinkRule) Check(input *ContentInput) (*RuleMatch, error) {
+	links := linkPattern.FindAllString(input.Text, -1)
+	if len(links) == 0 {
+		return nil, nil
+	}
+
+	words := len(strings.Fields(input.Text))
+	density := float64(len(links)) / float64(words)
+	if len(links) <= r.MaxLinks && density <= r.MaxDensity {
+		return nil, nil
+	}
+
+	masked := linkPattern.ReplaceAllString(input.Text, "[link removed]")
+	return &RuleMatch{Rule: "links", Action: r.Action, Reason: "too many links", Masked: masked}, nil
+}
+
+// RepeatRule catches the same text being posted over and over. Content without
+// a user, such as a username at registration, has no history to repeat.
+type RepeatRule struct {
+	repo       *repositories.ContentFilterRepository
+	Window     time.Duration
+	MaxRepeats int
+	Action     string
+}
+
+func (r *RepeatRule) Check(input *ContentInput) (*RuleMatch, error) {
+	if r.repo == nil || input.UserID == "" || strings.TrimSpace(input.Text) == "" {
+		return nil, nil
+	}
+	count, err := r.repo.CountRecentDuplicates(input.UserID, input.Kind, input.Text, time.Now().Add(-r.Window))
+	if err != nil {
+		return nil, err
+	}
+	if count < r.MaxRepeats {
+		return nil, nil
+	}
+	return &RuleMatch{Rule: "repeat", Action: r.Action, Reason: "repeated post"}, nil
+}
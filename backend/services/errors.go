@@ -0,0 +1,20 @@
+package services
+
+import (
+	"database/sql"
+
+	"DoToday/models"
+)
+
+// errForbidden is returned when the user may not see or change what they
+// asked for.
+var errForbidden = models.Forbidden("Unauthorized")
+
+// notFound reports a missing row as a not found error with message and
+// passes any other error through.
+func notFound(err error, message string) error {
+	if err == sql.ErrNoRows {
+		return models.NotFound(message)
+	}
+	return err
+}
@@ -0,0 +1,241 @@
+package services
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"time"
+
+	"DoToday/models"
+	"DoToday/repositories"
+)
+
+// ExportSchemaVersion is bumped whenever the layout of the export archive
+// changes, so importers can tell which format they are reading. Version 2
+// added the value column to completions.csv and the kind and measurement
+// columns to goals.csv.
+const ExportSchemaVersion = 2
+
+const exportTimeFormat = time.RFC3339
+
+type ExportService struct {
+	userRepo       *repositories.UserRepository
+	goalRepo       *repositories.GoalRepository
+	completionRepo *repositories.CompletionRepository
+	feedRepo       *repositories.FeedRepository
+	commentRepo    *repositories.CommentRepository
+	likeRepo       *repositories.LikeRepository
+}
+
+func NewExportService(
+	userRepo *repositories.UserRepository,
+	goalRepo *repositories.GoalRepository,
+	completionRepo *repositories.CompletionRepository,
+	feedRepo *repositories.FeedRepository,
+	commentRepo *repositories.CommentRepository,
+	likeRepo *repositories.LikeRepository,
+) *ExportService {
+	return &ExportService{
+		userRepo:       userRepo,
+		goalRepo:       goalRepo,
+		completionRepo: completionRepo,
+		feedRepo:       feedRepo,
+		commentRepo:    commentRepo,
+		likeRepo:       likeRepo,
+	}
+}
+
+// exportTable is one dataset in the archive, written as <name>.json and <name>.csv.
+type exportTable struct {
+	name    string
+	records interface{}
+	header  []string
+	rows    [][]string
+}
+
+// Export writes a zip archive with the user's profile, goals (archived ones
+// included), completions, feed posts, comments and likes.
+func (s *ExportService) Export(userID string, w io.Writer) error {
+	tables, err := s.collect(userID)
+	if err != nil {
+		return err
+	}
+
+	manifest := &models.ExportManifest{
+		SchemaVersion: ExportSchemaVersion,
+		UserID:        userID,
+		GeneratedAt:   time.Now().UTC(),
+	}
+
+	zw := zip.NewWriter(w)
+	for _, table := range tables {
+		if err := writeZipJSON(zw, table.name+".json", table.records); err != nil {
+			return err
+		}
+		if err := writeZipCSV(zw, table.name+".csv", table.header, table.rows); err != nil {
+			return err
+		}
+		manifest.Files = append(manifest.Files,
+			&models.ExportFile{Name: table.name + ".json", Format: "json", Records: len(table.rows)},
+			&models.ExportFile{Name: table.name + ".csv", Format: "csv", Records: len(table.rows)},
+		)
+	}
+	if err := writeZipJSON(zw, "manifest.json", manifest); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+func (s *ExportService) collect(userID string) ([]*exportTable, error) {
+	profile, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, notFound(err, "User not found")
+	}
+
+	goals, err := s.goalRepo.GetAllByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var completions []*models.Completion
+	for _, goal := range goals {
+		goalCompletions, err := s.completionRepo.GetByGoalID(goal.ID)
+		if err != nil {
+			return nil, err
+		}
+		completions = append(completions, goalCompletions...)
+	}
+
+	feeds, err := s.feedRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	comments, err := s.commentRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	likes, err := s.likeRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	tables := []*exportTable{
+		{
+			name:    "profile",
+			records: profile,
+			header:  []string{"id", "username", "email", "created_at"},
+			rows: [][]string{{
+				profile.ID, profile.Username, profile.Email, profile.CreatedAt.Format(exportTimeFormat),
+			}},
+		},
+		{
+			name:    "goals",
+			records: nonNil(goals),
+			header: []string{
+				"id", "title", "category", "description", "frequency", "target_count",
+				"deadline", "is_public", "current_streak", "archived", "created_at",
+				"kind", "unit", "target_value", "comparison", "aggregation",
+			},
+		},
+		{
+			name:    "completions",
+			records: nonNil(completions),
+			header:  []string{"id", "goal_id", "date", "count", "value", "created_at"},
+		},
+		{
+			name:    "feeds",
+			records: nonNil(feeds),
+			header:  []string{"id", "goal_id", "date", "description", "hidden"},
+		},
+		{
+			name:    "comments",
+			records: nonNil(comments),
+			header:  []string{"id", "feed_id", "content", "hidden", "created_at"},
+		},
+		{
+			name:    "likes",
+			records: nonNil(likes),
+			header:  []string{"id", "feed_id", "created_at"},
+		},
+	}
+
+	for _, g := range goals {
+		tables[1].rows = append(tables[1].rows, []string{
+			g.ID, g.Title, g.Category, g.Description, g.Frequency, strconv.Itoa(g.TargetCount),
+			formatOptionalTime(g.Deadline), strconv.FormatBool(g.IsPublic), strconv.Itoa(g.CurrentStreak),
+			strconv.FormatBool(g.Archived), g.CreatedAt.Format(exportTimeFormat),
+			g.Kind, g.Unit, formatOptionalFloat(g.TargetValue), g.Comparison, g.Aggregation,
+		})
+	}
+	for _, c := range completions {
+		tables[2].rows = append(tables[2].rows, []string{
+			c.ID, c.GoalID, c.Date.Format("2006-01-02"), strconv.Itoa(c.Count), formatOptionalFloat(c.Value),
+			c.CreatedAt.Format(exportTimeFormat),
+		})
+	}
+	for _, f := range feeds {
+		tables[3].rows = append(tables[3].rows, []string{
+			f.ID, f.GoalID, formatOptionalTime(f.Date), f.Description, strconv.FormatBool(f.Hidden),
+		})
+	}
+	for _, c := range comments {
+		tables[4].rows = append(tables[4].rows, []string{
+			c.ID, c.FeedID, c.Content, strconv.FormatBool(c.Hidden), c.CreatedAt.Format(exportTimeFormat),
+		})
+	}
+	for _, l := range likes {
+		tables[5].rows = append(tables[5].rows, []string{
+			l.ID, l.FeedID, l.CreatedAt.Format(exportTimeFormat),
+		})
+	}
+	return tables, nil
+}
+
+// nonNil makes empty datasets encode as [] rather than null.
+func nonNil[T any](records []T) []T {
+	if records == nil {
+		return []T{}
+	}
+	return records
+}
+
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(exportTimeFormat)
+}
+
+func formatOptionalFloat(f *float64) string {
+	if f == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*f, 'f', -1, 64)
+}
+
+func writeZipJSON(zw *zip.Writer, name string, v interface{}) error {
+	f, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func writeZipCSV(zw *zip.Writer, name string, header []string, rows [][]string) error {
+	f, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	cw := csv.NewWriter(f)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	if err := cw.WriteAll(rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
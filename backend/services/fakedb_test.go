@@ -0,0 +1,227 @@
+package services
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeDB is a scripted stand-in for Postgres used by the service tests. A
+// query returns the rows of the most recently added fixture whose text
+// appears in it, and nothing otherwise. Statements are recorded with their
+// arguments and report one affected row unless told otherwise.
+type fakeDB struct {
+	mu       sync.Mutex
+	fixtures []fakeFixture
+	results  []fakeResult
+	execs    []fakeExec
+}
+
+type fakeFixture struct {
+	query string
+	rows  [][]driver.Value
+}
+
+type fakeResult struct {
+	query    string
+	affected int64
+	err      error
+}
+
+// fakeExec is a statement the code under test ran.
+type fakeExec struct {
+	query string
+	args  []driver.Value
+}
+
+// on returns rows for queries containing query, compared with whitespace
+// collapsed. Later fixtures take precedence, so a test can change what a
+// query returns between calls.
+func (db *fakeDB) on(query string, rows ...[]driver.Value) *fakeDB {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.fixtures = append(db.fixtures, fakeFixture{query: collapse(query), rows: rows})
+	return db
+}
+
+// affect makes statements containing query report n affected rows.
+func (db *fakeDB) affect(query string, n int64) *fakeDB {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.results = append(db.results, fakeResult{query: collapse(query), affected: n})
+	return db
+}
+
+// fail makes statements containing query return err.
+func (db *fakeDB) fail(query string, err error) *fakeDB {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.results = append(db.results, fakeResult{query: collapse(query), err: err})
+	return db
+}
+
+// ran returns the recorded statements containing query, oldest first.
+func (db *fakeDB) ran(query string) []fakeExec {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	query = collapse(query)
+	var execs []fakeExec
+	for _, e := range db.execs {
+		if strings.Contains(e.query, query) {
+			execs = append(execs, e)
+		}
+	}
+	return execs
+}
+
+func (db *fakeDB) rows(query string) [][]driver.Value {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	query = collapse(query)
+	for i := len(db.fixtures) - 1; i >= 0; i-- {
+		if strings.Contains(query, db.fixtures[i].query) {
+			return db.fixtures[i].rows
+		}
+	}
+	return nil
+}
+
+func (db *fakeDB) exec(query string, args []driver.Value) (driver.Result, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	query = collapse(query)
+	db.execs = append(db.execs, fakeExec{query: query, args: args})
+	for i := len(db.results) - 1; i >= 0; i-- {
+		if r := db.results[i]; strings.Contains(query, r.query) {
+			if r.err != nil {
+				return nil, r.err
+			}
+			return driver.RowsAffected(r.affected), nil
+		}
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func collapse(query string) string {
+	return strings.Join(strings.Fields(query), " ")
+}
+
+var (
+	fakeDBsMu sync.Mutex
+	fakeDBs   = map[string]*fakeDB{}
+)
+
+func init() {
+	sql.Register("fakedb", fakeDriver{})
+}
+
+// openFakeDB returns a database backed by a new fakeDB for the test.
+func openFakeDB(t *testing.T) (*sql.DB, *fakeDB) {
+	t.Helper()
+	fake := &fakeDB{}
+	fakeDBsMu.Lock()
+	fakeDBs[t.Name()] = fake
+	fakeDBsMu.Unlock()
+
+	db, err := sql.Open("fakedb", t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		db.Close()
+		fakeDBsMu.Lock()
+		delete(fakeDBs, t.Name())
+		fakeDBsMu.Unlock()
+	})
+	return db, fake
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) {
+	fakeDBsMu.Lock()
+	defer fakeDBsMu.Unlock()
+	fake, ok := fakeDBs[name]
+	if !ok {
+		return nil, fmt.Errorf("fakedb: unknown database %q", name)
+	}
+	return &fakeConn{db: fake}, nil
+}
+
+type fakeConn struct {
+	db *fakeDB
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{db: c.db, query: query}, nil
+}
+
+func (c *fakeConn) Close() error              { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error) { return fakeTx{}, nil }
+
+// CheckNamedValue passes every argument through unchanged, so tests can
+// compare them with what the code under test sent.
+func (c *fakeConn) CheckNamedValue(*driver.NamedValue) error { return nil }
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+type fakeStmt struct {
+	db    *fakeDB
+	query string
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.db.exec(s.query, args)
+}
+
+func (s *fakeStmt) Query([]driver.Value) (driver.Rows, error) {
+	rows := s.db.rows(s.query)
+	width := 1
+	if len(rows) > 0 {
+		width = len(rows[0])
+	}
+	return &fakeRows{width: width, rows: rows}, nil
+}
+
+type fakeRows struct {
+	width int
+	rows  [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string {
+	columns := make([]string, r.width)
+	for i := range columns {
+		columns[i] = fmt.Sprintf("column%d", i+1)
+	}
+	return columns
+}
+
+func (r *fakeRows) Close() error { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+// goalRow is a daily habit goal as the goal queries return it.
+func goalRow(id, userID string, createdAt time.Time) []driver.Value {
+	return []driver.Value{
+		id, userID, "Read", "Learning", nil, "", "daily", int64(1),
+		"habit", "", nil, "", "", nil, false, int64(0), false, createdAt, nil, []byte("{}"),
+	}
+}
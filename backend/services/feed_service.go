@@ -1,26 +1,88 @@
 package services
 
 import (
+	"log"
+	"time"
+
 	"DoToday/models"
 	"DoToday/repositories"
+
+	"github.com/google/uuid"
 )
 
 type FeedService struct {
-	repo *repositories.FeedRepository
+	repo     *repositories.FeedRepository
+	goalRepo *repositories.GoalRepository
+	xp       *XPService
+	filter   *ContentFilter
 }
 
-func NewFeedService(repo *repositories.FeedRepository) *FeedService {
-	return &FeedService{repo: repo}
+func NewFeedService(
+	repo *repositories.FeedRepository,
+	goalRepo *repositories.GoalRepository,
+	xp *XPService,
+	filter *ContentFilter,
+) *FeedService {
+	return &FeedService{
+		repo:     repo,
+		goalRepo: goalRepo,
+		xp:       xp,
+		filter:   filter,
+	}
 }
 
-func (s *FeedService) CreateFeed(feed *models.Feed) error {
-	return s.repo.Create(feed)
+// CreateFeed posts on one of the user's goals. A goal takes one post a day; a
+// second post for the same day is a conflict.
+func (s *FeedService) CreateFeed(userID string, feed *models.Feed) error {
+	goal, err := s.goalRepo.GetByID(feed.GoalID)
+	if err != nil {
+		return notFound(err, "Goal not found")
+	}
+	if goal.UserID != userID {
+		return errForbidden
+	}
+
+	if feed.ID == "" {
+		feed.ID = uuid.NewString()
+	}
+	if feed.Date == nil {
+		today := time.Now().UTC().Truncate(24 * time.Hour)
+		feed.Date = &today
+	}
+
+	description, err := s.filter.Apply(&ContentInput{
+		UserID:   userID,
+		Kind:     models.ContentKindFeed,
+		TargetID: feed.ID,
+		Text:     feed.Description,
+	})
+	if err != nil {
+		return err
+	}
+	feed.Description = description
+
+	inserted, err := s.repo.Create(feed)
+	if err != nil {
+		return err
+	}
+	if !inserted {
+		return models.Conflict("goal already has a post for that day")
+	}
+
+	if err := s.xp.RecordFeedPost(goal.UserID, feed.GoalID, *feed.Date); err != nil {
+		log.Printf("recording feed post XP failed for feed %s: %v", feed.ID, err)
+	}
+	return nil
 }
 
-func (s *FeedService) GetFeedByID(id string) (*models.Feed, error) {
-	return s.repo.GetByID(id)
+func (s *FeedService) GetFeedByID(id, viewerID string) (*models.Feed, error) {
+	feed, err := s.repo.GetByID(id, viewerID)
+	if err != nil {
+		return nil, notFound(err, "Feed not found")
+	}
+	return feed, nil
 }
 
-func (s *FeedService) GetFeedsByGoalID(goalID string) ([]*models.Feed, error) {
-	return s.repo.GetByGoalID(goalID)
+func (s *FeedService) GetFeedsByGoalID(goalID, viewerID string) ([]*models.Feed, error) {
+	return s.repo.GetByGoalID(goalID, viewerID)
 }
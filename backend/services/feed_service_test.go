@@ -0,0 +1,65 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"DoToday/config"
+	"DoToday/models"
+	"DoToday/repositories"
+)
+
+const (
+	testUserID  = "6f1c2a9e-3d4b-4c5e-8f70-112233445566"
+	testOtherID = "9d8c7b6a-5f4e-4d3c-a2b1-0a1b2c3d4e5f"
+	testGoalID  = "0b7e4d2c-9a18-4f36-b5c1-665544332211"
+)
+
+func newTestXPService(db *sql.DB) *XPService {
+	return NewXPService(repositories.NewXPRepository(db), repositories.NewCompletionRepository(db), config.LoadXPConfig())
+}
+
+func newTestFeedService(db *sql.DB) *FeedService {
+	return NewFeedService(repositories.NewFeedRepository(db), repositories.NewGoalRepository(db), newTestXPService(db), nil)
+}
+
+func TestCreateFeedRepeatPostIsConflictWithoutXP(t *testing.T) {
+	db, fake := openFakeDB(t)
+	fake.on(`FROM goals g WHERE g.id = $1`, goalRow(testGoalID, testUserID, time.Now().AddDate(0, 0, -10)))
+	s := newTestFeedService(db)
+
+	if err := s.CreateFeed(testUserID, &models.Feed{GoalID: testGoalID, Description: "Done"}); err != nil {
+		t.Fatalf("first post: %v", err)
+	}
+
+	fake.affect(`INSERT INTO feeds`, 0)
+	err := s.CreateFeed(testUserID, &models.Feed{GoalID: testGoalID, Description: "Done again"})
+	if !errors.Is(err, &models.Error{Code: models.ErrorConflict}) {
+		t.Fatalf("second post: got %v, want a conflict", err)
+	}
+
+	awards := fake.ran(`INSERT INTO xp_ledger`)
+	if len(awards) != 1 {
+		t.Fatalf("got %d XP awards, want 1", len(awards))
+	}
+	today := time.Now().UTC().Format(dateLayout)
+	if ref := *awards[0].args[4].(*string); ref != "feed:"+testGoalID+":"+today {
+		t.Errorf("XP ref is %q, want it keyed on the goal and day", ref)
+	}
+}
+
+func TestCreateFeedOnAnotherUsersGoalIsForbidden(t *testing.T) {
+	db, fake := openFakeDB(t)
+	fake.on(`FROM goals g WHERE g.id = $1`, goalRow(testGoalID, testOtherID, time.Now().AddDate(0, 0, -10)))
+	s := newTestFeedService(db)
+
+	err := s.CreateFeed(testUserID, &models.Feed{GoalID: testGoalID, Description: "Done"})
+	if err != errForbidden {
+		t.Fatalf("got %v, want errForbidden", err)
+	}
+	if len(fake.ran(`INSERT INTO feeds`)) != 0 || len(fake.ran(`xp_ledger`)) != 0 {
+		t.Error("a forbidden post still wrote to the database")
+	}
+}
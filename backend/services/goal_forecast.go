@@ -0,0 +1,87 @@
+package services
+
+import (
+	"math"
+	"time"
+
+	"DoToday/models"
+)
+
+// milestoneWindow is how many recent days set a milestone goal's pace.
+const milestoneWindow = 14
+
+// attachMilestone fills in the forecast of a milestone goal with a target
+// and deadline. Other goals are left alone.
+func (s *GoalService) attachMilestone(goal *models.Goal) error {
+	if goal.Kind != models.GoalKindMilestone || goal.TargetValue == nil || goal.Deadline == nil {
+		return nil
+	}
+
+	today := truncateDay(time.Now())
+	progress, err := s.completionRepo.SumProgress(goal.ID, time.Time{}, today)
+	if err != nil {
+		return err
+	}
+	windowStart := today.AddDate(0, 0, -(milestoneWindow - 1))
+	if created := truncateDay(goal.CreatedAt); created.After(windowStart) {
+		windowStart = created
+	}
+	recent, err := s.completionRepo.SumProgress(goal.ID, windowStart, today)
+	if err != nil {
+		return err
+	}
+
+	windowDays := daysBetween(windowStart, today) + 1
+	goal.Milestone = forecastMilestone(goal, progress, recent/float64(windowDays), today)
+	return nil
+}
+
+// forecastMilestone works out a milestone goal's progress, the pace needed to
+// reach the target by the deadline and when it will be reached at the recent
+// pace. A goal is behind schedule when its progress trails a straight line
+// from nothing at creation to the target at the deadline.
+func forecastMilestone(goal *models.Goal, progress, recentPace float64, today time.Time) *models.MilestoneProgress {
+	target := *goal.TargetValue
+	created, deadline := truncateDay(goal.CreatedAt), truncateDay(*goal.Deadline)
+
+	// A target of zero or less is already reached; it would otherwise give a
+	// NaN percentage, which cannot be encoded.
+	percent := 100.0
+	if target > 0 {
+		percent = math.Min(100, progress/target*100)
+	}
+	m := &models.MilestoneProgress{
+		Target:     target,
+		Progress:   progress,
+		Percent:    percent,
+		Remaining:  math.Max(0, target-progress),
+		RecentPace: recentPace,
+		Achieved:   progress >= target,
+	}
+	if !deadline.Before(today) {
+		m.DaysLeft = daysBetween(today, deadline) + 1
+	}
+	if m.Achieved {
+		return m
+	}
+
+	if m.DaysLeft > 0 {
+		m.RequiredPace = m.Remaining / float64(m.DaysLeft)
+	} else {
+		m.RequiredPace = m.Remaining
+	}
+	if recentPace > 0 {
+		projected := today.AddDate(0, 0, int(math.Ceil(m.Remaining/recentPace)))
+		m.ProjectedDate = &projected
+	}
+
+	total := math.Max(1, float64(daysBetween(created, deadline)))
+	elapsed := math.Min(total, float64(daysBetween(created, today)+1))
+	m.BehindSchedule = progress < target*elapsed/total
+	return m
+}
+
+// daysBetween counts the calendar days from a to b.
+func daysBetween(a, b time.Time) int {
+	return int(math.Round(truncateDay(b).Sub(truncateDay(a)).Hours() / 24))
+}
@@ -0,0 +1,234 @@
+package services
+
+import (
+	"math"
+	"time"
+
+	"DoToday/models"
+)
+
+const dateLayout = "2006-01-02"
+
+// truncateDay drops the time of day, keeping the calendar date in UTC.
+func truncateDay(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// periodBounds returns the first and last day of the period of the given
+// frequency containing day. Weeks start on Monday.
+func periodBounds(frequency string, day time.Time) (time.Time, time.Time) {
+	day = truncateDay(day)
+	switch frequency {
+	case models.FrequencyWeekly:
+		start := day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+		return start, start.AddDate(0, 0, 6)
+	case models.FrequencyMonthly:
+		start := time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(0, 1, -1)
+	default:
+		return day, day
+	}
+}
+
+// revisionAt returns the index of the revision in force on day, or -1 when
+// day is before the first revision. revisions must be sorted by EffectiveFrom.
+func revisionAt(revisions []*models.GoalRevision, day time.Time) int {
+	idx := -1
+	for i, rev := range revisions {
+		if truncateDay(rev.EffectiveFrom).After(day) {
+			break
+		}
+		idx = i
+	}
+	return idx
+}
+
+// meetsTarget compares a measured value with a measurable goal's target.
+func meetsTarget(value, target float64, comparison string) bool {
+	switch comparison {
+	case models.ComparisonAtMost:
+		return value <= target
+	case models.ComparisonExactly:
+		return math.Abs(value-target) < 1e-9
+	default:
+		return value >= target
+	}
+}
+
+// periodMet reports whether the values measured from start to end meet a
+// measurable goal's target. A period without measurements is never met.
+func periodMet(goal *models.Goal, completions []*models.Completion, start, end time.Time) bool {
+	var values []float64
+	for _, c := range completions {
+		day := truncateDay(c.Date)
+		if c.Value != nil && !day.Before(start) && !day.After(end) {
+			values = append(values, *c.Value)
+		}
+	}
+	if len(values) == 0 || goal.TargetValue == nil {
+		return false
+	}
+	value := aggregateValues(values, goal.Aggregation)
+	return value != nil && meetsTarget(*value, *goal.TargetValue, goal.Comparison)
+}
+
+// aggregateValues combines a period's daily values. Sum is zero for a period
+// without measurements; max and avg are nil.
+func aggregateValues(values []float64, aggregation string) *float64 {
+	var result float64
+	switch aggregation {
+	case models.AggregationMax:
+		if len(values) == 0 {
+			return nil
+		}
+		result = values[0]
+		for _, v := range values[1:] {
+			result = math.Max(result, v)
+		}
+	case models.AggregationAvg:
+		if len(values) == 0 {
+			return nil
+		}
+		for _, v := range values {
+			result += v
+		}
+		result /= float64(len(values))
+	default:
+		for _, v := range values {
+			result += v
+		}
+	}
+	return &result
+}
+
+// evaluatePeriods splits from..to into the goal's scheduled periods and
+// checks each one against the revision in force when it started. A period
+// is cut short when a new revision takes effect inside it, so no day is
+// judged by two schedules, and the last period ends at to. Periods of
+// measurable goals are judged by their aggregated value instead of the
+// completion count, and periods of quit goals are met when free of slips.
+func evaluatePeriods(goal *models.Goal, revisions []*models.GoalRevision, completions []*models.Completion, from, to time.Time) []*models.GoalPeriod {
+	counts := make(map[string]int, len(completions))
+	values := make(map[string]float64, len(completions))
+	for _, c := range completions {
+		day := c.Date.UTC().Format(dateLayout)
+		counts[day] += c.Count
+		if c.Value != nil {
+			values[day] = *c.Value
+		}
+	}
+
+	periods := []*models.GoalPeriod{}
+	from, to = truncateDay(from), truncateDay(to)
+	for day := from; !day.After(to); {
+		idx := revisionAt(revisions, day)
+		if idx < 0 {
+			day = truncateDay(revisions[0].EffectiveFrom)
+			continue
+		}
+		rev := revisions[idx]
+
+		start, end := periodBounds(rev.Frequency, day)
+		if start.Before(day) {
+			start = day
+		}
+		if end.After(to) {
+			end = to
+		}
+		if idx+1 < len(revisions) {
+			if next := truncateDay(revisions[idx+1].EffectiveFrom); !next.After(end) {
+				end = next.AddDate(0, 0, -1)
+			}
+		}
+
+		period := &models.GoalPeriod{
+			Start:       start,
+			End:         end,
+			Frequency:   rev.Frequency,
+			TargetCount: rev.TargetCount,
+		}
+		var periodValues []float64
+		for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+			period.Completions += counts[d.Format(dateLayout)]
+			if v, ok := values[d.Format(dateLayout)]; ok {
+				periodValues = append(periodValues, v)
+			}
+		}
+		switch {
+		case goal.Kind == models.GoalKindQuit:
+			period.Met = period.Completions == 0
+		case goal.Kind == models.GoalKindMeasurable && goal.TargetValue != nil:
+			period.Value = aggregateValues(periodValues, goal.Aggregation)
+			period.Met = period.Value != nil && meetsTarget(*period.Value, *goal.TargetValue, goal.Comparison)
+		default:
+			period.Met = period.Completions >= period.TargetCount
+		}
+		periods = append(periods, period)
+
+		day = end.AddDate(0, 0, 1)
+	}
+	return periods
+}
+
+// duePeriods evaluates the goal's periods overlapping from..to and keeps the
+// ones that are due: finished periods, and the period still running once it
+// is met. Periods are whole, so a weekly goal is not judged on the days of
+// its first week that fall inside the window, and none start before the goal
+// was created.
+func duePeriods(goal *models.Goal, revisions []*models.GoalRevision, completions []*models.Completion, from, to time.Time) []*models.GoalPeriod {
+	to = truncateDay(to)
+	start, _ := periodBounds(goal.Frequency, from)
+	if created := truncateDay(goal.CreatedAt); created.After(start) {
+		start = created
+	}
+
+	var due []*models.GoalPeriod
+	for _, p := range evaluatePeriods(goal, revisions, completions, start, to) {
+		if p.Met || p.End.Before(to) {
+			due = append(due, p)
+		}
+	}
+	return due
+}
+
+// consistencyScore rewards an even spread: the mean of the met rates per
+// week (per period for weekly and monthly periods) times one minus their
+// standard deviation, out of 100.
+func consistencyScore(periods []*models.GoalPeriod) float64 {
+	type bucket struct{ met, due int }
+	buckets := map[time.Time]*bucket{}
+	for _, p := range periods {
+		key := p.Start
+		if p.Frequency == models.FrequencyDaily {
+			key, _ = periodBounds(models.FrequencyWeekly, p.Start)
+		}
+		b := buckets[key]
+		if b == nil {
+			b = &bucket{}
+			buckets[key] = b
+		}
+		b.due++
+		if p.Met {
+			b.met++
+		}
+	}
+	if len(buckets) == 0 {
+		return 0
+	}
+
+	rates := make([]float64, 0, len(buckets))
+	var mean float64
+	for _, b := range buckets {
+		rate := float64(b.met) / float64(b.due)
+		rates = append(rates, rate)
+		mean += rate
+	}
+	mean /= float64(len(rates))
+	var variance float64
+	for _, rate := range rates {
+		variance += (rate - mean) * (rate - mean)
+	}
+	variance /= float64(len(rates))
+	return mean * (1 - math.Sqrt(variance)) * 100
+}
@@ -2,7 +2,9 @@
 package services
 
 import (
-	"errors"
+	"database/sql"
+	"log"
+	"strings"
 	"time"
 
 	"DoToday/models"
@@ -11,46 +13,233 @@ import (
 	"github.com/google/uuid"
 )
 
+// GoalEventListener is notified after goals are created, completed, trashed,
+// restored or purged from the trash and after completions are removed.
+// GoalChanged covers every other change to a goal or its history, such as
+// edits, archiving, slips and imports. Errors are logged rather than failing
+// the request, since the write already happened.
+type GoalEventListener interface {
+	GoalCreated(goal *models.Goal) error
+	GoalCompleted(goal *models.Goal, completion *models.Completion, streak int) error
+	CompletionRemoved(goal *models.Goal, completion *models.Completion) error
+	GoalChanged(goal *models.Goal) error
+	GoalTrashed(goal *models.Goal) error
+	GoalRestored(goal *models.Goal) error
+	GoalDeleted(goal *models.Goal) error
+}
+
+var errAlreadyCompleted = models.Conflict("already completed today")
+
 type GoalService struct {
 	goalRepo       *repositories.GoalRepository
 	completionRepo *repositories.CompletionRepository
+	shareRepo      *repositories.GoalShareRepository
+	orgRepo        *repositories.OrganizationRepository
+	categories     *CategoryService
+	filter         *ContentFilter
+	listeners      []GoalEventListener
 }
 
-func NewGoalService(goalRepo *repositories.GoalRepository, completionRepo *repositories.CompletionRepository) *GoalService {
+func NewGoalService(
+	goalRepo *repositories.GoalRepository,
+	completionRepo *repositories.CompletionRepository,
+	shareRepo *repositories.GoalShareRepository,
+	orgRepo *repositories.OrganizationRepository,
+	categories *CategoryService,
+	filter *ContentFilter,
+) *GoalService {
 	return &GoalService{
 		goalRepo:       goalRepo,
 		completionRepo: completionRepo,
+		shareRepo:      shareRepo,
+		orgRepo:        orgRepo,
+		categories:     categories,
+		filter:         filter,
+	}
+}
+
+// AddListener registers a listener for goal events.
+func (s *GoalService) AddListener(listener GoalEventListener) {
+	s.listeners = append(s.listeners, listener)
+}
+
+func (s *GoalService) notifyChanged(goal *models.Goal) {
+	for _, listener := range s.listeners {
+		if err := listener.GoalChanged(goal); err != nil {
+			log.Printf("goal changed listener failed for goal %s: %v", goal.ID, err)
+		}
 	}
 }
 
+// canView reports whether the user may see a goal with its completions and
+// streak: the owner, anyone for public goals, users it was shared with, and
+// members of teams it was shared with.
+func (s *GoalService) canView(goal *models.Goal, userID string) (bool, error) {
+	if goal.UserID == userID || goal.IsPublic {
+		return true, nil
+	}
+	_, err := s.shareRepo.GetRole(goal.ID, userID)
+	if err == nil {
+		return true, nil
+	} else if err != sql.ErrNoRows {
+		return false, err
+	}
+	return s.orgRepo.IsGoalVisibleToMember(goal.ID, userID)
+}
+
 func (s *GoalService) CreateGoal(userID string, req *models.CreateGoalRequest) (*models.Goal, error) {
+	goal, err := s.createGoal(userID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, listener := range s.listeners {
+		if err := listener.GoalCreated(goal); err != nil {
+			log.Printf("goal created listener failed for goal %s: %v", goal.ID, err)
+		}
+	}
+
+	return goal, nil
+}
+
+// CreateImportedGoal creates a goal for history brought in from elsewhere.
+// GoalCreated is not fired, so the import awards no XP or achievements.
+func (s *GoalService) CreateImportedGoal(userID string, req *models.CreateGoalRequest) (*models.Goal, error) {
+	return s.createGoal(userID, req)
+}
+
+func (s *GoalService) createGoal(userID string, req *models.CreateGoalRequest) (*models.Goal, error) {
 	// Always set TargetCount to at least 1
 	targetCount := req.TargetCount
 	if targetCount < 1 {
 		targetCount = 1
 	}
+	frequency := req.Frequency
+	switch frequency {
+	case "":
+		frequency = models.FrequencyDaily
+	case models.FrequencyDaily, models.FrequencyWeekly, models.FrequencyMonthly:
+	default:
+		// Imports do not go through request binding.
+		return nil, models.InvalidFields("invalid frequency",
+			models.FieldError{Field: "frequency", Message: "must be one of daily, weekly, monthly"})
+	}
+	category, err := s.categories.Resolve(userID, req.CategoryID, req.Category)
+	if err != nil {
+		return nil, categoryError(err)
+	}
+	var deadline *time.Time
+	if req.Deadline != nil && !req.Deadline.IsZero() {
+		deadline = req.Deadline
+	}
+	measure, err := newMeasure(req, deadline)
+	if err != nil {
+		return nil, err
+	}
+	goalID := uuid.NewString()
+	title, err := s.filter.Apply(&ContentInput{
+		UserID:   userID,
+		Kind:     models.ContentKindGoalTitle,
+		TargetID: goalID,
+		Text:     req.Title,
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	goal := &models.Goal{
-		ID:            uuid.NewString(),
+		ID:            goalID,
 		UserID:        userID,
-		Title:         req.Title,
-		Category:      req.Category,
+		Title:         title,
+		Category:      category.Name,
+		CategoryID:    &category.ID,
 		Description:   req.Description,
-		Frequency:     req.Frequency,
+		Frequency:     frequency,
 		TargetCount:   targetCount,
-		Deadline:      &req.Deadline,
+		Kind:          measure.Kind,
+		Unit:          measure.Unit,
+		TargetValue:   measure.TargetValue,
+		Comparison:    measure.Comparison,
+		Aggregation:   measure.Aggregation,
+		Deadline:      deadline,
 		IsPublic:      req.IsPublic,
 		CurrentStreak: 0,
 		Archived:      false,
 		CreatedAt:     time.Now(),
+		Tags:          normalizeTags(req.Tags),
 	}
 
 	if err := s.goalRepo.Create(goal); err != nil {
 		return nil, err
 	}
-
 	return goal, nil
 }
 
+// newMeasure validates the kind and measurement settings of a new goal and
+// fills in their defaults. Only measurable and milestone goals keep a unit
+// and target value.
+func newMeasure(req *models.CreateGoalRequest, deadline *time.Time) (*models.Goal, error) {
+	switch req.Kind {
+	case models.GoalKindMeasurable:
+	case models.GoalKindMilestone:
+		if req.TargetValue == nil || *req.TargetValue <= 0 || deadline == nil || !deadline.After(time.Now()) {
+			return nil, models.Invalid("milestone goals need a target value and a future deadline")
+		}
+		return &models.Goal{
+			Kind:        models.GoalKindMilestone,
+			Unit:        strings.TrimSpace(req.Unit),
+			TargetValue: req.TargetValue,
+			Aggregation: models.AggregationSum,
+		}, nil
+	case models.GoalKindQuit:
+		return &models.Goal{Kind: models.GoalKindQuit}, nil
+	default:
+		return &models.Goal{Kind: models.GoalKindHabit}, nil
+	}
+	if strings.TrimSpace(req.Unit) == "" || req.TargetValue == nil {
+		return nil, models.Invalid("measurable goals need a unit and target value")
+	}
+	measure := &models.Goal{
+		Kind:        models.GoalKindMeasurable,
+		Unit:        strings.TrimSpace(req.Unit),
+		TargetValue: req.TargetValue,
+		Comparison:  req.Comparison,
+		Aggregation: req.Aggregation,
+	}
+	if measure.Comparison == "" {
+		measure.Comparison = models.ComparisonAtLeast
+	}
+	if measure.Aggregation == "" {
+		measure.Aggregation = models.AggregationSum
+	}
+	return measure, nil
+}
+
+// normalizeTags lowercases tags, drops a leading '#' and removes blanks and
+// duplicates.
+func normalizeTags(tags []string) []string {
+	normalized := []string{}
+	seen := make(map[string]bool)
+	for _, tag := range tags {
+		tag = normalizeTag(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized
+}
+
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(tag), "#")))
+}
+
+// GetTags returns the tags the user has put on their goals.
+func (s *GoalService) GetTags(userID string) ([]*models.TagCount, error) {
+	return s.goalRepo.GetTags(userID)
+}
+
 func (s *GoalService) GetUserGoals(userID string) ([]*models.Goal, error) {
 	goals, err := s.goalRepo.GetByUserID(userID)
 	if err != nil {
@@ -67,6 +256,9 @@ func (s *GoalService) GetUserGoals(userID string) ([]*models.Goal, error) {
 		} else {
 			goal.Completions = completions
 		}
+		if err := s.attachMilestone(goal); err != nil {
+			return nil, err
+		}
 	}
 	return goals, nil
 }
@@ -74,12 +266,19 @@ func (s *GoalService) GetUserGoals(userID string) ([]*models.Goal, error) {
 func (s *GoalService) GetGoalByID(goalID, userID string) (*models.Goal, error) {
 	goal, err := s.goalRepo.GetByID(goalID)
 	if err != nil {
-		return nil, err
+		return nil, notFound(err, "Goal not found")
 	}
 
-	// Check if user owns the goal or if it's public
-	if goal.UserID != userID && !goal.IsPublic {
-		return nil, errors.New("unauthorized")
+	// Check if user owns the goal, it's public, or it was shared with them
+	allowed, err := s.canView(goal, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, errForbidden
+	}
+	if err := s.attachMilestone(goal); err != nil {
+		return nil, err
 	}
 
 	return goal, nil
@@ -88,22 +287,54 @@ func (s *GoalService) GetGoalByID(goalID, userID string) (*models.Goal, error) {
 func (s *GoalService) UpdateGoal(goalID, userID string, req *models.UpdateGoalRequest) (*models.Goal, error) {
 	goal, err := s.goalRepo.GetByID(goalID)
 	if err != nil {
-		return nil, err
+		return nil, notFound(err, "Goal not found")
 	}
 
 	if goal.UserID != userID {
-		return nil, errors.New("unauthorized")
+		return nil, errForbidden
 	}
 
 	// Update fields if provided
-	if req.Title != nil {
-		goal.Title = *req.Title
+	if req.Title != nil && *req.Title != goal.Title {
+		title, err := s.filter.Apply(&ContentInput{
+			UserID:   userID,
+			Kind:     models.ContentKindGoalTitle,
+			TargetID: goalID,
+			Text:     *req.Title,
+		})
+		if err != nil {
+			return nil, err
+		}
+		goal.Title = title
+	}
+	if req.CategoryID != nil || req.Category != nil {
+		var categoryID, name string
+		if req.CategoryID != nil {
+			categoryID = *req.CategoryID
+		}
+		if req.Category != nil {
+			name = *req.Category
+		}
+		category, err := s.categories.Resolve(userID, categoryID, name)
+		if err != nil {
+			return nil, categoryError(err)
+		}
+		goal.Category = category.Name
+		goal.CategoryID = &category.ID
 	}
 	if req.Description != nil {
 		goal.Description = *req.Description
 	}
 	if req.Deadline != nil {
-		goal.Deadline = req.Deadline
+		// A zero deadline clears it, as it does on create.
+		goal.Deadline = nil
+		if !req.Deadline.IsZero() {
+			goal.Deadline = req.Deadline
+		}
+		if goal.Kind == models.GoalKindMilestone && (goal.Deadline == nil || !goal.Deadline.After(time.Now())) {
+			return nil, models.InvalidFields("milestone goals need a future deadline",
+				models.FieldError{Field: "deadline", Message: "must be in the future"})
+		}
 	}
 	if req.IsPublic != nil {
 		goal.IsPublic = *req.IsPublic
@@ -111,48 +342,381 @@ func (s *GoalService) UpdateGoal(goalID, userID string, req *models.UpdateGoalRe
 	if req.Archived != nil {
 		goal.Archived = *req.Archived
 	}
+	if req.Tags != nil {
+		goal.Tags = normalizeTags(*req.Tags)
+	}
+	if req.Unit != nil || req.TargetValue != nil || req.Comparison != nil || req.Aggregation != nil {
+		// Milestones take a unit and total but always add their values up.
+		milestone := goal.Kind == models.GoalKindMilestone && req.Comparison == nil && req.Aggregation == nil
+		if goal.Kind != models.GoalKindMeasurable && !milestone {
+			return nil, models.Invalid("goal is not measurable")
+		}
+		if req.Unit != nil {
+			goal.Unit = strings.TrimSpace(*req.Unit)
+		}
+		if req.TargetValue != nil {
+			if goal.Kind == models.GoalKindMilestone && *req.TargetValue <= 0 {
+				return nil, models.InvalidFields("milestone goals need a target value above zero",
+					models.FieldError{Field: "target_value", Message: "must be greater than 0"})
+			}
+			goal.TargetValue = req.TargetValue
+		}
+		if req.Comparison != nil {
+			goal.Comparison = *req.Comparison
+		}
+		if req.Aggregation != nil {
+			goal.Aggregation = *req.Aggregation
+		}
+	}
 
-	if err := s.goalRepo.Update(goal); err != nil {
+	rev, err := s.scheduleRevision(goal, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.goalRepo.Update(goal, rev); err != nil {
+		return nil, err
+	}
+	s.notifyChanged(goal)
+	if err := s.attachMilestone(goal); err != nil {
 		return nil, err
 	}
 
 	return goal, nil
 }
 
+// scheduleRevision builds the revision for a frequency or target change and
+// sets the goal's schedule to whichever revision is in force today. It
+// returns nil when the schedule is unchanged.
+func (s *GoalService) scheduleRevision(goal *models.Goal, req *models.UpdateGoalRequest) (*models.GoalRevision, error) {
+	if req.Frequency == nil && req.TargetCount == nil {
+		return nil, nil
+	}
+
+	revisions, err := s.goalRepo.GetRevisions(goal.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	today := truncateDay(time.Now())
+	effective := today
+	if req.EffectiveFrom != nil {
+		if effective, err = time.Parse(dateLayout, *req.EffectiveFrom); err != nil {
+			return nil, models.Invalid("invalid effective date")
+		}
+	}
+	if effective.After(today) {
+		return nil, models.Invalid("effective date cannot be in the future")
+	}
+	if effective.Before(truncateDay(goal.CreatedAt)) {
+		return nil, models.Invalid("effective date is before the goal was created")
+	}
+
+	// Start from the schedule in force on the effective date.
+	rev := &models.GoalRevision{
+		ID:            uuid.NewString(),
+		GoalID:        goal.ID,
+		Frequency:     goal.Frequency,
+		TargetCount:   goal.TargetCount,
+		EffectiveFrom: effective,
+		CreatedAt:     time.Now(),
+	}
+	if idx := revisionAt(revisions, effective); idx >= 0 {
+		rev.Frequency = revisions[idx].Frequency
+		rev.TargetCount = revisions[idx].TargetCount
+	}
+	if req.Frequency != nil {
+		rev.Frequency = *req.Frequency
+	}
+	if req.TargetCount != nil {
+		rev.TargetCount = *req.TargetCount
+	}
+
+	// A backdated revision only changes the current schedule if no later
+	// revision has already replaced it.
+	merged := []*models.GoalRevision{}
+	for _, r := range revisions {
+		if !truncateDay(r.EffectiveFrom).Equal(effective) {
+			merged = append(merged, r)
+		}
+	}
+	merged = append(merged, rev)
+	current := rev
+	for _, r := range merged {
+		if !truncateDay(r.EffectiveFrom).After(today) && truncateDay(r.EffectiveFrom).After(truncateDay(current.EffectiveFrom)) {
+			current = r
+		}
+	}
+	goal.Frequency = current.Frequency
+	goal.TargetCount = current.TargetCount
+	return rev, nil
+}
+
+func (s *GoalService) GetRevisions(goalID, userID string) ([]*models.GoalRevision, error) {
+	goal, err := s.goalRepo.GetByID(goalID)
+	if err != nil {
+		return nil, notFound(err, "Goal not found")
+	}
+
+	allowed, err := s.canView(goal, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, errForbidden
+	}
+
+	return s.goalRepo.GetRevisions(goalID)
+}
+
+// revisions returns the goal's schedule revisions, falling back to its
+// current schedule for goals without any.
+func (s *GoalService) revisions(goal *models.Goal) ([]*models.GoalRevision, error) {
+	revisions, err := s.goalRepo.GetRevisions(goal.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(revisions) == 0 {
+		revisions = []*models.GoalRevision{{
+			GoalID:        goal.ID,
+			Frequency:     goal.Frequency,
+			TargetCount:   goal.TargetCount,
+			EffectiveFrom: goal.CreatedAt,
+		}}
+	}
+	return revisions, nil
+}
+
+// GetPeriods evaluates the goal's scheduled periods between from and to,
+// each against the schedule that was in force when it started.
+func (s *GoalService) GetPeriods(goalID, userID string, from, to time.Time) ([]*models.GoalPeriod, error) {
+	goal, err := s.goalRepo.GetByID(goalID)
+	if err != nil {
+		return nil, notFound(err, "Goal not found")
+	}
+
+	allowed, err := s.canView(goal, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, errForbidden
+	}
+
+	revisions, err := s.revisions(goal)
+	if err != nil {
+		return nil, err
+	}
+
+	completions, err := s.completionRepo.GetByGoalID(goalID)
+	if err != nil {
+		return nil, err
+	}
+
+	return evaluatePeriods(goal, revisions, completions, from, to), nil
+}
+
+// DeleteGoal moves the goal to the trash, where it stays for trashRetention
+// before it is purged.
 func (s *GoalService) DeleteGoal(goalID, userID string) error {
 	goal, err := s.goalRepo.GetByID(goalID)
 	if err != nil {
+		return notFound(err, "Goal not found")
+	}
+
+	if goal.UserID != userID {
+		return errForbidden
+	}
+
+	if err := s.goalRepo.Trash(goalID, userID, time.Now()); err != nil {
 		return err
 	}
 
+	for _, listener := range s.listeners {
+		if err := listener.GoalTrashed(goal); err != nil {
+			log.Printf("goal trashed listener failed for goal %s: %v", goal.ID, err)
+		}
+	}
+	return nil
+}
+
+// trashRetention is how long deleted goals can be restored.
+const trashRetention = 30 * 24 * time.Hour
+
+func (s *GoalService) GetTrash(userID string) ([]*models.Goal, error) {
+	return s.goalRepo.GetTrashed(userID, nil)
+}
+
+func (s *GoalService) getTrashedGoal(goalID, userID string) (*models.Goal, error) {
+	goal, err := s.goalRepo.GetTrashedByID(goalID)
+	if err != nil {
+		return nil, notFound(err, "Goal not found in trash")
+	}
 	if goal.UserID != userID {
-		return errors.New("unauthorized")
+		return nil, errForbidden
+	}
+	return goal, nil
+}
+
+func (s *GoalService) RestoreGoal(goalID, userID string) (*models.Goal, error) {
+	goal, err := s.getTrashedGoal(goalID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.goalRepo.Restore(goalID, userID); err != nil {
+		return nil, err
+	}
+	goal.DeletedAt = nil
+
+	for _, listener := range s.listeners {
+		if err := listener.GoalRestored(goal); err != nil {
+			log.Printf("goal restored listener failed for goal %s: %v", goal.ID, err)
+		}
+	}
+	return goal, nil
+}
+
+// PurgeGoal permanently deletes a goal from the trash.
+func (s *GoalService) PurgeGoal(goalID, userID string) error {
+	goal, err := s.getTrashedGoal(goalID, userID)
+	if err != nil {
+		return err
+	}
+	return s.purge(goal)
+}
+
+// PurgeExpiredTrash permanently deletes goals that have been in the trash for
+// longer than trashRetention.
+func (s *GoalService) PurgeExpiredTrash() error {
+	cutoff := time.Now().Add(-trashRetention)
+	goals, err := s.goalRepo.GetTrashed("", &cutoff)
+	if err != nil {
+		return err
+	}
+	for _, goal := range goals {
+		if err := s.purge(goal); err != nil {
+			log.Printf("purging goal %s failed: %v", goal.ID, err)
+		}
+	}
+	return nil
+}
+
+func (s *GoalService) purge(goal *models.Goal) error {
+	if err := s.goalRepo.Delete(goal.ID, goal.UserID); err != nil {
+		return err
 	}
 
-	return s.goalRepo.Delete(goalID, userID)
+	for _, listener := range s.listeners {
+		if err := listener.GoalDeleted(goal); err != nil {
+			log.Printf("goal deleted listener failed for goal %s: %v", goal.ID, err)
+		}
+	}
+	return nil
 }
 
 func (s *GoalService) ArchiveGoal(goalID, userID string) error {
 	goal, err := s.goalRepo.GetByID(goalID)
 	if err != nil {
+		return notFound(err, "Goal not found")
+	}
+
+	if goal.UserID != userID {
+		return errForbidden
+	}
+
+	if err := s.goalRepo.Archive(goalID, userID); err != nil {
 		return err
 	}
+	goal.Archived = true
+	s.notifyChanged(goal)
+	return nil
+}
+
+// GetArchivedGoals returns the user's archived goals with their completion
+// history and stats.
+func (s *GoalService) GetArchivedGoals(userID string) ([]*models.Goal, error) {
+	goals, err := s.goalRepo.GetArchivedByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, goal := range goals {
+		completions, err := s.completionRepo.GetByGoalID(goal.ID)
+		if err != nil {
+			return nil, err
+		}
+		goal.Completions = completions
+		if goal.Stats, err = s.goalStats(goal); err != nil {
+			return nil, err
+		}
+		if err := s.attachMilestone(goal); err != nil {
+			return nil, err
+		}
+	}
+	return goals, nil
+}
+
+// UnarchiveGoal brings an archived goal back to the active list with its
+// streak recalculated. A goal unarchived after its deadline is not
+// auto-archived again until it is given a later deadline.
+func (s *GoalService) UnarchiveGoal(goalID, userID string) (*models.Goal, error) {
+	goal, err := s.goalRepo.GetByID(goalID)
+	if err != nil {
+		return nil, notFound(err, "Goal not found")
+	}
 
 	if goal.UserID != userID {
-		return errors.New("unauthorized")
+		return nil, errForbidden
 	}
+	if !goal.Archived {
+		return nil, models.Conflict("goal is not archived")
+	}
+
+	if err := s.goalRepo.Unarchive(goalID, userID); err != nil {
+		return nil, err
+	}
+	goal.Archived = false
 
-	return s.goalRepo.Archive(goalID, userID)
+	streak, err := s.currentStreak(goal)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.goalRepo.UpdateStreak(goalID, streak); err != nil {
+		return nil, err
+	}
+	goal.CurrentStreak = streak
+	s.notifyChanged(goal)
+	return goal, nil
+}
+
+// AutoArchiveExpired archives every active goal whose deadline has passed.
+func (s *GoalService) AutoArchiveExpired() error {
+	goals, err := s.goalRepo.ArchiveExpired(time.Now())
+	if err != nil {
+		return err
+	}
+	for _, goal := range goals {
+		s.notifyChanged(goal)
+	}
+	if len(goals) > 0 {
+		log.Printf("auto-archived %d goals past their deadline", len(goals))
+	}
+	return nil
 }
 
 func (s *GoalService) MarkComplete(goalID, userID string) error {
 	goal, err := s.goalRepo.GetByID(goalID)
 	if err != nil {
-		return err
+		return notFound(err, "Goal not found")
 	}
 
 	if goal.UserID != userID {
-		return errors.New("unauthorized")
+		return errForbidden
+	}
+	if goal.Kind == models.GoalKindMeasurable {
+		return models.Invalid("measurable goals are completed by recording a value")
+	}
+	if goal.Kind == models.GoalKindQuit {
+		return models.Invalid("quit goals log slips instead of completions")
 	}
 
 	today := time.Now().UTC().Truncate(24 * time.Hour)
@@ -164,7 +728,7 @@ func (s *GoalService) MarkComplete(goalID, userID string) error {
 	}
 
 	if exists {
-		return errors.New("already completed today")
+		return errAlreadyCompleted
 	}
 
 	// Create completion
@@ -186,17 +750,236 @@ func (s *GoalService) MarkComplete(goalID, userID string) error {
 		return err
 	}
 
-	return s.goalRepo.UpdateStreak(goalID, streak)
+	if err := s.goalRepo.UpdateStreak(goalID, streak); err != nil {
+		return err
+	}
+	goal.CurrentStreak = streak
+
+	for _, listener := range s.listeners {
+		if err := listener.GoalCompleted(goal, completion, streak); err != nil {
+			log.Printf("goal completed listener failed for goal %s: %v", goalID, err)
+		}
+	}
+	return nil
 }
 
-func (s *GoalService) GetCompletions(goalID, userID string) ([]*models.Completion, error) {
+// RecordMeasurement logs a value for today on a measurable or milestone goal.
+// A measurable goal is completed, notifying listeners like MarkComplete, by
+// the measurement that makes its current period meet the target; values that
+// miss it are kept but earn nothing. Milestones have no daily target, so the
+// day's first measurement counts as the day's completion.
+func (s *GoalService) RecordMeasurement(goalID, userID string, value float64) (*models.Completion, error) {
 	goal, err := s.goalRepo.GetByID(goalID)
+	if err != nil {
+		return nil, notFound(err, "Goal not found")
+	}
+
+	if goal.UserID != userID {
+		return nil, errForbidden
+	}
+	if goal.Kind != models.GoalKindMeasurable && goal.Kind != models.GoalKindMilestone {
+		return nil, models.Invalid("goal does not record values")
+	}
+	if value < 0 {
+		return nil, models.Invalid("value must not be negative")
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	start, end := periodBounds(goal.Frequency, today)
+	var history []*models.Completion
+	metBefore := false
+	if goal.Kind == models.GoalKindMeasurable {
+		if history, err = s.completionRepo.GetByGoalID(goalID); err != nil {
+			return nil, err
+		}
+		metBefore = periodMet(goal, history, start, end)
+	}
+
+	completion := &models.Completion{
+		ID:        uuid.NewString(),
+		GoalID:    goalID,
+		Date:      today,
+		Value:     &value,
+		CreatedAt: time.Now(),
+	}
+	first, err := s.completionRepo.RecordMeasurement(completion, goal.Aggregation)
 	if err != nil {
 		return nil, err
 	}
 
-	if goal.UserID != userID && !goal.IsPublic {
-		return nil, errors.New("unauthorized")
+	completed := first
+	if goal.Kind == models.GoalKindMeasurable {
+		// Swap in today's new total before checking the period again.
+		updated := []*models.Completion{completion}
+		for _, c := range history {
+			if !truncateDay(c.Date).Equal(today) {
+				updated = append(updated, c)
+			}
+		}
+		completed = !metBefore && periodMet(goal, updated, start, end)
+	}
+
+	// Every measurement can change whether today counts towards the streak.
+	streak, err := s.completionRepo.CalculateCurrentStreak(goalID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.goalRepo.UpdateStreak(goalID, streak); err != nil {
+		return nil, err
+	}
+	goal.CurrentStreak = streak
+	if !completed {
+		s.notifyChanged(goal)
+		return completion, nil
+	}
+
+	for _, listener := range s.listeners {
+		if err := listener.GoalCompleted(goal, completion, streak); err != nil {
+			log.Printf("goal completed listener failed for goal %s: %v", goalID, err)
+		}
+	}
+	return completion, nil
+}
+
+// RecordSlip logs a slip on a quit goal, today unless a date is given.
+// Slips restart the clean run; they earn nothing and only notify listeners
+// that the goal changed.
+func (s *GoalService) RecordSlip(goalID, userID string, date *string) (*models.Completion, error) {
+	goal, err := s.goalRepo.GetByID(goalID)
+	if err != nil {
+		return nil, notFound(err, "Goal not found")
+	}
+
+	if goal.UserID != userID {
+		return nil, errForbidden
+	}
+	if goal.Kind != models.GoalKindQuit {
+		return nil, models.Invalid("goal is not a quit goal")
+	}
+
+	day := truncateDay(time.Now())
+	if date != nil {
+		if day, err = time.Parse(dateLayout, *date); err != nil {
+			return nil, models.Invalid("invalid slip date")
+		}
+		if day.After(truncateDay(time.Now())) {
+			return nil, models.Invalid("slip date cannot be in the future")
+		}
+		if day.Before(truncateDay(goal.CreatedAt)) {
+			return nil, models.Invalid("slip date is before the goal was created")
+		}
+	}
+
+	slip := &models.Completion{
+		ID:        uuid.NewString(),
+		GoalID:    goalID,
+		Date:      day,
+		Count:     1,
+		CreatedAt: time.Now(),
+	}
+	if err := s.completionRepo.Create(slip); err != nil {
+		return nil, err
+	}
+
+	streak, err := s.currentStreak(goal)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.goalRepo.UpdateStreak(goalID, streak); err != nil {
+		return nil, err
+	}
+	goal.CurrentStreak = streak
+	s.notifyChanged(goal)
+	return slip, nil
+}
+
+// currentStreak is the run of completed days for most goals and the days
+// since the last slip for quit goals.
+func (s *GoalService) currentStreak(goal *models.Goal) (int, error) {
+	if goal.Kind == models.GoalKindQuit {
+		return s.completionRepo.CalculateCleanRun(goal.ID)
+	}
+	return s.completionRepo.CalculateCurrentStreak(goal.ID)
+}
+
+// GetGraph returns the goal's daily completions, and measured values for
+// measurable goals, over the last days days.
+func (s *GoalService) GetGraph(goalID, userID string, days int) ([]models.CompletionGraphData, error) {
+	goal, err := s.goalRepo.GetByID(goalID)
+	if err != nil {
+		return nil, notFound(err, "Goal not found")
+	}
+
+	allowed, err := s.canView(goal, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, errForbidden
+	}
+
+	if days <= 0 || days > 365 {
+		days = 30
+	}
+	return s.completionRepo.GetCompletionGraphData(goalID, days-1)
+}
+
+// RemoveCompletion undoes the completion logged on the given day and
+// recalculates the goal's streak.
+func (s *GoalService) RemoveCompletion(goalID, userID string, date time.Time) error {
+	goal, err := s.goalRepo.GetByID(goalID)
+	if err != nil {
+		return notFound(err, "Goal not found")
+	}
+
+	if goal.UserID != userID {
+		return errForbidden
+	}
+
+	completion, err := s.completionRepo.GetByGoalAndDate(goalID, date)
+	if err == sql.ErrNoRows {
+		return models.NotFound("completion not found")
+	} else if err != nil {
+		return err
+	}
+
+	if err := s.completionRepo.Delete(completion.ID); err != nil {
+		return err
+	}
+
+	streak, err := s.currentStreak(goal)
+	if err != nil {
+		return err
+	}
+	if err := s.goalRepo.UpdateStreak(goalID, streak); err != nil {
+		return err
+	}
+	goal.CurrentStreak = streak
+
+	// Slips earned nothing, so there is nothing to reverse.
+	if goal.Kind == models.GoalKindQuit {
+		return nil
+	}
+	for _, listener := range s.listeners {
+		if err := listener.CompletionRemoved(goal, completion); err != nil {
+			log.Printf("completion removed listener failed for goal %s: %v", goalID, err)
+		}
+	}
+	return nil
+}
+
+func (s *GoalService) GetCompletions(goalID, userID string) ([]*models.Completion, error) {
+	goal, err := s.goalRepo.GetByID(goalID)
+	if err != nil {
+		return nil, notFound(err, "Goal not found")
+	}
+
+	allowed, err := s.canView(goal, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, errForbidden
 	}
 
 	return s.completionRepo.GetByGoalID(goalID)
@@ -204,13 +987,26 @@ func (s *GoalService) GetCompletions(goalID, userID string) ([]*models.Completio
 
 func (s *GoalService) GetStreak(goalID, userID string) (*models.StreakResponse, error) {
 	goal, err := s.goalRepo.GetByID(goalID)
+	if err != nil {
+		return nil, notFound(err, "Goal not found")
+	}
+
+	allowed, err := s.canView(goal, userID)
 	if err != nil {
 		return nil, err
 	}
+	if !allowed {
+		return nil, errForbidden
+	}
+
+	return s.goalStats(goal)
+}
 
-	if goal.UserID != userID && !goal.IsPublic {
-		return nil, errors.New("unauthorized")
+func (s *GoalService) goalStats(goal *models.Goal) (*models.StreakResponse, error) {
+	if goal.Kind == models.GoalKindQuit {
+		return s.quitStats(goal)
 	}
+	goalID := goal.ID
 
 	currentStreak, err := s.completionRepo.CalculateCurrentStreak(goalID)
 	if err != nil {
@@ -222,13 +1018,11 @@ func (s *GoalService) GetStreak(goalID, userID string) (*models.StreakResponse,
 		return nil, err
 	}
 
-	// Get completion data for graph (last 365 days)
-	graphData, err := s.completionRepo.GetCompletionGraphData(goalID, 365)
+	totalCompletions, err := s.completionRepo.CountByGoalID(goalID)
 	if err != nil {
 		return nil, err
 	}
 
-	totalCompletions := len(graphData)
 	return &models.StreakResponse{
 		CurrentStreak: currentStreak,
 		LongestStreak: longestStreak,
@@ -236,9 +1030,34 @@ func (s *GoalService) GetStreak(goalID, userID string) (*models.StreakResponse,
 	}, nil
 }
 
-func (s *GoalService) GetPublicGoals(limit int) ([]*models.Goal, error) {
+// quitStats reports a quit goal's clean runs as its streaks and its slips
+// as its completions.
+func (s *GoalService) quitStats(goal *models.Goal) (*models.StreakResponse, error) {
+	stats, err := s.completionRepo.GetSlipSummary(goal.ID)
+	if err != nil {
+		return nil, err
+	}
+	if stats.DaysSinceSlip, err = s.completionRepo.CalculateCleanRun(goal.ID); err != nil {
+		return nil, err
+	}
+	if stats.LongestCleanRun, err = s.completionRepo.CalculateLongestCleanRun(goal.ID); err != nil {
+		return nil, err
+	}
+
+	tracked := truncateDay(time.Now()).Sub(truncateDay(goal.CreatedAt)).Hours()/24 + 1
+	stats.SlipsPerWeek = float64(stats.Slips) / tracked * 7
+
+	return &models.StreakResponse{
+		CurrentStreak: stats.DaysSinceSlip,
+		LongestStreak: stats.LongestCleanRun,
+		Completions:   stats.SlipDays,
+		Quit:          stats,
+	}, nil
+}
+
+func (s *GoalService) GetPublicGoals(limit int, viewerID string) ([]*models.Goal, error) {
 	if limit <= 0 || limit > 100 {
 		limit = 50 // Default limit
 	}
-	return s.goalRepo.GetPublicGoals(limit)
+	return s.goalRepo.GetPublicGoals(limit, viewerID)
 }
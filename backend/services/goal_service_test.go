@@ -0,0 +1,130 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"DoToday/models"
+	"DoToday/repositories"
+)
+
+func newTestGoalService(db *sql.DB, filter *ContentFilter) *GoalService {
+	goalRepo := repositories.NewGoalRepository(db)
+	return NewGoalService(
+		goalRepo,
+		repositories.NewCompletionRepository(db),
+		repositories.NewGoalShareRepository(db),
+		repositories.NewOrganizationRepository(db),
+		NewCategoryService(repositories.NewCategoryRepository(db)),
+		filter,
+	)
+}
+
+func wordFilter(action string) *ContentFilter {
+	return NewContentFilter(nil, NewWordListRule([]string{"spam"}, action))
+}
+
+func TestUpdateGoalRejectsFilteredTitle(t *testing.T) {
+	db, fake := openFakeDB(t)
+	fake.on(`FROM goals g WHERE g.id = $1`, goalRow(testGoalID, testUserID, time.Now().AddDate(0, 0, -10)))
+	s := newTestGoalService(db, wordFilter(FilterReject))
+
+	title := "Read spam daily"
+	_, err := s.UpdateGoal(testGoalID, testUserID, &models.UpdateGoalRequest{Title: &title})
+	if !errors.Is(err, &models.Error{Code: models.ErrorRejected}) {
+		t.Fatalf("got %v, want a rejection", err)
+	}
+	if len(fake.ran(`UPDATE goals`)) != 0 {
+		t.Error("a rejected title was still written")
+	}
+}
+
+func TestUpdateGoalMasksFilteredTitle(t *testing.T) {
+	db, fake := openFakeDB(t)
+	fake.on(`FROM goals g WHERE g.id = $1`, goalRow(testGoalID, testUserID, time.Now().AddDate(0, 0, -10)))
+	s := newTestGoalService(db, wordFilter(FilterMask))
+
+	title := "Read spam daily"
+	goal, err := s.UpdateGoal(testGoalID, testUserID, &models.UpdateGoalRequest{Title: &title})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if goal.Title != "Read **** daily" {
+		t.Errorf("title is %q, want the masked title", goal.Title)
+	}
+	updates := fake.ran(`UPDATE goals SET title = $1`)
+	if len(updates) != 1 || updates[0].args[0] != "Read **** daily" {
+		t.Errorf("got updates %v, want the masked title stored", updates)
+	}
+}
+
+func TestUpdateGoalRequiresMilestonesToKeepAFutureDeadline(t *testing.T) {
+	db, fake := openFakeDB(t)
+	goal := goalRow(testGoalID, testUserID, time.Now().AddDate(0, 0, -10))
+	goal[8], goal[9], goal[10], goal[12], goal[13] = "milestone", "km", 100.0, "sum", time.Now().AddDate(0, 1, 0)
+	fake.on(`FROM goals g WHERE g.id = $1`, goal)
+	s := newTestGoalService(db, nil)
+
+	for name, deadline := range map[string]time.Time{
+		"zero": {},
+		"past": time.Now().AddDate(0, 0, -1),
+	} {
+		_, err := s.UpdateGoal(testGoalID, testUserID, &models.UpdateGoalRequest{Deadline: &deadline})
+		if !errors.Is(err, &models.Error{Code: models.ErrorValidation}) {
+			t.Errorf("%s deadline: got %v, want a validation error", name, err)
+		}
+	}
+	if len(fake.ran(`UPDATE goals`)) != 0 {
+		t.Error("an invalid deadline was still written")
+	}
+}
+
+func TestCreateImportedGoalRejectsUnknownFrequency(t *testing.T) {
+	db, fake := openFakeDB(t)
+	s := newTestGoalService(db, nil)
+
+	_, err := s.CreateImportedGoal(testUserID, &models.CreateGoalRequest{Title: "Read", Category: "Learning", Frequency: "yearly"})
+	if !errors.Is(err, &models.Error{Code: models.ErrorValidation}) {
+		t.Fatalf("got %v, want a validation error", err)
+	}
+	if len(fake.ran(`INSERT INTO goals`)) != 0 {
+		t.Error("a goal with an unknown frequency was still stored")
+	}
+}
+
+// changeRecorder is a goal listener that records which goals changed.
+type changeRecorder struct {
+	changed []string
+}
+
+func (r *changeRecorder) GoalCreated(goal *models.Goal) error { return nil }
+func (r *changeRecorder) GoalCompleted(goal *models.Goal, completion *models.Completion, streak int) error {
+	return nil
+}
+func (r *changeRecorder) CompletionRemoved(goal *models.Goal, completion *models.Completion) error {
+	return nil
+}
+func (r *changeRecorder) GoalChanged(goal *models.Goal) error {
+	r.changed = append(r.changed, goal.ID)
+	return nil
+}
+func (r *changeRecorder) GoalTrashed(goal *models.Goal) error  { return nil }
+func (r *changeRecorder) GoalRestored(goal *models.Goal) error { return nil }
+func (r *changeRecorder) GoalDeleted(goal *models.Goal) error  { return nil }
+
+func TestAutoArchiveExpiredNotifiesListeners(t *testing.T) {
+	db, fake := openFakeDB(t)
+	fake.on(`UPDATE goals g SET archived = true`, goalRow(testGoalID, testUserID, time.Now().AddDate(0, 0, -10)))
+	s := newTestGoalService(db, nil)
+	recorder := &changeRecorder{}
+	s.AddListener(recorder)
+
+	if err := s.AutoArchiveExpired(); err != nil {
+		t.Fatal(err)
+	}
+	if len(recorder.changed) != 1 || recorder.changed[0] != testGoalID {
+		t.Errorf("got changes for %v, want the archived goal", recorder.changed)
+	}
+}
@@ -0,0 +1,206 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"DoToday/models"
+	"DoToday/repositories"
+
+	"github.com/google/uuid"
+)
+
+// maxGoalPartners caps how many accountability partners a goal can have.
+const maxGoalPartners = 2
+
+type GoalShareService struct {
+	shareRepo        *repositories.GoalShareRepository
+	goalRepo         *repositories.GoalRepository
+	userRepo         *repositories.UserRepository
+	notificationRepo *repositories.NotificationRepository
+	filter           *ContentFilter
+}
+
+func NewGoalShareService(
+	shareRepo *repositories.GoalShareRepository,
+	goalRepo *repositories.GoalRepository,
+	userRepo *repositories.UserRepository,
+	notificationRepo *repositories.NotificationRepository,
+	filter *ContentFilter,
+) *GoalShareService {
+	return &GoalShareService{
+		shareRepo:        shareRepo,
+		goalRepo:         goalRepo,
+		userRepo:         userRepo,
+		notificationRepo: notificationRepo,
+		filter:           filter,
+	}
+}
+
+func (s *GoalShareService) ShareGoal(goalID, userID string, req *models.ShareGoalRequest) (*models.GoalShare, error) {
+	goal, err := s.goalRepo.GetByID(goalID)
+	if err != nil {
+		return nil, notFound(err, "Goal not found")
+	}
+	if goal.UserID != userID {
+		return nil, errForbidden
+	}
+
+	target, err := s.userRepo.GetByUsername(req.Username)
+	if err == sql.ErrNoRows {
+		return nil, models.NotFound("user not found")
+	} else if err != nil {
+		return nil, err
+	}
+	if target.ID == userID {
+		return nil, models.Invalid("cannot share with yourself")
+	}
+
+	if req.Role == models.ShareRolePartner {
+		currentRole, err := s.shareRepo.GetRole(goalID, target.ID)
+		if err != nil && err != sql.ErrNoRows {
+			return nil, err
+		}
+		if currentRole != models.ShareRolePartner {
+			partners, err := s.shareRepo.CountByRole(goalID, models.ShareRolePartner)
+			if err != nil {
+				return nil, err
+			}
+			if partners >= maxGoalPartners {
+				return nil, models.Conflict("partner limit reached")
+			}
+		}
+	}
+
+	share := &models.GoalShare{
+		GoalID:    goalID,
+		UserID:    target.ID,
+		Username:  target.Username,
+		Role:      req.Role,
+		CreatedAt: time.Now(),
+	}
+	if err := s.shareRepo.Upsert(share); err != nil {
+		return nil, err
+	}
+	return share, nil
+}
+
+// RemoveShare revokes a grant. The owner can remove anyone; a viewer or
+// partner can remove themselves.
+func (s *GoalShareService) RemoveShare(goalID, userID, targetID string) error {
+	goal, err := s.goalRepo.GetByID(goalID)
+	if err != nil {
+		return notFound(err, "Goal not found")
+	}
+	if goal.UserID != userID && targetID != userID {
+		return errForbidden
+	}
+	return s.shareRepo.Delete(goalID, targetID)
+}
+
+func (s *GoalShareService) GetShares(goalID, userID string) ([]*models.GoalShare, error) {
+	goal, err := s.goalRepo.GetByID(goalID)
+	if err != nil {
+		return nil, notFound(err, "Goal not found")
+	}
+	if goal.UserID != userID {
+		return nil, errForbidden
+	}
+	return s.shareRepo.GetByGoalID(goalID)
+}
+
+func (s *GoalShareService) GetSharedGoals(userID string) ([]*models.Goal, error) {
+	return s.shareRepo.GetSharedWithUser(userID)
+}
+
+// Encourage lets a partner leave a message on a shared goal and notifies the owner.
+func (s *GoalShareService) Encourage(goalID, userID string, req *models.EncouragementRequest) (*models.Encouragement, error) {
+	goal, err := s.goalRepo.GetByID(goalID)
+	if err != nil {
+		return nil, notFound(err, "Goal not found")
+	}
+
+	role, err := s.shareRepo.GetRole(goalID, userID)
+	if err == sql.ErrNoRows || (err == nil && role != models.ShareRolePartner) {
+		return nil, errForbidden
+	} else if err != nil {
+		return nil, err
+	}
+
+	encouragement := &models.Encouragement{
+		ID:        uuid.NewString(),
+		GoalID:    goalID,
+		UserID:    userID,
+		CreatedAt: time.Now(),
+	}
+	message, err := s.filter.Apply(&ContentInput{
+		UserID:   userID,
+		Kind:     models.ContentKindEncourage,
+		TargetID: encouragement.ID,
+		Text:     req.Message,
+	})
+	if err != nil {
+		return nil, err
+	}
+	encouragement.Message = message
+
+	if err := s.shareRepo.CreateEncouragement(encouragement); err != nil {
+		return nil, err
+	}
+
+	err = s.notificationRepo.Create(&models.Notification{
+		ID:        uuid.NewString(),
+		UserID:    goal.UserID,
+		Kind:      models.NotificationEncouragement,
+		GoalID:    &goal.ID,
+		Message:   fmt.Sprintf("New encouragement on %q: %s", goal.Title, message),
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return encouragement, nil
+}
+
+func (s *GoalShareService) GetEncouragements(goalID, userID string) ([]*models.Encouragement, error) {
+	goal, err := s.goalRepo.GetByID(goalID)
+	if err != nil {
+		return nil, notFound(err, "Goal not found")
+	}
+	if goal.UserID != userID {
+		if _, err := s.shareRepo.GetRole(goalID, userID); err == sql.ErrNoRows {
+			return nil, errForbidden
+		} else if err != nil {
+			return nil, err
+		}
+	}
+	return s.shareRepo.GetEncouragements(goalID)
+}
+
+// NotifyMissedGoals tells partners about shared daily goals that had no
+// completion on the given day. It is safe to run repeatedly for the same day.
+func (s *GoalShareService) NotifyMissedGoals(day time.Time) error {
+	day = day.UTC().Truncate(24 * time.Hour)
+	missed, err := s.shareRepo.GetMissedPartnerGoals(day)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range missed {
+		goalID := m.GoalID
+		err := s.notificationRepo.Create(&models.Notification{
+			ID:        uuid.NewString(),
+			UserID:    m.PartnerID,
+			Kind:      models.NotificationGoalMissed,
+			GoalID:    &goalID,
+			Message:   fmt.Sprintf("%s missed %q on %s", m.OwnerUsername, m.GoalTitle, day.Format("Jan 2")),
+			RefDate:   &day,
+			CreatedAt: time.Now(),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
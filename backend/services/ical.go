@@ -0,0 +1,55 @@
+package services
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	icalDate     = "20060102"
+	icalDateTime = "20060102T150405Z"
+)
+
+// icalWriter builds an iCalendar (RFC 5545) document, folding long lines and
+// ending each with CRLF.
+type icalWriter struct {
+	b strings.Builder
+}
+
+// line writes a content line, folding it at 75 octets.
+func (w *icalWriter) line(name, value string) {
+	line := name + ":" + value
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		w.b.WriteString(line[:cut])
+		w.b.WriteString("\r\n ")
+		line = line[cut:]
+		limit = 74 // the leading space counts
+	}
+	w.b.WriteString(line)
+	w.b.WriteString("\r\n")
+}
+
+func (w *icalWriter) date(name string, t time.Time) {
+	w.line(name+";VALUE=DATE", t.Format(icalDate))
+}
+
+func (w *icalWriter) timestamp(name string, t time.Time) {
+	w.line(name, t.UTC().Format(icalDateTime))
+}
+
+func (w *icalWriter) String() string {
+	return w.b.String()
+}
+
+var icalEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`)
+
+// icalText escapes a TEXT value.
+func icalText(s string) string {
+	return icalEscaper.Replace(s)
+}
@@ -0,0 +1,368 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"DoToday/models"
+)
+
+const importDateLayout = "2006-01-02"
+
+// importedGoal is a goal read from an import file together with its
+// completions, keyed by YYYY-MM-DD date.
+type importedGoal struct {
+	Title       string
+	Category    string
+	Description string
+	Frequency   string
+	TargetCount int
+	IsPublic    bool
+	Completions map[string]int
+
+	// The kind, measurement settings and measured values are only known for
+	// DoToday archives; goals from other formats are habits.
+	Kind        string
+	Unit        string
+	TargetValue *float64
+	Comparison  string
+	Aggregation string
+	Deadline    *time.Time
+	Values      map[string]float64
+}
+
+// importBatch collects goals in the order they first appear in the file.
+type importBatch struct {
+	goals    []*importedGoal
+	byTitle  map[string]*importedGoal
+	warnings []string
+}
+
+func newImportBatch() *importBatch {
+	return &importBatch{byTitle: make(map[string]*importedGoal)}
+}
+
+// goal returns the goal with the given title, adding it on first use. Titles
+// that differ only in case or surrounding space are the same goal.
+func (b *importBatch) goal(title string) *importedGoal {
+	key := normalizeTitle(title)
+	if g, ok := b.byTitle[key]; ok {
+		return g
+	}
+	g := &importedGoal{
+		Title:       strings.TrimSpace(title),
+		Completions: make(map[string]int),
+		Values:      make(map[string]float64),
+	}
+	b.byTitle[key] = g
+	b.goals = append(b.goals, g)
+	return g
+}
+
+func (b *importBatch) warnf(format string, args ...interface{}) {
+	b.warnings = append(b.warnings, fmt.Sprintf(format, args...))
+}
+
+func normalizeTitle(title string) string {
+	return strings.ToLower(strings.TrimSpace(title))
+}
+
+// parseImport reads data in the requested format.
+func parseImport(data []byte, req *models.ImportRequest) (*importBatch, error) {
+	switch req.Format {
+	case models.ImportFormatGenericCSV:
+		return parseGenericCSV(bytes.NewReader(data), req)
+	case models.ImportFormatLoop:
+		return parseLoop(data)
+	case models.ImportFormatDoToday:
+		return parseDoToday(data)
+	default:
+		return nil, models.InvalidFields("unsupported import format", models.FieldError{Field: "format", Message: "unsupported import format"})
+	}
+}
+
+// csvHeaderIndex maps lower-cased, trimmed header names to column positions.
+func csvHeaderIndex(header []string) map[string]int {
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		index[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	return index
+}
+
+// parseGenericCSV reads one completion per row. Column names default to
+// habit, date, count and category; only the title and date columns are
+// required.
+func parseGenericCSV(r io.Reader, req *models.ImportRequest) (*importBatch, error) {
+	titleCol := strings.ToLower(firstNonEmpty(req.TitleColumn, "habit"))
+	dateCol := strings.ToLower(firstNonEmpty(req.DateColumn, "date"))
+	countCol := strings.ToLower(firstNonEmpty(req.CountColumn, "count"))
+	categoryCol := strings.ToLower(firstNonEmpty(req.CategoryColumn, "category"))
+	layout := firstNonEmpty(req.DateFormat, importDateLayout)
+
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err != nil {
+		return nil, models.Rejected("invalid import file: missing header row")
+	}
+	index := csvHeaderIndex(header)
+	titleIdx, ok := index[titleCol]
+	if !ok {
+		return nil, models.Rejected("invalid import file: no %q column", titleCol)
+	}
+	dateIdx, ok := index[dateCol]
+	if !ok {
+		return nil, models.Rejected("invalid import file: no %q column", dateCol)
+	}
+	countIdx, hasCount := index[countCol]
+	categoryIdx, hasCategory := index[categoryCol]
+
+	batch := newImportBatch()
+	for line := 2; ; line++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, models.Rejected("invalid import file: %v", err)
+		}
+
+		title := field(record, titleIdx)
+		if title == "" {
+			batch.warnf("row %d: missing title", line)
+			continue
+		}
+		date, err := time.Parse(layout, field(record, dateIdx))
+		if err != nil {
+			batch.warnf("row %d: invalid date %q", line, field(record, dateIdx))
+			continue
+		}
+		count := 1
+		if hasCount && field(record, countIdx) != "" {
+			count, err = strconv.Atoi(field(record, countIdx))
+			if err != nil || count < 0 {
+				batch.warnf("row %d: invalid count %q", line, field(record, countIdx))
+				continue
+			}
+			if count == 0 {
+				continue
+			}
+		}
+
+		goal := batch.goal(title)
+		if hasCategory && goal.Category == "" {
+			goal.Category = field(record, categoryIdx)
+		}
+		goal.Completions[date.Format(importDateLayout)] += count
+	}
+	return batch, nil
+}
+
+// Loop Habit Tracker checkmark values. Only manual check-ins count as
+// completions; automatic ones are implied by the habit's frequency.
+const loopCheckmarkYesManual = 2
+
+// parseLoop reads a Loop Habit Tracker export: either the full zip or its
+// root Checkmarks.csv, which has a Date column followed by one column per
+// habit. Habits.csv, when present, supplies descriptions.
+func parseLoop(data []byte) (*importBatch, error) {
+	checkmarks := data
+	var habits []byte
+
+	if zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data))); err == nil {
+		checkmarks, err = readZipFile(zr, "Checkmarks.csv")
+		if err != nil {
+			return nil, err
+		}
+		habits, _ = readZipFile(zr, "Habits.csv")
+	}
+
+	cr := csv.NewReader(bytes.NewReader(checkmarks))
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err != nil || len(header) < 2 || !strings.EqualFold(strings.TrimSpace(header[0]), "date") {
+		return nil, models.Rejected("invalid import file: expected Loop Checkmarks.csv")
+	}
+
+	batch := newImportBatch()
+	columns := make([]*importedGoal, len(header))
+	for i, name := range header[1:] {
+		if strings.TrimSpace(name) != "" {
+			columns[i+1] = batch.goal(name)
+		}
+	}
+
+	for line := 2; ; line++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, models.Rejected("invalid import file: %v", err)
+		}
+		date, err := time.Parse(importDateLayout, field(record, 0))
+		if err != nil {
+			batch.warnf("row %d: invalid date %q", line, field(record, 0))
+			continue
+		}
+		for i := 1; i < len(record) && i < len(columns); i++ {
+			if columns[i] == nil {
+				continue
+			}
+			if value, err := strconv.Atoi(field(record, i)); err == nil && value == loopCheckmarkYesManual {
+				columns[i].Completions[date.Format(importDateLayout)] = 1
+			}
+		}
+	}
+
+	if habits != nil {
+		applyLoopHabits(batch, habits)
+	}
+	return batch, nil
+}
+
+func applyLoopHabits(batch *importBatch, data []byte) {
+	cr := csv.NewReader(bytes.NewReader(data))
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err != nil {
+		return
+	}
+	index := csvHeaderIndex(header)
+	nameIdx, ok := index["name"]
+	if !ok {
+		return
+	}
+	descIdx, hasDesc := index["description"]
+	questionIdx, hasQuestion := index["question"]
+
+	for {
+		record, err := cr.Read()
+		if err != nil {
+			return
+		}
+		goal, ok := batch.byTitle[normalizeTitle(field(record, nameIdx))]
+		if !ok {
+			continue
+		}
+		if hasDesc {
+			goal.Description = field(record, descIdx)
+		}
+		if goal.Description == "" && hasQuestion {
+			goal.Description = field(record, questionIdx)
+		}
+	}
+}
+
+// parseDoToday reads an archive produced by the account export. It reads the
+// JSON files, so every schema version up to ExportSchemaVersion is accepted;
+// completions from version 1 simply carry no value.
+func parseDoToday(data []byte) (*importBatch, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, models.Rejected("invalid import file: expected a DoToday export archive")
+	}
+
+	var manifest models.ExportManifest
+	if err := readZipJSON(zr, "manifest.json", &manifest); err != nil {
+		return nil, err
+	}
+	if manifest.SchemaVersion < 1 || manifest.SchemaVersion > ExportSchemaVersion {
+		return nil, models.Rejected("invalid import file: unsupported schema version %d", manifest.SchemaVersion)
+	}
+
+	var goals []*models.Goal
+	if err := readZipJSON(zr, "goals.json", &goals); err != nil {
+		return nil, err
+	}
+	var completions []*models.Completion
+	if err := readZipJSON(zr, "completions.json", &completions); err != nil {
+		return nil, err
+	}
+
+	batch := newImportBatch()
+	byID := make(map[string]*importedGoal, len(goals))
+	for _, g := range goals {
+		goal := batch.goal(g.Title)
+		goal.Category = g.Category
+		goal.Description = g.Description
+		goal.Frequency = g.Frequency
+		goal.TargetCount = g.TargetCount
+		goal.IsPublic = g.IsPublic
+		goal.Kind = g.Kind
+		goal.Unit = g.Unit
+		goal.TargetValue = g.TargetValue
+		goal.Comparison = g.Comparison
+		goal.Aggregation = g.Aggregation
+		goal.Deadline = g.Deadline
+		byID[g.ID] = goal
+	}
+	for _, c := range completions {
+		goal, ok := byID[c.GoalID]
+		if !ok {
+			batch.warnf("completion %s: unknown goal %s", c.ID, c.GoalID)
+			continue
+		}
+		count := c.Count
+		if count < 1 {
+			count = 1
+		}
+		day := c.Date.UTC().Format(importDateLayout)
+		goal.Completions[day] += count
+		if c.Value != nil {
+			goal.Values[day] = *c.Value
+		}
+	}
+	return batch, nil
+}
+
+// readZipFile returns the named file from the archive root.
+func readZipFile(zr *zip.Reader, name string) ([]byte, error) {
+	for _, f := range zr.File {
+		if f.Name != name {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		return io.ReadAll(rc)
+	}
+	return nil, models.Rejected("invalid import file: missing %s", name)
+}
+
+func readZipJSON(zr *zip.Reader, name string, v interface{}) error {
+	data, err := readZipFile(zr, name)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return models.Rejected("invalid import file: %s: %v", name, err)
+	}
+	return nil
+}
+
+func field(record []string, i int) string {
+	if i < 0 || i >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[i])
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
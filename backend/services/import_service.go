@@ -0,0 +1,194 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"DoToday/models"
+	"DoToday/repositories"
+
+	"github.com/google/uuid"
+)
+
+type ImportService struct {
+	goalRepo        *repositories.GoalRepository
+	completionRepo  *repositories.CompletionRepository
+	goalService     *GoalService
+	categoryService *CategoryService
+}
+
+func NewImportService(
+	goalRepo *repositories.GoalRepository,
+	completionRepo *repositories.CompletionRepository,
+	goalService *GoalService,
+	categoryService *CategoryService,
+) *ImportService {
+	return &ImportService{
+		goalRepo:        goalRepo,
+		completionRepo:  completionRepo,
+		goalService:     goalService,
+		categoryService: categoryService,
+	}
+}
+
+// Import reads an export from another tracker (or from DoToday itself) and
+// adds its goals and completions to the user's account. Goals are matched to
+// the user's active goals by title and merged; completions on dates the goal
+// already has are skipped, and goals whose kind differs from the match are
+// skipped entirely so slips never become completions or the other way round.
+// Imported history does not award XP or achievements. With dryRun set
+// nothing is written.
+func (s *ImportService) Import(userID string, data []byte, req *models.ImportRequest) (*models.ImportReport, error) {
+	batch, err := parseImport(data, req)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.goalRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	byTitle := make(map[string]*models.Goal, len(existing))
+	for _, goal := range existing {
+		byTitle[normalizeTitle(goal.Title)] = goal
+	}
+
+	report := &models.ImportReport{
+		Format:   req.Format,
+		DryRun:   req.DryRun,
+		Goals:    []*models.ImportGoalReport{},
+		Warnings: batch.warnings,
+	}
+	if report.Warnings == nil {
+		report.Warnings = []string{}
+	}
+
+	for _, imported := range batch.goals {
+		goalReport, err := s.importGoal(userID, imported, byTitle[normalizeTitle(imported.Title)], req.DryRun)
+		if err != nil {
+			return nil, err
+		}
+		report.Goals = append(report.Goals, goalReport)
+		switch goalReport.Action {
+		case models.ImportActionCreate:
+			report.GoalsCreated++
+		case models.ImportActionMerge:
+			report.GoalsMerged++
+		}
+		report.CompletionsImported += goalReport.Completions
+		report.CompletionsSkipped += goalReport.Duplicates
+	}
+	return report, nil
+}
+
+func (s *ImportService) importGoal(userID string, imported *importedGoal, match *models.Goal, dryRun bool) (*models.ImportGoalReport, error) {
+	goalReport := &models.ImportGoalReport{Title: imported.Title, Action: models.ImportActionMerge}
+
+	kind := firstNonEmpty(imported.Kind, models.GoalKindHabit)
+	if match != nil && firstNonEmpty(match.Kind, models.GoalKindHabit) != kind {
+		goalReport.Action = models.ImportActionSkip
+		goalReport.GoalID = match.ID
+		goalReport.Reason = fmt.Sprintf("existing goal is a %s goal, imported goal is a %s goal",
+			firstNonEmpty(match.Kind, models.GoalKindHabit), kind)
+		return goalReport, nil
+	}
+
+	goal := match
+	if goal == nil {
+		goalReport.Action = models.ImportActionCreate
+		if dryRun {
+			goalReport.Completions = len(imported.Completions)
+			return goalReport, nil
+		}
+
+		// Categories the user does not have yet are created for them.
+		category, err := s.categoryService.Ensure(userID, firstNonEmpty(imported.Category, models.DefaultCategoryName))
+		if err != nil {
+			return nil, err
+		}
+
+		created, err := s.goalService.CreateImportedGoal(userID, &models.CreateGoalRequest{
+			Title:       imported.Title,
+			CategoryID:  category.ID,
+			Description: imported.Description,
+			Frequency:   firstNonEmpty(imported.Frequency, "daily"),
+			TargetCount: imported.TargetCount,
+			Deadline:    imported.Deadline,
+			IsPublic:    imported.IsPublic,
+			Kind:        kind,
+			Unit:        imported.Unit,
+			TargetValue: imported.TargetValue,
+			Comparison:  imported.Comparison,
+			Aggregation: imported.Aggregation,
+		})
+		if err != nil {
+			// Goals the filter rejects or that no longer validate, such as
+			// milestones past their deadline, are left out.
+			if errors.Is(err, &models.Error{Code: models.ErrorRejected}) ||
+				errors.Is(err, &models.Error{Code: models.ErrorValidation}) {
+				goalReport.Action = models.ImportActionSkip
+				goalReport.Reason = err.Error()
+				return goalReport, nil
+			}
+			return nil, err
+		}
+		goal = created
+	}
+	goalReport.GoalID = goal.ID
+
+	now := time.Now()
+	for day, count := range imported.Completions {
+		date, err := time.Parse(importDateLayout, day)
+		if err != nil {
+			return nil, err
+		}
+
+		if dryRun {
+			exists, err := s.completionRepo.GetCompletionExists(goal.ID, date)
+			if err != nil {
+				return nil, err
+			}
+			if exists {
+				goalReport.Duplicates++
+			} else {
+				goalReport.Completions++
+			}
+			continue
+		}
+
+		completion := &models.Completion{
+			ID:        uuid.NewString(),
+			GoalID:    goal.ID,
+			Date:      date,
+			Count:     count,
+			CreatedAt: now,
+		}
+		if value, ok := imported.Values[day]; ok {
+			completion.Value = &value
+		}
+		inserted, err := s.completionRepo.CreateIfAbsent(completion)
+		if err != nil {
+			return nil, err
+		}
+		if inserted {
+			goalReport.Completions++
+		} else {
+			goalReport.Duplicates++
+		}
+	}
+
+	if !dryRun && goalReport.Completions > 0 {
+		streak, err := s.completionRepo.CalculateCurrentStreak(goal.ID)
+		if err != nil {
+			log.Printf("recalculating streak after import failed for goal %s: %v", goal.ID, err)
+		} else if err := s.goalRepo.UpdateStreak(goal.ID, streak); err != nil {
+			log.Printf("updating streak after import failed for goal %s: %v", goal.ID, err)
+		}
+	}
+	if !dryRun && (goalReport.Action == models.ImportActionCreate || goalReport.Completions > 0) {
+		s.goalService.notifyChanged(goal)
+	}
+	return goalReport, nil
+}
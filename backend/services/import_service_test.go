@@ -0,0 +1,66 @@
+package services
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"DoToday/models"
+	"DoToday/repositories"
+)
+
+func newTestImportService(db *sql.DB) *ImportService {
+	goals := newTestGoalService(db, nil)
+	goals.AddListener(newTestXPService(db))
+	return NewImportService(
+		repositories.NewGoalRepository(db),
+		repositories.NewCompletionRepository(db),
+		goals,
+		NewCategoryService(repositories.NewCategoryRepository(db)),
+	)
+}
+
+const testImportCSV = "habit,date\nread,2026-01-02\nRead,2026-01-03\n"
+
+func TestImportMergesIntoMatchingGoalWithoutXP(t *testing.T) {
+	db, fake := openFakeDB(t)
+	fake.on(`FROM goals g WHERE g.user_id = $1 AND g.archived = false`, goalRow(testGoalID, testUserID, time.Now().AddDate(0, -1, 0))).
+		affect(`INSERT INTO completions`, 1)
+	s := newTestImportService(db)
+
+	report, err := s.Import(testUserID, []byte(testImportCSV), &models.ImportRequest{Format: models.ImportFormatGenericCSV})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.GoalsMerged != 1 || report.GoalsCreated != 0 || report.CompletionsImported != 2 {
+		t.Errorf("got %d merged, %d created, %d completions; want 1, 0, 2",
+			report.GoalsMerged, report.GoalsCreated, report.CompletionsImported)
+	}
+	for _, insert := range fake.ran(`INSERT INTO completions`) {
+		if insert.args[1] != testGoalID {
+			t.Errorf("completion imported into %v, want %s", insert.args[1], testGoalID)
+		}
+	}
+	if len(fake.ran(`INSERT INTO goals`)) != 0 || len(fake.ran(`xp_ledger`)) != 0 {
+		t.Error("merging created a goal or awarded XP")
+	}
+}
+
+func TestImportSkipsGoalsOfAnotherKind(t *testing.T) {
+	db, fake := openFakeDB(t)
+	quit := goalRow(testGoalID, testUserID, time.Now().AddDate(0, -1, 0))
+	quit[8] = models.GoalKindQuit
+	fake.on(`FROM goals g WHERE g.user_id = $1 AND g.archived = false`, quit)
+	s := newTestImportService(db)
+
+	report, err := s.Import(testUserID, []byte(testImportCSV), &models.ImportRequest{Format: models.ImportFormatGenericCSV})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Goals) != 1 || report.Goals[0].Action != models.ImportActionSkip {
+		t.Fatalf("got %+v, want the goal skipped", report.Goals)
+	}
+	if len(fake.ran(`INSERT INTO completions`)) != 0 {
+		t.Error("completions were imported into a goal of another kind")
+	}
+}
@@ -0,0 +1,174 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"DoToday/models"
+	"DoToday/repositories"
+)
+
+// Insights look back insightDays days by default and at most a year. A
+// relationship is only reported with at least insightMinSamples days of data
+// and a correlation of at least insightMinCorrelation either way.
+const (
+	insightDays           = 90
+	insightMaxDays        = 365
+	insightLimit          = 10
+	insightMinSamples     = 14
+	insightMinCorrelation = 0.2
+)
+
+type InsightService struct {
+	goalRepo       *repositories.GoalRepository
+	completionRepo *repositories.CompletionRepository
+}
+
+func NewInsightService(goalRepo *repositories.GoalRepository, completionRepo *repositories.CompletionRepository) *InsightService {
+	return &InsightService{
+		goalRepo:       goalRepo,
+		completionRepo: completionRepo,
+	}
+}
+
+// GetInsights returns the strongest relationships between the user's active
+// goals over the last days days, up to yesterday.
+func (s *InsightService) GetInsights(userID string, days, limit int) (*models.InsightReport, error) {
+	if days <= 0 || days > insightMaxDays {
+		days = insightDays
+	}
+	if limit <= 0 || limit > 50 {
+		limit = insightLimit
+	}
+
+	to := truncateDay(time.Now()).AddDate(0, 0, -1)
+	from := to.AddDate(0, 0, -(days - 1))
+	insights, err := s.findInsights(userID, from, to, limit)
+	if err != nil {
+		return nil, err
+	}
+	return &models.InsightReport{Days: days, From: from, To: to, Insights: insights}, nil
+}
+
+func (s *InsightService) findInsights(userID string, from, to time.Time, limit int) ([]*models.Insight, error) {
+	goals, err := s.goalRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	completions, err := s.completionRepo.GetByUserInRange(userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return correlateGoals(goals, completions, from, to, limit), nil
+}
+
+// goalSeries marks the days from..to on which a goal was met, starting at
+// the day it was created.
+type goalSeries struct {
+	goal  *models.Goal
+	first int
+	met   []bool
+}
+
+// correlateGoals compares every pair of goals on the same day and, both
+// ways, a day apart, returning the strongest relationships first. A day
+// counts as met when the goal has a completion, or for quit goals when it
+// has no slip.
+func correlateGoals(goals []*models.Goal, completions []*models.Completion, from, to time.Time, limit int) []*models.Insight {
+	days := daysBetween(from, to) + 1
+	done := make(map[string]map[int]bool)
+	for _, c := range completions {
+		if done[c.GoalID] == nil {
+			done[c.GoalID] = make(map[int]bool)
+		}
+		done[c.GoalID][daysBetween(from, c.Date)] = true
+	}
+
+	var series []*goalSeries
+	for _, goal := range goals {
+		first := max(daysBetween(from, goal.CreatedAt), 0)
+		if first >= days {
+			continue
+		}
+		gs := &goalSeries{goal: goal, first: first, met: make([]bool, days)}
+		for d := first; d < days; d++ {
+			gs.met[d] = done[goal.ID][d] != (goal.Kind == models.GoalKindQuit)
+		}
+		series = append(series, gs)
+	}
+
+	insights := []*models.Insight{}
+	add := func(insight *models.Insight) {
+		if insight != nil {
+			insights = append(insights, insight)
+		}
+	}
+	for i, a := range series {
+		for _, b := range series[i+1:] {
+			add(relate(a, b, 0))
+			add(relate(a, b, 1))
+			add(relate(b, a, 1))
+		}
+	}
+
+	sort.SliceStable(insights, func(i, j int) bool {
+		ci, cj := math.Abs(insights[i].Correlation), math.Abs(insights[j].Correlation)
+		if ci != cj {
+			return ci > cj
+		}
+		return insights[i].SampleSize > insights[j].SampleSize
+	})
+	if len(insights) > limit {
+		insights = insights[:limit]
+	}
+	return insights
+}
+
+// relate correlates meeting a on a day with meeting b lag days later, or
+// returns nil when there is too little data or no relationship.
+func relate(a, b *goalSeries, lag int) *models.Insight {
+	var n11, n10, n01, n00 int
+	for d := max(a.first, b.first-lag); d+lag < len(b.met); d++ {
+		switch x, y := a.met[d], b.met[d+lag]; {
+		case x && y:
+			n11++
+		case x:
+			n10++
+		case y:
+			n01++
+		default:
+			n00++
+		}
+	}
+
+	n := n11 + n10 + n01 + n00
+	denominator := math.Sqrt(float64((n11 + n10) * (n01 + n00) * (n11 + n01) * (n10 + n00)))
+	if n < insightMinSamples || denominator == 0 {
+		return nil
+	}
+	phi := float64(n11*n00-n10*n01) / denominator
+	if math.Abs(phi) < insightMinCorrelation {
+		return nil
+	}
+
+	insight := &models.Insight{
+		GoalAID:      a.goal.ID,
+		GoalATitle:   a.goal.Title,
+		GoalBID:      b.goal.ID,
+		GoalBTitle:   b.goal.Title,
+		Lag:          lag,
+		Correlation:  phi,
+		SampleSize:   n,
+		BothDays:     n11,
+		RateWithA:    float64(n11) / float64(n11+n10),
+		RateWithoutA: float64(n01) / float64(n01+n00),
+	}
+	when := "On days you meet %q, you meet %q %s of the time, against %s on other days."
+	if lag > 0 {
+		when = "The day after you meet %q, you meet %q %s of the time, against %s otherwise."
+	}
+	insight.Summary = fmt.Sprintf(when, a.goal.Title, b.goal.Title, percent(insight.RateWithA), percent(insight.RateWithoutA))
+	return insight
+}
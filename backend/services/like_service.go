@@ -1,28 +1,53 @@
 package services
 
 import (
+	"log"
+
 	"DoToday/models"
 	"DoToday/repositories"
 )
 
 type LikeService struct {
-	repo *repositories.LikeRepository
+	repo     *repositories.LikeRepository
+	feedRepo *repositories.FeedRepository
+	xp       *XPService
 }
 
-func NewLikeService(repo *repositories.LikeRepository) *LikeService {
-	return &LikeService{repo: repo}
+func NewLikeService(
+	repo *repositories.LikeRepository,
+	feedRepo *repositories.FeedRepository,
+	xp *XPService,
+) *LikeService {
+	return &LikeService{
+		repo:     repo,
+		feedRepo: feedRepo,
+		xp:       xp,
+	}
 }
 
+// CreateLike likes a post the user can see. Hidden posts and posts the user
+// cannot see because of a block or mute are not found.
 func (s *LikeService) CreateLike(like *models.Like) error {
-	return s.repo.Create(like)
+	if _, err := s.feedRepo.GetByID(like.FeedID, like.UserID); err != nil {
+		return notFound(err, "Feed not found")
+	}
+
+	if err := s.repo.Create(like); err != nil {
+		return err
+	}
+
+	if err := s.xp.RecordLike(like.UserID, like.FeedID); err != nil {
+		log.Printf("recording like XP failed for feed %s: %v", like.FeedID, err)
+	}
+	return nil
 }
 
 func (s *LikeService) DeleteLike(feedID, userID string) error {
 	return s.repo.Delete(feedID, userID)
 }
 
-func (s *LikeService) CountLikes(feedID string) (int, error) {
-	return s.repo.Count(feedID)
+func (s *LikeService) CountLikes(feedID, viewerID string) (int, error) {
+	return s.repo.Count(feedID, viewerID)
 }
 
 func (s *LikeService) Exists(feedID, userID string) (bool, error) {
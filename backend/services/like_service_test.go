@@ -0,0 +1,35 @@
+package services
+
+import (
+	"testing"
+
+	"DoToday/models"
+	"DoToday/repositories"
+)
+
+func TestRelikingAPostReusesItsXPRef(t *testing.T) {
+	db, fake := openFakeDB(t)
+	fake.on(`SELECT f.id, f.goal_id, f.date, f.description, f.hidden`, feedRow())
+	s := NewLikeService(repositories.NewLikeRepository(db), repositories.NewFeedRepository(db), newTestXPService(db))
+
+	for i := 0; i < 2; i++ {
+		if err := s.CreateLike(&models.Like{FeedID: testFeedID, UserID: testUserID}); err != nil {
+			t.Fatalf("liking: %v", err)
+		}
+		if err := s.DeleteLike(testFeedID, testUserID); err != nil {
+			t.Fatalf("unliking: %v", err)
+		}
+	}
+
+	// The ledger keeps one entry per ref, so the second award is dropped.
+	awards := fake.ran(`INSERT INTO xp_ledger`)
+	if len(awards) != 2 {
+		t.Fatalf("got %d like awards, want 2", len(awards))
+	}
+	want := "like:" + testFeedID + ":" + testUserID
+	for _, award := range awards {
+		if ref := award.args[4].(*string); *ref != want {
+			t.Errorf("award ref is %q, want %q", *ref, want)
+		}
+	}
+}
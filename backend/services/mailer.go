@@ -0,0 +1,79 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"time"
+
+	"DoToday/config"
+)
+
+// Mailer sends an email with a plain text and an HTML body.
+type Mailer interface {
+	Send(to, subject, text, html string) error
+}
+
+// NewMailer returns an SMTP mailer, or nil when no SMTP host is configured.
+func NewMailer(cfg *config.MailConfig) Mailer {
+	if cfg.Host == "" {
+		return nil
+	}
+	return &smtpMailer{config: cfg}
+}
+
+type smtpMailer struct {
+	config *config.MailConfig
+}
+
+func (m *smtpMailer) Send(to, subject, text, html string) error {
+	from, err := mail.ParseAddress(m.config.From)
+	if err != nil {
+		return fmt.Errorf("invalid MAIL_FROM: %w", err)
+	}
+
+	var body bytes.Buffer
+	parts := multipart.NewWriter(&body)
+	fmt.Fprintf(&body, "From: %s\r\n", from.String())
+	fmt.Fprintf(&body, "To: %s\r\n", to)
+	fmt.Fprintf(&body, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&body, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&body, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&body, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", parts.Boundary())
+
+	for _, part := range []struct{ contentType, content string }{
+		{"text/plain; charset=utf-8", text},
+		{"text/html; charset=utf-8", html},
+	} {
+		w, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return err
+		}
+		qp := quotedprintable.NewWriter(w)
+		if _, err := qp.Write([]byte(part.content)); err != nil {
+			return err
+		}
+		if err := qp.Close(); err != nil {
+			return err
+		}
+	}
+	if err := parts.Close(); err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if m.config.Username != "" {
+		auth = smtp.PlainAuth("", m.config.Username, m.config.Password, m.config.Host)
+	}
+	addr := m.config.Host + ":" + strconv.Itoa(m.config.Port)
+	return smtp.SendMail(addr, auth, from.Address, []string{to}, body.Bytes())
+}
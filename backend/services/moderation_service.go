@@ -0,0 +1,157 @@
+package services
+
+import (
+	"database/sql"
+	"time"
+
+	"DoToday/models"
+	"DoToday/repositories"
+
+	"github.com/google/uuid"
+)
+
+type ModerationService struct {
+	repo        *repositories.ModerationRepository
+	feedRepo    *repositories.FeedRepository
+	commentRepo *repositories.CommentRepository
+	flagRepo    *repositories.ContentFilterRepository
+	userRepo    *repositories.UserRepository
+}
+
+func NewModerationService(
+	repo *repositories.ModerationRepository,
+	feedRepo *repositories.FeedRepository,
+	commentRepo *repositories.CommentRepository,
+	flagRepo *repositories.ContentFilterRepository,
+	userRepo *repositories.UserRepository,
+) *ModerationService {
+	return &ModerationService{
+		repo:        repo,
+		feedRepo:    feedRepo,
+		commentRepo: commentRepo,
+		flagRepo:    flagRepo,
+		userRepo:    userRepo,
+	}
+}
+
+func (s *ModerationService) BlockUser(userID, targetID string) error {
+	if userID == targetID {
+		return models.Invalid("cannot block yourself")
+	}
+	if err := s.requireUser(targetID); err != nil {
+		return err
+	}
+	return s.repo.Block(userID, targetID)
+}
+
+func (s *ModerationService) UnblockUser(userID, targetID string) error {
+	return s.repo.Unblock(userID, targetID)
+}
+
+func (s *ModerationService) GetBlocks(userID string) ([]*models.Block, error) {
+	return s.repo.GetBlocks(userID)
+}
+
+func (s *ModerationService) MuteUser(userID, targetID string) error {
+	if userID == targetID {
+		return models.Invalid("cannot mute yourself")
+	}
+	if err := s.requireUser(targetID); err != nil {
+		return err
+	}
+	return s.repo.Mute(userID, targetID)
+}
+
+func (s *ModerationService) UnmuteUser(userID, targetID string) error {
+	return s.repo.Unmute(userID, targetID)
+}
+
+// requireUser reports a block or mute target without a profile as not found
+// rather than letting the insert fail on the foreign key.
+func (s *ModerationService) requireUser(id string) error {
+	_, err := s.userRepo.GetByID(id)
+	return notFound(err, "User not found")
+}
+
+func (s *ModerationService) GetMutes(userID string) ([]*models.Mute, error) {
+	return s.repo.GetMutes(userID)
+}
+
+func (s *ModerationService) CreateReport(userID string, req *models.CreateReportRequest) (*models.Report, error) {
+	// Make sure the reported content exists
+	var err error
+	switch req.TargetType {
+	case models.ReportTargetFeed:
+		_, err = s.feedRepo.GetOwnerID(req.TargetID)
+	case models.ReportTargetComment:
+		_, err = s.commentRepo.GetByID(req.TargetID)
+	default:
+		return nil, models.InvalidFields("invalid report target type", models.FieldError{Field: "target_type", Message: "invalid report target type"})
+	}
+	if err == sql.ErrNoRows {
+		return nil, models.NotFound("report target not found")
+	} else if err != nil {
+		return nil, err
+	}
+
+	report := &models.Report{
+		ID:         uuid.NewString(),
+		ReporterID: userID,
+		TargetType: req.TargetType,
+		TargetID:   req.TargetID,
+		Reason:     req.Reason,
+		Status:     models.ReportStatusOpen,
+		CreatedAt:  time.Now(),
+	}
+	if err := s.repo.CreateReport(report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func (s *ModerationService) GetReports(status string, limit int) ([]*models.Report, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 50 // Default limit
+	}
+	return s.repo.GetReports(status, limit)
+}
+
+// GetContentFlags returns content the filter let through but flagged for review.
+func (s *ModerationService) GetContentFlags(limit int) ([]*models.ContentFlag, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 50 // Default limit
+	}
+	return s.flagRepo.GetFlags(limit)
+}
+
+// HideReported hides the content a report points at and closes every report
+// against it.
+func (s *ModerationService) HideReported(reportID, adminID string) (*models.Report, error) {
+	return s.resolve(reportID, adminID, true, models.ReportStatusHidden)
+}
+
+// RestoreReported makes previously reported content visible again and closes
+// every report against it.
+func (s *ModerationService) RestoreReported(reportID, adminID string) (*models.Report, error) {
+	return s.resolve(reportID, adminID, false, models.ReportStatusRestored)
+}
+
+func (s *ModerationService) resolve(reportID, adminID string, hidden bool, status string) (*models.Report, error) {
+	report, err := s.repo.GetReportByID(reportID)
+	if err != nil {
+		return nil, notFound(err, "Report not found")
+	}
+
+	if err := s.repo.SetHidden(report.TargetType, report.TargetID, hidden); err != nil {
+		return nil, err
+	}
+	if err := s.repo.ResolveReports(report.TargetType, report.TargetID, status, adminID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	report.Status = status
+	report.ResolvedBy = &adminID
+	report.ResolvedAt = &now
+	return report, nil
+}
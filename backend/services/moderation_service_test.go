@@ -0,0 +1,40 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+
+	"DoToday/models"
+	"DoToday/repositories"
+)
+
+func newTestModerationService(db *sql.DB) *ModerationService {
+	return NewModerationService(
+		repositories.NewModerationRepository(db),
+		repositories.NewFeedRepository(db),
+		repositories.NewCommentRepository(db),
+		repositories.NewContentFilterRepository(db),
+		repositories.NewUserRepository(db),
+	)
+}
+
+func TestBlockAndMuteRejectMissingUsersAndSelf(t *testing.T) {
+	db, fake := openFakeDB(t)
+	s := newTestModerationService(db)
+
+	for name, apply := range map[string]func(userID, targetID string) error{
+		"block": s.BlockUser,
+		"mute":  s.MuteUser,
+	} {
+		if err := apply(testUserID, testOtherID); !errors.Is(err, &models.Error{Code: models.ErrorNotFound}) {
+			t.Errorf("%s of a missing user: got %v, want not found", name, err)
+		}
+		if err := apply(testUserID, testUserID); !errors.Is(err, &models.Error{Code: models.ErrorValidation}) {
+			t.Errorf("%s of yourself: got %v, want invalid", name, err)
+		}
+	}
+	if len(fake.ran(`INSERT INTO blocks`)) != 0 || len(fake.ran(`INSERT INTO mutes`)) != 0 {
+		t.Error("a rejected block or mute was still stored")
+	}
+}
@@ -0,0 +1,29 @@
+package services
+
+import (
+	"DoToday/models"
+	"DoToday/repositories"
+)
+
+type NotificationService struct {
+	repo *repositories.NotificationRepository
+}
+
+func NewNotificationService(repo *repositories.NotificationRepository) *NotificationService {
+	return &NotificationService{repo: repo}
+}
+
+func (s *NotificationService) GetNotifications(userID string, unreadOnly bool, limit int) ([]*models.Notification, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 50 // Default limit
+	}
+	return s.repo.GetByUserID(userID, unreadOnly, limit)
+}
+
+func (s *NotificationService) MarkRead(id, userID string) error {
+	return s.repo.MarkRead(id, userID)
+}
+
+func (s *NotificationService) MarkAllRead(userID string) error {
+	return s.repo.MarkAllRead(userID)
+}
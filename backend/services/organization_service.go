@@ -0,0 +1,307 @@
+package services
+
+import (
+	"database/sql"
+	"time"
+
+	"DoToday/models"
+	"DoToday/repositories"
+
+	"github.com/google/uuid"
+)
+
+var orgRoleRank = map[string]int{
+	models.OrgRoleMember: 1,
+	models.OrgRoleAdmin:  2,
+	models.OrgRoleOwner:  3,
+}
+
+type OrganizationService struct {
+	repo           *repositories.OrganizationRepository
+	goalRepo       *repositories.GoalRepository
+	userRepo       *repositories.UserRepository
+	completionRepo *repositories.CompletionRepository
+	goalService    *GoalService
+}
+
+func NewOrganizationService(
+	repo *repositories.OrganizationRepository,
+	goalRepo *repositories.GoalRepository,
+	userRepo *repositories.UserRepository,
+	completionRepo *repositories.CompletionRepository,
+	goalService *GoalService,
+) *OrganizationService {
+	return &OrganizationService{
+		repo:           repo,
+		goalRepo:       goalRepo,
+		userRepo:       userRepo,
+		completionRepo: completionRepo,
+		goalService:    goalService,
+	}
+}
+
+// memberRole returns the caller's role, or errForbidden if they are not a
+// member or rank below minRole.
+func (s *OrganizationService) memberRole(orgID, userID, minRole string) (string, error) {
+	role, err := s.repo.GetMemberRole(orgID, userID)
+	if err == sql.ErrNoRows {
+		return "", errForbidden
+	} else if err != nil {
+		return "", err
+	}
+	if orgRoleRank[role] < orgRoleRank[minRole] {
+		return "", errForbidden
+	}
+	return role, nil
+}
+
+func (s *OrganizationService) CreateOrganization(userID string, req *models.CreateOrganizationRequest) (*models.Organization, error) {
+	org := &models.Organization{
+		ID:        uuid.NewString(),
+		Name:      req.Name,
+		OwnerID:   userID,
+		Role:      models.OrgRoleOwner,
+		CreatedAt: time.Now(),
+	}
+	if err := s.repo.Create(org); err != nil {
+		return nil, err
+	}
+	return org, nil
+}
+
+func (s *OrganizationService) GetUserOrganizations(userID string) ([]*models.Organization, error) {
+	return s.repo.GetByUserID(userID)
+}
+
+func (s *OrganizationService) GetOrganization(orgID, userID string) (*models.Organization, error) {
+	role, err := s.memberRole(orgID, userID, models.OrgRoleMember)
+	if err != nil {
+		return nil, err
+	}
+	org, err := s.repo.GetByID(orgID)
+	if err != nil {
+		return nil, notFound(err, "Organization not found")
+	}
+	org.Role = role
+	return org, nil
+}
+
+func (s *OrganizationService) GetMembers(orgID, userID string) ([]*models.OrganizationMember, error) {
+	if _, err := s.memberRole(orgID, userID, models.OrgRoleMember); err != nil {
+		return nil, err
+	}
+	return s.repo.GetMembers(orgID)
+}
+
+// AddMember lets admins add members and the owner add admins.
+func (s *OrganizationService) AddMember(orgID, userID string, req *models.AddMemberRequest) (*models.OrganizationMember, error) {
+	callerRole, err := s.memberRole(orgID, userID, models.OrgRoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	role := req.Role
+	if role == "" {
+		role = models.OrgRoleMember
+	}
+	if role == models.OrgRoleAdmin && callerRole != models.OrgRoleOwner {
+		return nil, errForbidden
+	}
+
+	target, err := s.userRepo.GetByUsername(req.Username)
+	if err == sql.ErrNoRows {
+		return nil, models.NotFound("user not found")
+	} else if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.repo.GetMemberRole(orgID, target.ID); err == nil {
+		return nil, models.Conflict("already a member")
+	} else if err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	member := &models.OrganizationMember{
+		OrganizationID: orgID,
+		UserID:         target.ID,
+		Username:       target.Username,
+		Role:           role,
+		JoinedAt:       time.Now(),
+	}
+	if err := s.repo.AddMember(member); err != nil {
+		return nil, err
+	}
+	return member, nil
+}
+
+// UpdateMemberRole is reserved for the owner, whose own role cannot change.
+func (s *OrganizationService) UpdateMemberRole(orgID, userID, targetID string, req *models.UpdateMemberRoleRequest) error {
+	if _, err := s.memberRole(orgID, userID, models.OrgRoleOwner); err != nil {
+		return err
+	}
+	if targetID == userID {
+		return models.Invalid("owner role cannot change")
+	}
+	if _, err := s.repo.GetMemberRole(orgID, targetID); err == sql.ErrNoRows {
+		return models.NotFound("member not found")
+	} else if err != nil {
+		return err
+	}
+	return s.repo.UpdateMemberRole(orgID, targetID, req.Role)
+}
+
+// RemoveMember lets members leave and lets admins remove anyone ranked below
+// them. The owner cannot leave.
+func (s *OrganizationService) RemoveMember(orgID, userID, targetID string) error {
+	targetRole, err := s.repo.GetMemberRole(orgID, targetID)
+	if err == sql.ErrNoRows {
+		return models.NotFound("member not found")
+	} else if err != nil {
+		return err
+	}
+	if targetRole == models.OrgRoleOwner {
+		return models.Invalid("owner cannot leave organization")
+	}
+
+	if targetID != userID {
+		callerRole, err := s.memberRole(orgID, userID, models.OrgRoleAdmin)
+		if err != nil {
+			return err
+		}
+		if orgRoleRank[callerRole] <= orgRoleRank[targetRole] {
+			return errForbidden
+		}
+	}
+	return s.repo.RemoveMember(orgID, targetID)
+}
+
+// ShareGoal makes one of the caller's goals visible to the team.
+func (s *OrganizationService) ShareGoal(orgID, userID string, req *models.ShareGoalWithTeamRequest) error {
+	if _, err := s.memberRole(orgID, userID, models.OrgRoleMember); err != nil {
+		return err
+	}
+	goal, err := s.goalRepo.GetByID(req.GoalID)
+	if err != nil {
+		return notFound(err, "Goal not found")
+	}
+	if goal.UserID != userID {
+		return errForbidden
+	}
+	return s.repo.AddGoal(orgID, goal.ID)
+}
+
+// UnshareGoal removes a goal from the team. The goal owner and team admins may do this.
+func (s *OrganizationService) UnshareGoal(orgID, userID, goalID string) error {
+	role, err := s.memberRole(orgID, userID, models.OrgRoleMember)
+	if err != nil {
+		return err
+	}
+	goal, err := s.goalRepo.GetByID(goalID)
+	if err != nil {
+		return notFound(err, "Goal not found")
+	}
+	if goal.UserID != userID && orgRoleRank[role] < orgRoleRank[models.OrgRoleAdmin] {
+		return errForbidden
+	}
+	return s.repo.RemoveGoal(orgID, goalID)
+}
+
+func (s *OrganizationService) GetGoals(orgID, userID string) ([]*models.Goal, error) {
+	if _, err := s.memberRole(orgID, userID, models.OrgRoleMember); err != nil {
+		return nil, err
+	}
+	return s.repo.GetGoals(orgID)
+}
+
+// GetDashboard aggregates the team's goals over the last days days: per-member
+// stats, overall completion rate and active streaks, and a daily completion
+// series summed across every team goal. Completion rates are the share of
+// scheduled periods met since each goal was created. A period still in
+// progress only counts once it is met, and quit and milestone goals have no
+// scheduled periods.
+func (s *OrganizationService) GetDashboard(orgID, userID string, days int) (*models.TeamDashboard, error) {
+	if _, err := s.memberRole(orgID, userID, models.OrgRoleMember); err != nil {
+		return nil, err
+	}
+	if days <= 0 || days > 365 {
+		days = 30 // Default window
+	}
+
+	members, err := s.repo.GetMemberStats(orgID, days)
+	if err != nil {
+		return nil, err
+	}
+
+	dashboard := &models.TeamDashboard{
+		OrganizationID: orgID,
+		Days:           days,
+		Members:        members,
+	}
+	for _, m := range members {
+		dashboard.TeamGoals += m.TeamGoals
+		dashboard.ActiveStreaks += m.ActiveStreaks
+		dashboard.TotalCompletions += m.WindowCompletions
+	}
+
+	goals, err := s.repo.GetGoals(orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	today := truncateDay(time.Now())
+	from := today.AddDate(0, 0, -(days - 1))
+	due := make(map[string]int)
+	met := make(map[string]int)
+	for _, goal := range goals {
+		if goal.Kind == models.GoalKindQuit || goal.Kind == models.GoalKindMilestone {
+			continue
+		}
+		revisions, err := s.goalService.revisions(goal)
+		if err != nil {
+			return nil, err
+		}
+		completions, err := s.completionRepo.GetByGoalID(goal.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range duePeriods(goal, revisions, completions, from, today) {
+			due[goal.UserID]++
+			if p.Met {
+				met[goal.UserID]++
+			}
+		}
+	}
+	var teamDue, teamMet int
+	for _, m := range members {
+		if due[m.UserID] > 0 {
+			m.CompletionRate = float64(met[m.UserID]) / float64(due[m.UserID])
+		}
+		teamDue += due[m.UserID]
+		teamMet += met[m.UserID]
+	}
+	if teamDue > 0 {
+		dashboard.CompletionRate = float64(teamMet) / float64(teamDue)
+	}
+
+	for _, goal := range goals {
+		// days-1 because the series includes today
+		series, err := s.completionRepo.GetCompletionGraphData(goal.ID, days-1)
+		if err != nil {
+			return nil, err
+		}
+		if dashboard.Graph == nil {
+			dashboard.Graph = series
+			continue
+		}
+		for i := range series {
+			if i < len(dashboard.Graph) {
+				dashboard.Graph[i].Completions += series[i].Completions
+				dashboard.Graph[i].Count += series[i].Count
+			}
+		}
+	}
+	if dashboard.Graph == nil {
+		dashboard.Graph = []models.CompletionGraphData{}
+	}
+	return dashboard, nil
+}
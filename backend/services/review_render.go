@@ -0,0 +1,119 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"strings"
+
+	"DoToday/models"
+)
+
+// reviewTitle names the review and its period, e.g. "Weekly review: Oct 5 –
+// Oct 11, 2026" or "Monthly review: October 2026".
+func reviewTitle(review *models.Review) string {
+	if review.Period == models.ReviewPeriodMonthly {
+		return "Monthly review: " + review.PeriodStart.Format("January 2006")
+	}
+	return fmt.Sprintf("Weekly review: %s – %s",
+		review.PeriodStart.Format("Jan 2"), review.PeriodEnd.Format("Jan 2, 2006"))
+}
+
+func percent(rate float64) string {
+	return fmt.Sprintf("%.0f%%", rate*100)
+}
+
+// markdownEscaper escapes the characters that would format a goal title.
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`, "|", `\|`, "#", `\#`, "<", "&lt;",
+)
+
+// RenderReviewMarkdown renders the review as Markdown.
+func RenderReviewMarkdown(review *models.Review) string {
+	s := review.Summary
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# %s\n\n", reviewTitle(review))
+	if s.Scheduled == 0 {
+		b.WriteString("No goals were scheduled in this period.\n")
+		return b.String()
+	}
+	fmt.Fprintf(&b, "You completed %d of %d scheduled (%s).\n", s.Completed, s.Scheduled, percent(s.CompletionRate))
+
+	section := func(title string, goals []*models.ReviewGoal, line func(*models.ReviewGoal) string) {
+		if len(goals) == 0 {
+			return
+		}
+		fmt.Fprintf(&b, "\n## %s\n\n", title)
+		for _, g := range goals {
+			fmt.Fprintf(&b, "- **%s**: %s\n", markdownEscaper.Replace(g.Title), line(g))
+		}
+	}
+	section("Best goals", s.BestGoals, func(g *models.ReviewGoal) string {
+		return fmt.Sprintf("%d of %d (%s), streak %d", g.Completed, g.Scheduled, percent(g.CompletionRate), g.StreakEnd)
+	})
+	section("Needs attention", s.NeedsAttention, func(g *models.ReviewGoal) string {
+		return fmt.Sprintf("%d of %d (%s)", g.Completed, g.Scheduled, percent(g.CompletionRate))
+	})
+	section("Streaks gained", s.StreaksGained, func(g *models.ReviewGoal) string {
+		return fmt.Sprintf("%d → %d", g.StreakStart, g.StreakEnd)
+	})
+	section("Streaks lost", s.StreaksLost, func(g *models.ReviewGoal) string {
+		return fmt.Sprintf("lost a streak of %d", g.StreakStart)
+	})
+
+	if len(s.Insights) > 0 {
+		b.WriteString("\n## Insights\n\n")
+		for _, insight := range s.Insights {
+			fmt.Fprintf(&b, "- %s (%d days)\n", markdownEscaper.Replace(insight.Summary), insight.SampleSize)
+		}
+	}
+
+	b.WriteString("\n## All goals\n\n| Goal | Completed | Rate | Streak |\n| --- | --- | --- | --- |\n")
+	for _, g := range s.Goals {
+		fmt.Fprintf(&b, "| %s | %d of %d | %s | %d |\n",
+			markdownEscaper.Replace(g.Title), g.Completed, g.Scheduled, percent(g.CompletionRate), g.StreakEnd)
+	}
+	return b.String()
+}
+
+var reviewTemplate = template.Must(template.New("review").Funcs(template.FuncMap{
+	"percent": percent,
+}).Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>{{.Title}}</title></head>
+<body style="font-family: sans-serif; max-width: 640px; margin: 0 auto;">
+<h1>{{.Title}}</h1>
+{{with .Review.Summary}}{{if eq .Scheduled 0}}<p>No goals were scheduled in this period.</p>{{else}}
+<p>You completed {{.Completed}} of {{.Scheduled}} scheduled ({{percent .CompletionRate}}).</p>
+{{if .BestGoals}}<h2>Best goals</h2>
+<ul>{{range .BestGoals}}<li><strong>{{.Title}}</strong>: {{.Completed}} of {{.Scheduled}} ({{percent .CompletionRate}}), streak {{.StreakEnd}}</li>{{end}}</ul>{{end}}
+{{if .NeedsAttention}}<h2>Needs attention</h2>
+<ul>{{range .NeedsAttention}}<li><strong>{{.Title}}</strong>: {{.Completed}} of {{.Scheduled}} ({{percent .CompletionRate}})</li>{{end}}</ul>{{end}}
+{{if .StreaksGained}}<h2>Streaks gained</h2>
+<ul>{{range .StreaksGained}}<li><strong>{{.Title}}</strong>: {{.StreakStart}} → {{.StreakEnd}}</li>{{end}}</ul>{{end}}
+{{if .StreaksLost}}<h2>Streaks lost</h2>
+<ul>{{range .StreaksLost}}<li><strong>{{.Title}}</strong>: lost a streak of {{.StreakStart}}</li>{{end}}</ul>{{end}}
+{{if .Insights}}<h2>Insights</h2>
+<ul>{{range .Insights}}<li>{{.Summary}} ({{.SampleSize}} days)</li>{{end}}</ul>{{end}}
+<h2>All goals</h2>
+<table>
+<tr><th align="left">Goal</th><th>Completed</th><th>Rate</th><th>Streak</th></tr>
+{{range .Goals}}<tr><td>{{.Title}}</td><td>{{.Completed}} of {{.Scheduled}}</td><td>{{percent .CompletionRate}}</td><td>{{.StreakEnd}}</td></tr>
+{{end}}</table>{{end}}{{end}}
+</body>
+</html>
+`))
+
+// RenderReviewHTML renders the review as a standalone HTML page.
+func RenderReviewHTML(review *models.Review) (string, error) {
+	var b bytes.Buffer
+	err := reviewTemplate.Execute(&b, struct {
+		Title  string
+		Review *models.Review
+	}{reviewTitle(review), review})
+	if err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
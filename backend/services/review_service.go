@@ -0,0 +1,302 @@
+package services
+
+import (
+	"database/sql"
+	"log"
+	"sort"
+	"time"
+
+	"DoToday/models"
+	"DoToday/repositories"
+
+	"github.com/google/uuid"
+)
+
+// reviewHighlights caps the best goals and insights listed in a review.
+const reviewHighlights = 3
+
+var errInvalidPeriod = models.InvalidFields("invalid period",
+	models.FieldError{Field: "period", Message: "must be one of: weekly, monthly"})
+
+type ReviewService struct {
+	reviewRepo     *repositories.ReviewRepository
+	goalRepo       *repositories.GoalRepository
+	completionRepo *repositories.CompletionRepository
+	userRepo       *repositories.UserRepository
+	goalService    *GoalService
+	insightService *InsightService
+	mailer         Mailer
+}
+
+// NewReviewService creates the review service. mailer may be nil, in which
+// case reviews are only stored.
+func NewReviewService(
+	reviewRepo *repositories.ReviewRepository,
+	goalRepo *repositories.GoalRepository,
+	completionRepo *repositories.CompletionRepository,
+	userRepo *repositories.UserRepository,
+	goalService *GoalService,
+	insightService *InsightService,
+	mailer Mailer,
+) *ReviewService {
+	return &ReviewService{
+		reviewRepo:     reviewRepo,
+		goalRepo:       goalRepo,
+		completionRepo: completionRepo,
+		userRepo:       userRepo,
+		goalService:    goalService,
+		insightService: insightService,
+		mailer:         mailer,
+	}
+}
+
+// reviewBounds returns the first and last day of the last full week or month
+// before now.
+func reviewBounds(period string, now time.Time) (time.Time, time.Time) {
+	frequency := models.FrequencyWeekly
+	if period == models.ReviewPeriodMonthly {
+		frequency = models.FrequencyMonthly
+	}
+	current, _ := periodBounds(frequency, now)
+	return periodBounds(frequency, current.AddDate(0, 0, -1))
+}
+
+// GenerateDue writes the last week's and month's reviews for every user who
+// had goals then and has no review yet, emailing them when enabled.
+func (s *ReviewService) GenerateDue() error {
+	now := time.Now()
+	for _, period := range []string{models.ReviewPeriodWeekly, models.ReviewPeriodMonthly} {
+		start, end := reviewBounds(period, now)
+		userIDs, err := s.reviewRepo.GetUsersDue(period, start, end)
+		if err != nil {
+			return err
+		}
+		for _, userID := range userIDs {
+			if _, err := s.generate(userID, period, start, end); err != nil {
+				log.Printf("generating %s review failed for user %s: %v", period, userID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Generate returns the user's review of the last full week or month,
+// writing it first if it does not exist yet.
+func (s *ReviewService) Generate(userID, period string) (*models.Review, error) {
+	if period != models.ReviewPeriodWeekly && period != models.ReviewPeriodMonthly {
+		return nil, errInvalidPeriod
+	}
+	start, end := reviewBounds(period, time.Now())
+	return s.generate(userID, period, start, end)
+}
+
+func (s *ReviewService) generate(userID, period string, start, end time.Time) (*models.Review, error) {
+	review, err := s.reviewRepo.GetByPeriod(userID, period, start)
+	if err == nil {
+		return review, nil
+	} else if err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	summary, err := s.summarize(userID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	review = &models.Review{
+		ID:          uuid.NewString(),
+		UserID:      userID,
+		Period:      period,
+		PeriodStart: start,
+		PeriodEnd:   end,
+		Summary:     summary,
+		CreatedAt:   time.Now(),
+	}
+	inserted, err := s.reviewRepo.Create(review)
+	if err != nil {
+		return nil, err
+	}
+	if !inserted {
+		// Written concurrently by the background job or another request.
+		return s.reviewRepo.GetByPeriod(userID, period, start)
+	}
+
+	if err := s.deliver(review); err != nil {
+		log.Printf("emailing review %s failed: %v", review.ID, err)
+	}
+	return review, nil
+}
+
+// summarize evaluates the user's active goals between start and end.
+func (s *ReviewService) summarize(userID string, start, end time.Time) (*models.ReviewSummary, error) {
+	goals, err := s.goalRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &models.ReviewSummary{
+		Goals:          []*models.ReviewGoal{},
+		StreaksGained:  []*models.ReviewGoal{},
+		StreaksLost:    []*models.ReviewGoal{},
+		BestGoals:      []*models.ReviewGoal{},
+		NeedsAttention: []*models.ReviewGoal{},
+	}
+	for _, goal := range goals {
+		if truncateDay(goal.CreatedAt).After(end) {
+			continue
+		}
+		revisions, err := s.goalService.revisions(goal)
+		if err != nil {
+			return nil, err
+		}
+		completions, err := s.completionRepo.GetByGoalID(goal.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		rg, missed := reviewGoal(goal, evaluatePeriods(goal, revisions, completions, goal.CreatedAt, end), start, end)
+		summary.Goals = append(summary.Goals, rg)
+		summary.Scheduled += rg.Scheduled
+		summary.Completed += rg.Completed
+		if rg.StreakEnd > rg.StreakStart {
+			summary.StreaksGained = append(summary.StreaksGained, rg)
+		}
+		if missed && rg.StreakStart > 0 {
+			summary.StreaksLost = append(summary.StreaksLost, rg)
+		}
+		if rg.Scheduled > 0 && rg.CompletionRate < 0.5 {
+			summary.NeedsAttention = append(summary.NeedsAttention, rg)
+		}
+	}
+	if summary.Scheduled > 0 {
+		summary.CompletionRate = float64(summary.Completed) / float64(summary.Scheduled)
+	}
+
+	sort.SliceStable(summary.Goals, func(i, j int) bool {
+		a, b := summary.Goals[i], summary.Goals[j]
+		if a.CompletionRate != b.CompletionRate {
+			return a.CompletionRate > b.CompletionRate
+		}
+		return a.StreakEnd > b.StreakEnd
+	})
+	for _, rg := range summary.Goals {
+		if len(summary.BestGoals) == reviewHighlights || rg.Completed == 0 {
+			break
+		}
+		summary.BestGoals = append(summary.BestGoals, rg)
+	}
+	sort.SliceStable(summary.NeedsAttention, func(i, j int) bool {
+		return summary.NeedsAttention[i].CompletionRate < summary.NeedsAttention[j].CompletionRate
+	})
+
+	summary.Insights, err = s.insightService.findInsights(userID, end.AddDate(0, 0, -(insightDays-1)), end, reviewHighlights)
+	if err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
+
+// reviewGoal sums up the goal's periods ending between start and end and its
+// streak before and after them, reporting whether any period was missed. A
+// period still running at end only counts once met.
+func reviewGoal(goal *models.Goal, periods []*models.GoalPeriod, start, end time.Time) (*models.ReviewGoal, bool) {
+	rg := &models.ReviewGoal{
+		GoalID:    goal.ID,
+		Title:     goal.Title,
+		Kind:      goal.Kind,
+		Frequency: goal.Frequency,
+	}
+
+	var counted []*models.GoalPeriod
+	missed := false
+	for _, p := range periods {
+		if p.End.Before(start) {
+			counted = append(counted, p)
+			continue
+		}
+		if _, natural := periodBounds(p.Frequency, p.Start); natural.After(end) && !p.Met {
+			continue
+		}
+		if rg.Scheduled == 0 {
+			rg.StreakStart = periodStreak(counted)
+		}
+		counted = append(counted, p)
+		rg.Scheduled++
+		if p.Met {
+			rg.Completed++
+		} else {
+			missed = true
+		}
+	}
+	if rg.Scheduled == 0 {
+		rg.StreakStart = periodStreak(counted)
+	}
+	rg.StreakEnd = periodStreak(counted)
+	if rg.Scheduled > 0 {
+		rg.CompletionRate = float64(rg.Completed) / float64(rg.Scheduled)
+	}
+	return rg, missed
+}
+
+// periodStreak counts the met periods at the end of periods.
+func periodStreak(periods []*models.GoalPeriod) int {
+	streak := 0
+	for i := len(periods) - 1; i >= 0 && periods[i].Met; i-- {
+		streak++
+	}
+	return streak
+}
+
+// deliver emails the review when a mailer is configured and the user has
+// not turned review emails off.
+func (s *ReviewService) deliver(review *models.Review) error {
+	if s.mailer == nil {
+		return nil
+	}
+	profile, err := s.userRepo.GetByID(review.UserID)
+	if err != nil {
+		return err
+	}
+	if !profile.ReviewEmails || profile.Email == "" {
+		return nil
+	}
+
+	html, err := RenderReviewHTML(review)
+	if err != nil {
+		return err
+	}
+	if err := s.mailer.Send(profile.Email, reviewTitle(review), RenderReviewMarkdown(review), html); err != nil {
+		return err
+	}
+	now := time.Now()
+	review.EmailedAt = &now
+	return s.reviewRepo.MarkEmailed(review.ID, now)
+}
+
+// GetReviews lists the user's latest reviews, optionally of one period.
+func (s *ReviewService) GetReviews(userID, period string, limit int) ([]*models.Review, error) {
+	if period != "" && period != models.ReviewPeriodWeekly && period != models.ReviewPeriodMonthly {
+		return nil, errInvalidPeriod
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 12
+	}
+	reviews, err := s.reviewRepo.GetByUserID(userID, period, limit)
+	if err != nil {
+		return nil, err
+	}
+	if reviews == nil {
+		reviews = []*models.Review{}
+	}
+	return reviews, nil
+}
+
+func (s *ReviewService) GetReview(reviewID, userID string) (*models.Review, error) {
+	review, err := s.reviewRepo.GetByID(reviewID)
+	if err != nil {
+		return nil, notFound(err, "Review not found")
+	}
+	if review.UserID != userID {
+		return nil, errForbidden
+	}
+	return review, nil
+}
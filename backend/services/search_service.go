@@ -0,0 +1,175 @@
+package services
+
+import (
+	"html"
+	"math"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"DoToday/config"
+	"DoToday/models"
+	"DoToday/repositories"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+	maxSearchTerms     = 8
+
+	// searchCandidateLimit caps how many rows the simple search ranks.
+	searchCandidateLimit = 500
+
+	// snippetRunes is the length of simple search snippets.
+	snippetRunes = 160
+)
+
+var searchTypes = []string{models.SearchTypeGoal, models.SearchTypeFeed, models.SearchTypeComment}
+
+type SearchService struct {
+	repo     *repositories.SearchRepository
+	fullText bool
+}
+
+func NewSearchService(repo *repositories.SearchRepository, cfg *config.SearchConfig) *SearchService {
+	fullText := cfg.Mode == config.SearchModeFullText ||
+		(cfg.Mode == config.SearchModeAuto && repo.SupportsFullText())
+	return &SearchService{repo: repo, fullText: fullText}
+}
+
+// Search returns the goals, feed posts and comments visible to the user that
+// match the query, best match first.
+func (s *SearchService) Search(userID string, q *models.SearchQuery) ([]*models.SearchResult, error) {
+	q.Text = strings.TrimSpace(q.Text)
+	q.Terms = searchTerms(q.Text)
+	if len(q.Terms) == 0 {
+		return nil, models.InvalidFields("search query is required", models.FieldError{Field: "q", Message: "is required"})
+	}
+	if len(q.Types) == 0 {
+		q.Types = searchTypes
+	}
+	for _, t := range q.Types {
+		if !slices.Contains(searchTypes, t) {
+			return nil, models.InvalidFields("invalid search type", models.FieldError{Field: "type", Message: "must be one of: " + strings.Join(searchTypes, ", ")})
+		}
+	}
+	if q.From != nil && q.To != nil && q.From.After(*q.To) {
+		return nil, models.InvalidFields("from must not be after to", models.FieldError{Field: "from", Message: "must not be after to"})
+	}
+	q.Tag = normalizeTag(q.Tag)
+	if q.Limit <= 0 {
+		q.Limit = defaultSearchLimit
+	} else if q.Limit > maxSearchLimit {
+		q.Limit = maxSearchLimit
+	}
+
+	if s.fullText {
+		results, err := s.repo.Search(userID, q, true, q.Limit)
+		if err != nil {
+			return nil, err
+		}
+		for _, result := range results {
+			result.Snippet = renderSnippet(result.Snippet)
+		}
+		return nonNil(results), nil
+	}
+
+	results, err := s.repo.Search(userID, q, false, searchCandidateLimit)
+	if err != nil {
+		return nil, err
+	}
+	pattern := termPattern(q.Terms)
+	for _, result := range results {
+		result.Rank = simpleRank(result.Snippet, pattern)
+		result.Snippet = renderSnippet(highlight(excerpt(result.Snippet, pattern), pattern))
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Rank != results[j].Rank {
+			return results[i].Rank > results[j].Rank
+		}
+		return results[i].Date.After(results[j].Date)
+	})
+	if len(results) > q.Limit {
+		results = results[:q.Limit]
+	}
+	return nonNil(results), nil
+}
+
+// searchTerms splits a query into lowercased words, dropping the quotes,
+// "or" and negated words of web search syntax.
+func searchTerms(text string) []string {
+	var terms []string
+	for _, word := range strings.Fields(strings.ToLower(text)) {
+		if strings.HasPrefix(word, "-") {
+			continue
+		}
+		word = strings.Trim(word, `"'`)
+		if word == "" || word == "or" || slices.Contains(terms, word) {
+			continue
+		}
+		terms = append(terms, word)
+		if len(terms) == maxSearchTerms {
+			break
+		}
+	}
+	return terms
+}
+
+func termPattern(terms []string) *regexp.Regexp {
+	quoted := make([]string, len(terms))
+	for i, term := range terms {
+		quoted[i] = regexp.QuoteMeta(term)
+	}
+	return regexp.MustCompile(`(?i)` + strings.Join(quoted, "|"))
+}
+
+// simpleRank scores text by how often the terms occur, damped by its length.
+func simpleRank(text string, pattern *regexp.Regexp) float64 {
+	hits := len(pattern.FindAllStringIndex(text, -1))
+	words := len(strings.Fields(text))
+	return float64(hits) / (1 + math.Log1p(float64(words)))
+}
+
+// excerpt cuts text down to snippetRunes around the first match.
+func excerpt(text string, pattern *regexp.Regexp) string {
+	if utf8.RuneCountInString(text) <= snippetRunes {
+		return text
+	}
+	runes := []rune(text)
+	start := 0
+	if loc := pattern.FindStringIndex(text); loc != nil {
+		start = utf8.RuneCountInString(text[:loc[0]]) - snippetRunes/4
+		if start < 0 {
+			start = 0
+		}
+	}
+	end := start + snippetRunes
+	if end > len(runes) {
+		end = len(runes)
+		start = end - snippetRunes
+	}
+
+	cut := strings.TrimSpace(string(runes[start:end]))
+	if start > 0 {
+		cut = "…" + cut
+	}
+	if end < len(runes) {
+		cut += "…"
+	}
+	return cut
+}
+
+func highlight(text string, pattern *regexp.Regexp) string {
+	return pattern.ReplaceAllString(text, repositories.SearchMarkStart+"$0"+repositories.SearchMarkStop)
+}
+
+// renderSnippet escapes a marked snippet and turns the markers into <mark>.
+func renderSnippet(snippet string) string {
+	escaped := html.EscapeString(snippet)
+	return strings.NewReplacer(
+		repositories.SearchMarkStart, "<mark>",
+		repositories.SearchMarkStop, "</mark>",
+	).Replace(escaped)
+}
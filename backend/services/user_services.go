@@ -3,7 +3,6 @@ package services
 
 import (
 	"database/sql"
-	"errors"
 
 	"DoToday/models"
 	"DoToday/repositories"
@@ -12,18 +11,37 @@ import (
 )
 
 type UserService struct {
-	userRepo *repositories.UserRepository
+	userRepo     *repositories.UserRepository
+	followRepo   *repositories.FollowRepository
+	achievements *AchievementService
+	filter       *ContentFilter
 }
 
-func NewUserService(userRepo *repositories.UserRepository) *UserService {
-	return &UserService{userRepo: userRepo}
+func NewUserService(
+	userRepo *repositories.UserRepository,
+	followRepo *repositories.FollowRepository,
+	achievements *AchievementService,
+	filter *ContentFilter,
+) *UserService {
+	return &UserService{
+		userRepo:     userRepo,
+		followRepo:   followRepo,
+		achievements: achievements,
+		filter:       filter,
+	}
 }
 
 func (s *UserService) GetProfile(userID string) (*models.Profile, error) {
 	profile, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, notFound(err, "User not found")
+	}
+
+	achievements, err := s.achievements.GetUserAchievements(userID)
 	if err != nil {
 		return nil, err
 	}
+	profile.Achievements = achievements
 	return profile, nil
 }
 
@@ -31,23 +49,47 @@ func (s *UserService) UpdateProfile(userID string, req *models.UpdateProfileRequ
 	// Get current profile
 	profile, err := s.userRepo.GetByID(userID)
 	if err != nil {
-		return nil, err
+		return nil, notFound(err, "User not found")
 	}
 
 	// Update username if provided
 	if req.Username != nil && *req.Username != profile.Username {
+		username, err := s.filter.Apply(&ContentInput{
+			UserID:   userID,
+			Kind:     models.ContentKindUsername,
+			TargetID: userID,
+			Text:     *req.Username,
+		})
+		if err != nil {
+			return nil, err
+		}
+
 		// Check if username is already taken
-		_, err := s.userRepo.GetByUsername(*req.Username)
+		_, err = s.userRepo.GetByUsername(username)
 		if err == nil {
-			return nil, errors.New("username already exists")
+			return nil, models.Conflict("username already exists")
 		} else if err != sql.ErrNoRows {
 			return nil, err
 		}
 
-		if err := s.userRepo.UpdateUsername(userID, *req.Username); err != nil {
+		if err := s.userRepo.UpdateUsername(userID, username); err != nil {
+			return nil, err
+		}
+		profile.Username = username
+	}
+
+	if req.ShareAchievements != nil && *req.ShareAchievements != profile.ShareAchievements {
+		if err := s.userRepo.UpdateShareAchievements(userID, *req.ShareAchievements); err != nil {
+			return nil, err
+		}
+		profile.ShareAchievements = *req.ShareAchievements
+	}
+
+	if req.ReviewEmails != nil && *req.ReviewEmails != profile.ReviewEmails {
+		if err := s.userRepo.UpdateReviewEmails(userID, *req.ReviewEmails); err != nil {
 			return nil, err
 		}
-		profile.Username = *req.Username
+		profile.ReviewEmails = *req.ReviewEmails
 	}
 
 	return profile, nil
@@ -56,3 +98,23 @@ func (s *UserService) UpdateProfile(userID string, req *models.UpdateProfileRequ
 func (s *UserService) GetUserStats(userID uuid.UUID) (*models.UserStats, error) {
 	return s.userRepo.GetStats(userID)
 }
+
+func (s *UserService) Follow(userID, targetID string) error {
+	if userID == targetID {
+		return models.Invalid("cannot follow yourself")
+	}
+	if _, err := s.userRepo.GetByID(targetID); err == sql.ErrNoRows {
+		return models.NotFound("user not found")
+	} else if err != nil {
+		return err
+	}
+	return s.followRepo.Follow(userID, targetID)
+}
+
+func (s *UserService) Unfollow(userID, targetID string) error {
+	return s.followRepo.Unfollow(userID, targetID)
+}
+
+func (s *UserService) GetFollowing(userID string) ([]*models.Follow, error) {
+	return s.followRepo.GetFollowing(userID)
+}
@@ -0,0 +1,193 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"DoToday/config"
+	"DoToday/models"
+	"DoToday/repositories"
+
+	"github.com/google/uuid"
+)
+
+// levelThreshold is the total XP needed to reach a level: 0, 100, 300, 600, ...
+func levelThreshold(level int) int {
+	return 50 * level * (level - 1)
+}
+
+// LevelForXP derives a level from total XP.
+func LevelForXP(xp int) int {
+	level := 1
+	for levelThreshold(level+1) <= xp {
+		level++
+	}
+	return level
+}
+
+type XPService struct {
+	repo           *repositories.XPRepository
+	completionRepo *repositories.CompletionRepository
+	weights        *config.XPConfig
+}
+
+func NewXPService(
+	repo *repositories.XPRepository,
+	completionRepo *repositories.CompletionRepository,
+	weights *config.XPConfig,
+) *XPService {
+	return &XPService{
+		repo:           repo,
+		completionRepo: completionRepo,
+		weights:        weights,
+	}
+}
+
+func (s *XPService) award(userID string, amount int, reason, ref string, goalID, completionID *string) error {
+	if amount == 0 {
+		return nil
+	}
+	return s.repo.Append(&models.XPEntry{
+		ID:           uuid.NewString(),
+		UserID:       userID,
+		Amount:       amount,
+		Reason:       reason,
+		Ref:          &ref,
+		GoalID:       goalID,
+		CompletionID: completionID,
+		CreatedAt:    time.Now(),
+	})
+}
+
+func (s *XPService) GoalCreated(goal *models.Goal) error {
+	return nil
+}
+
+// GoalCompleted awards XP for the completion itself, for reaching a streak
+// milestone, and for reaching the goal's target count.
+func (s *XPService) GoalCompleted(goal *models.Goal, completion *models.Completion, streak int) error {
+	goalID, completionID := goal.ID, completion.ID
+
+	err := s.award(goal.UserID, s.weights.Completion, models.XPReasonCompletion,
+		"completion:"+completionID, &goalID, &completionID)
+	if err != nil {
+		return err
+	}
+
+	if points, ok := s.weights.StreakMilestone[streak]; ok {
+		err := s.award(goal.UserID, points, models.XPReasonStreak,
+			fmt.Sprintf("streak:%s:%d", completionID, streak), &goalID, &completionID)
+		if err != nil {
+			return err
+		}
+	}
+
+	if goal.TargetCount > 1 {
+		count, err := s.completionRepo.CountByGoalID(goal.ID)
+		if err != nil {
+			return err
+		}
+		if count == goal.TargetCount {
+			err := s.award(goal.UserID, s.weights.TargetReached, models.XPReasonTarget,
+				"target:"+completionID, &goalID, &completionID)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// CompletionRemoved reverses everything the completion earned.
+func (s *XPService) CompletionRemoved(goal *models.Goal, completion *models.Completion) error {
+	return s.repo.ReverseCompletion(completion.ID)
+}
+
+func (s *XPService) GoalChanged(goal *models.Goal) error {
+	return nil
+}
+
+// GoalTrashed keeps the goal's XP, since the goal can still be restored. It is
+// reversed once the goal is purged.
+func (s *XPService) GoalTrashed(goal *models.Goal) error {
+	return nil
+}
+
+func (s *XPService) GoalRestored(goal *models.Goal) error {
+	return nil
+}
+
+// GoalDeleted reverses everything the goal's completions earned.
+func (s *XPService) GoalDeleted(goal *models.Goal) error {
+	return s.repo.ReverseGoal(goal.ID)
+}
+
+// RecordFeedPost awards XP once per goal and day, matching the one post a
+// goal can have each day.
+func (s *XPService) RecordFeedPost(userID, goalID string, date time.Time) error {
+	return s.award(userID, s.weights.FeedPost, models.XPReasonFeedPost,
+		"feed:"+goalID+":"+date.UTC().Format(dateLayout), nil, nil)
+}
+
+func (s *XPService) RecordComment(userID, commentID string) error {
+	return s.award(userID, s.weights.Comment, models.XPReasonComment, "comment:"+commentID, nil, nil)
+}
+
+// CommentDeleted reverses the XP the comment earned.
+func (s *XPService) CommentDeleted(commentID string) error {
+	return s.repo.ReverseRef("comment:" + commentID)
+}
+
+// RecordLike awards XP once per user and post, so unliking and liking again earns nothing.
+func (s *XPService) RecordLike(userID, feedID string) error {
+	return s.award(userID, s.weights.Like, models.XPReasonLike, "like:"+feedID+":"+userID, nil, nil)
+}
+
+func (s *XPService) GetLevel(userID string) (*models.LevelInfo, error) {
+	total, err := s.repo.GetTotal(userID)
+	if err != nil {
+		return nil, err
+	}
+	level := LevelForXP(total)
+	return &models.LevelInfo{
+		UserID:        userID,
+		TotalXP:       total,
+		Level:         level,
+		LevelStartXP:  levelThreshold(level),
+		NextLevelXP:   levelThreshold(level + 1),
+		XPToNextLevel: levelThreshold(level+1) - total,
+	}, nil
+}
+
+func (s *XPService) GetHistory(userID string, limit int) ([]*models.XPEntry, error) {
+	if limit <= 0 || limit > 200 {
+		limit = 50 // Default limit
+	}
+	return s.repo.GetHistory(userID, limit)
+}
+
+// GetWeeklyLeaderboard ranks the user and everyone they follow by XP earned
+// since Monday (UTC).
+func (s *XPService) GetWeeklyLeaderboard(userID string) ([]*models.XPLeaderboardEntry, error) {
+	now := time.Now().UTC()
+	weekday := (int(now.Weekday()) + 6) % 7 // Monday = 0
+	weekStart := now.Truncate(24*time.Hour).AddDate(0, 0, -weekday)
+
+	entries, err := s.repo.GetWeeklyLeaderboard(userID, weekStart)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].WeeklyXP > entries[j].WeeklyXP
+	})
+	for i, e := range entries {
+		e.Level = LevelForXP(e.TotalXP)
+		e.Rank = i + 1
+		if i > 0 && entries[i-1].WeeklyXP == e.WeeklyXP {
+			e.Rank = entries[i-1].Rank
+		}
+	}
+	return entries, nil
+}